@@ -2,11 +2,17 @@ package main
 
 import (
 	"book_api/internal/config"
+	"book_api/internal/domain"
 	"book_api/internal/repository/psql"
 	"book_api/internal/service"
 	"book_api/internal/transport/rest"
 	"book_api/pkg/database"
 	"book_api/pkg/hash"
+	"book_api/pkg/metadata"
+	"book_api/pkg/notify"
+	"book_api/pkg/scheduler"
+	"book_api/pkg/storage"
+	"context"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"net/http"
@@ -49,14 +55,154 @@ func main() {
 	userService := service.NewUserService(userRepo, hasher, []byte("my secret"), cfg.Auth.TokenTTL)
 
 	bookRepo := psql.NewBookRepository(db)
-	bookService := service.NewBookService(bookRepo)
+	var store service.BlobStore
+	var publicFiles http.Handler
 
-	bookHandler := rest.NewHandler(bookService, userService)
+	switch cfg.Storage.Driver {
+	case "s3":
+		store = storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			Region:    cfg.Storage.S3.Region,
+			Bucket:    cfg.Storage.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			BaseURL:   cfg.Storage.S3.BaseURL,
+		})
+	default:
+		local, err := storage.NewLocal(cfg.Storage.Local.Dir, cfg.Storage.Local.BaseURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		store = local
+		publicFiles = http.StripPrefix("/files/", http.FileServer(http.Dir(local.Root())))
+	}
+
+	bookService := service.NewBookService(bookRepo, store, service.CoverOptions{
+		MaxSize:        cfg.Covers.MaxSize,
+		ThumbnailSizes: cfg.Covers.ThumbnailSizes,
+	})
+
+	fileRepo := psql.NewBookFileRepository(db)
+	fileService := service.NewFileService(fileRepo, bookService, store, service.FileOptions{
+		MaxSize: cfg.Files.MaxSize,
+	})
+
+	enrichService := service.NewEnrichService(bookRepo, bookService,
+		metadata.NewOpenLibrary(cfg.Metadata.BaseURL, cfg.Metadata.CoversURL))
+
+	seriesRepo := psql.NewSeriesRepository(db)
+	seriesService := service.NewSeriesService(seriesRepo, bookRepo)
+
+	reviewRepo := psql.NewReviewRepository(db)
+	reviewService := service.NewReviewService(reviewRepo, bookRepo)
+
+	shelfRepo := psql.NewShelfRepository(db)
+	shelfService := service.NewShelfService(shelfRepo, bookRepo)
+
+	readingRepo := psql.NewReadingRepository(db)
+	readingService := service.NewReadingService(readingRepo, bookRepo)
+
+	copyRepo := psql.NewCopyRepository(db)
+	loanRepo := psql.NewLoanRepository(db)
+	circulationService := service.NewCirculationService(copyRepo, loanRepo, bookRepo, service.CirculationOptions{
+		LoanPeriod:   cfg.Circulation.LoanPeriod,
+		MaxLoans:     cfg.Circulation.MaxLoans,
+		PickupPeriod: cfg.Circulation.PickupPeriod,
+		FineRate: domain.FineRate{
+			DailyAmount: cfg.Fines.DailyAmount,
+			MaxAmount:   cfg.Fines.MaxAmount,
+		},
+		BookURL: cfg.Labels.BookURL,
+	})
+
+	holdRepo := psql.NewHoldRepository(db)
+	holdService := service.NewHoldService(holdRepo, cfg.Circulation.PickupPeriod)
+
+	branchRepo := psql.NewBranchRepository(db)
+	transferRepo := psql.NewTransferRepository(db)
+	branchService := service.NewBranchService(branchRepo, copyRepo, transferRepo, bookRepo, cfg.Circulation.PickupPeriod)
+
+	oaiService := service.NewOAIService(bookRepo, seriesRepo, service.OAIOptions{
+		RepositoryName:       cfg.OAI.RepositoryName,
+		BaseURL:              cfg.OAI.BaseURL,
+		AdminEmail:           cfg.OAI.AdminEmail,
+		RepositoryIdentifier: cfg.OAI.RepositoryIdentifier,
+	})
+
+	var notifier service.Notifier
+	switch cfg.Notifier.Driver {
+	case "email":
+		notifier = notify.NewEmail(notify.EmailConfig{
+			Host:     cfg.Notifier.Email.Host,
+			Port:     cfg.Notifier.Email.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.Notifier.Email.From,
+		})
+	case "webhook":
+		notifier = notify.NewWebhook(cfg.Notifier.Webhook.URL)
+	default:
+		notifier = notify.NewLog()
+	}
+
+	fineRepo := psql.NewFineRepository(db)
+	fineService := service.NewFineService(fineRepo, loanRepo, notifier, service.FineOptions{
+		DailyAmount:      cfg.Fines.DailyAmount,
+		MaxAmount:        cfg.Fines.MaxAmount,
+		Currency:         cfg.Fines.Currency,
+		ReminderInterval: cfg.Fines.RemindEvery,
+	})
+
+	jobs := scheduler.New(database.NewAdvisoryLocker(db),
+		scheduler.Job{
+			Name:     "accrue_fines",
+			Interval: cfg.Scheduler.FinesInterval,
+			Run:      fineService.AccrueFines,
+		},
+		scheduler.Job{
+			Name:     "send_reminders",
+			Interval: cfg.Scheduler.RemindersInterval,
+			Run:      fineService.SendReminders,
+		},
+		scheduler.Job{
+			Name:     "expire_holds",
+			Interval: cfg.Scheduler.HoldExpiryInterval,
+			Run: func(ctx context.Context) error {
+				_, err := holdService.ExpireUnclaimed(ctx)
+				return err
+			},
+		},
+	)
+	go jobs.Start(context.Background())
+
+	bookHandler := rest.NewHandler(
+		bookService,
+		userService,
+		seriesService,
+		reviewService,
+		shelfService,
+		readingService,
+		circulationService,
+		holdService,
+		fineService,
+		branchService,
+		oaiService,
+		fileService,
+		enrichService,
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", bookHandler.InitRoutes())
+	if publicFiles != nil {
+		// Only covers are public; book files are served by the API to
+		// signed-in users.
+		mux.Handle("/files/covers/", publicFiles)
+	}
 
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	server := http.Server{
 		Addr:    addr,
-		Handler: bookHandler.InitRoutes(),
+		Handler: mux,
 	}
 
 	log.Info("Server started")
@@ -7,7 +7,9 @@ import (
 import "github.com/kelseyhightower/envconfig"
 
 type Config struct {
-	DB Postgres
+	DB   Postgres
+	S3   S3Credentials
+	SMTP SMTPCredentials
 
 	Server struct {
 		Host string `mapstructure:"host"`
@@ -17,6 +19,81 @@ type Config struct {
 	Auth struct {
 		TokenTTL time.Duration `mapstructure:"token_ttl"`
 	} `mapstructure:"auth"`
+
+	Storage struct {
+		Driver string `mapstructure:"driver"`
+
+		Local struct {
+			Dir     string `mapstructure:"dir"`
+			BaseURL string `mapstructure:"base_url"`
+		} `mapstructure:"local"`
+
+		S3 struct {
+			Endpoint string `mapstructure:"endpoint"`
+			Region   string `mapstructure:"region"`
+			Bucket   string `mapstructure:"bucket"`
+			BaseURL  string `mapstructure:"base_url"`
+		} `mapstructure:"s3"`
+	} `mapstructure:"storage"`
+
+	Covers struct {
+		MaxSize        int64 `mapstructure:"max_size"`
+		ThumbnailSizes []int `mapstructure:"thumbnail_sizes"`
+	} `mapstructure:"covers"`
+
+	Files struct {
+		MaxSize int64 `mapstructure:"max_size"`
+	} `mapstructure:"files"`
+
+	Metadata struct {
+		BaseURL   string `mapstructure:"base_url"`
+		CoversURL string `mapstructure:"covers_url"`
+	} `mapstructure:"metadata"`
+
+	Circulation struct {
+		LoanPeriod time.Duration `mapstructure:"loan_period"`
+		MaxLoans   int           `mapstructure:"max_loans"`
+
+		PickupPeriod time.Duration `mapstructure:"pickup_period"`
+	} `mapstructure:"circulation"`
+
+	OAI struct {
+		RepositoryName       string `mapstructure:"repository_name"`
+		BaseURL              string `mapstructure:"base_url"`
+		AdminEmail           string `mapstructure:"admin_email"`
+		RepositoryIdentifier string `mapstructure:"repository_identifier"`
+	} `mapstructure:"oai"`
+
+	Labels struct {
+		BookURL string `mapstructure:"book_url"`
+	} `mapstructure:"labels"`
+
+	Fines struct {
+		DailyAmount int64         `mapstructure:"daily_amount"`
+		MaxAmount   int64         `mapstructure:"max_amount"`
+		Currency    string        `mapstructure:"currency"`
+		RemindEvery time.Duration `mapstructure:"remind_every"`
+	} `mapstructure:"fines"`
+
+	Scheduler struct {
+		FinesInterval      time.Duration `mapstructure:"fines_interval"`
+		RemindersInterval  time.Duration `mapstructure:"reminders_interval"`
+		HoldExpiryInterval time.Duration `mapstructure:"hold_expiry_interval"`
+	} `mapstructure:"scheduler"`
+
+	Notifier struct {
+		Driver string `mapstructure:"driver"`
+
+		Email struct {
+			Host string `mapstructure:"host"`
+			Port int    `mapstructure:"port"`
+			From string `mapstructure:"from"`
+		} `mapstructure:"email"`
+
+		Webhook struct {
+			URL string `mapstructure:"url"`
+		} `mapstructure:"webhook"`
+	} `mapstructure:"notifier"`
 }
 
 type Postgres struct {
@@ -28,6 +105,16 @@ type Postgres struct {
 	Password string
 }
 
+type S3Credentials struct {
+	AccessKey string `envconfig:"access_key"`
+	SecretKey string `envconfig:"secret_key"`
+}
+
+type SMTPCredentials struct {
+	Username string `envconfig:"username"`
+	Password string `envconfig:"password"`
+}
+
 func New(folder, filename string) (*Config, error) {
 	cnf := new(Config)
 
@@ -46,5 +133,13 @@ func New(folder, filename string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := envconfig.Process("s3", &cnf.S3); err != nil {
+		return nil, err
+	}
+
+	if err := envconfig.Process("smtp", &cnf.SMTP); err != nil {
+		return nil, err
+	}
+
 	return cnf, nil
 }
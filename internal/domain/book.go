@@ -9,27 +9,184 @@ var (
 	ErrorEmptyRequiredField   = errors.New("required field is empty")
 	ErrorBookNotFound         = errors.New("book not found")
 	ErrorEmptyUpdateBookInput = errors.New("empty update book input")
+	ErrorCoverTooLarge        = errors.New("cover image is too large")
+	ErrorUnsupportedCoverType = errors.New("unsupported cover image type")
+	ErrorDuplicateISBN        = errors.New("a book with this isbn already exists")
+	ErrorVersionMismatch      = errors.New("book has been changed since it was read")
 )
 
 type Book struct {
 	ID          int64     `json:"id"`
-	Title       string    `json:"title"`
-	Author      string    `json:"author"`
-	PublishDate time.Time `json:"publish_date"`
-	Rating      int       `json:"rating"`
+	Title       string    `json:"title" validate:"required,max=1000"`
+	Author      string    `json:"author" validate:"required,max=1000"`
+	ISBN        string    `json:"isbn" validate:"omitempty,isbn"`
+	Description string    `json:"description" validate:"max=20000"`
+	Language    string    `json:"language" validate:"omitempty,max=35,language"`
+	PageCount   int       `json:"page_count" validate:"min=0,max=100000"`
+	Subjects    []string  `json:"subjects" validate:"max=100,dive,required,max=255"`
+	PublishDate time.Time `json:"publish_date" validate:"publish_date"`
+	SeriesID    *int64    `json:"series_id" validate:"omitempty,min=1"`
+	Volume      *float64  `json:"volume" validate:"omitempty,min=0"`
+
+	Rating          float64 `json:"rating" validate:"min=0,max=5"`
+	RatingCount     int64   `json:"rating_count" validate:"min=0"`
+	RatingHistogram []int64 `json:"rating_histogram"`
+
+	Cover         string            `json:"-"`
+	CoverURL      string            `json:"cover_url,omitempty"`
+	ThumbnailURLs map[string]string `json:"thumbnail_urls,omitempty"`
+
+	// Locale is the language Accept-Language negotiation picked for the
+	// title and description; it is empty when nothing was negotiated.
+	Locale string `json:"locale,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Version grows with every change of the book and makes its ETag.
+	Version int64 `json:"version"`
 }
 
 type UpdateBookInput struct {
-	Title       *string    `json:"title"`
-	Author      *string    `json:"author"`
-	PublishDate *time.Time `json:"publish_date"`
-	Rating      *int       `json:"rating"`
+	Title       *string    `json:"title" validate:"omitempty,min=1,max=1000"`
+	Author      *string    `json:"author" validate:"omitempty,min=1,max=1000"`
+	ISBN        *string    `json:"isbn" validate:"omitempty,isbn"`
+	Description *string    `json:"description" validate:"omitempty,max=20000"`
+	Language    *string    `json:"language" validate:"omitempty,max=35,language"`
+	PageCount   *int       `json:"page_count" validate:"omitempty,min=0,max=100000"`
+	Subjects    *[]string  `json:"subjects" validate:"omitempty,max=100,dive,required,max=255"`
+	PublishDate *time.Time `json:"publish_date" validate:"omitempty,publish_date"`
+	SeriesID    *int64     `json:"series_id" validate:"omitempty,min=1"`
+	Volume      *float64   `json:"volume" validate:"omitempty,min=0"`
+
+	// A nil SeriesID or Volume leaves the field as it is; these set it
+	// to null instead.
+	ClearSeries bool `json:"-"`
+	ClearVolume bool `json:"-"`
+
+	// Version, when set, makes the update fail with ErrorVersionMismatch
+	// unless the book is still at this version.
+	Version int64 `json:"-"`
+}
+
+// BookFields are the fields of a book clients write. PUT replaces all of
+// them and patches are applied to them, so a field left out is cleared.
+type BookFields struct {
+	Title       string     `json:"title" validate:"required,max=1000"`
+	Author      string     `json:"author" validate:"required,max=1000"`
+	ISBN        string     `json:"isbn" validate:"omitempty,isbn"`
+	Description string     `json:"description" validate:"max=20000"`
+	Language    string     `json:"language" validate:"omitempty,max=35,language"`
+	PageCount   int        `json:"page_count" validate:"min=0,max=100000"`
+	Subjects    []string   `json:"subjects" validate:"max=100,dive,required,max=255"`
+	PublishDate *time.Time `json:"publish_date" validate:"omitempty,publish_date"`
+	SeriesID    *int64     `json:"series_id" validate:"omitempty,min=1"`
+	Volume      *float64   `json:"volume" validate:"omitempty,min=0"`
+}
+
+// Book listing orders.
+const (
+	BookSortNewest   = "newest"
+	BookSortTopRated = "top_rated"
+)
+
+// BookFilter narrows book listings; zero values match every book. Query
+// matches either the title or the author, Author any part of the author and
+// ExactAuthor the whole of it. Search is a full-text query over
+// titles and descriptions, translations included, stemmed by the rules of
+// each text's language.
+type BookFilter struct {
+	Query         string
+	Search        string
+	Title         string
+	Author        string
+	ExactAuthor   string
+	ISBN          string
+	SeriesID      *int64
+	PublishedFrom *time.Time
+	PublishedTo   *time.Time
+	UpdatedFrom   *time.Time
+	UpdatedBefore *time.Time
+}
+
+type BooksPage struct {
+	Items   []Book `json:"items"`
+	Total   int64  `json:"total"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+}
+
+type AuthorsPage struct {
+	Items   []AuthorCount `json:"items"`
+	Total   int64         `json:"total"`
+	Page    int           `json:"page"`
+	PerPage int           `json:"per_page"`
 }
 
-func (b Book) Validate() bool {
-	if b.Title == "" || b.Author == "" {
-		return false
+// Validate checks the book against the catalog rules, returning a
+// ValidationError that lists every invalid field.
+func (b Book) Validate() error {
+	return validationError(validate.Struct(b))
+}
+
+func (inp UpdateBookInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
+
+func (f BookFields) Validate() error {
+	return validationError(validate.Struct(f))
+}
+
+// Fields returns the writable fields of the book.
+func (b Book) Fields() BookFields {
+	f := BookFields{
+		Title:       b.Title,
+		Author:      b.Author,
+		ISBN:        b.ISBN,
+		Description: b.Description,
+		Language:    b.Language,
+		PageCount:   b.PageCount,
+		Subjects:    b.Subjects,
+		SeriesID:    b.SeriesID,
+		Volume:      b.Volume,
+	}
+
+	if f.Subjects == nil {
+		f.Subjects = make([]string, 0)
 	}
 
-	return true
+	if !b.PublishDate.IsZero() {
+		publishDate := b.PublishDate
+		f.PublishDate = &publishDate
+	}
+
+	return f
+}
+
+// UpdateInput returns an update that sets every field of the book to the
+// given one, clearing those left empty.
+func (f BookFields) UpdateInput() UpdateBookInput {
+	subjects := f.Subjects
+	if subjects == nil {
+		subjects = make([]string, 0)
+	}
+
+	var publishDate time.Time
+	if f.PublishDate != nil {
+		publishDate = *f.PublishDate
+	}
+
+	return UpdateBookInput{
+		Title:       &f.Title,
+		Author:      &f.Author,
+		ISBN:        &f.ISBN,
+		Description: &f.Description,
+		Language:    &f.Language,
+		PageCount:   &f.PageCount,
+		Subjects:    &subjects,
+		PublishDate: &publishDate,
+		SeriesID:    f.SeriesID,
+		Volume:      f.Volume,
+		ClearSeries: f.SeriesID == nil,
+		ClearVolume: f.Volume == nil,
+	}
 }
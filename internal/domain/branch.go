@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	TransferRequested = "requested"
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+	TransferCancelled = "cancelled"
+)
+
+var (
+	ErrorBranchNotFound         = errors.New("branch not found")
+	ErrorEmptyUpdateBranchInput = errors.New("empty update branch input")
+	ErrorTransferNotFound       = errors.New("transfer not found")
+	ErrorInvalidTransferState   = errors.New("transfer can not move to the requested state")
+	ErrorSameBranchTransfer     = errors.New("copy is already at the destination branch")
+	ErrorCopyInTransit          = errors.New("copy is being transferred between branches")
+	ErrorTransferBranchMismatch = errors.New("transfer does not go to the pickup branch of the hold")
+)
+
+type Branch struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name" validate:"required,max=255"`
+	Address string `json:"address" validate:"max=1000"`
+	// OpeningHours maps a weekday ("mon".."sun") to opening intervals such as "09:00-18:00".
+	OpeningHours map[string]string `json:"opening_hours" validate:"dive,keys,oneof=mon tue wed thu fri sat sun,endkeys,max=100"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+type UpdateBranchInput struct {
+	Name         *string            `json:"name" validate:"omitempty,min=1,max=255"`
+	Address      *string            `json:"address" validate:"omitempty,max=1000"`
+	OpeningHours *map[string]string `json:"opening_hours" validate:"omitempty,dive,keys,oneof=mon tue wed thu fri sat sun,endkeys,max=100"`
+}
+
+// BranchAvailability counts copies of a book held at a branch.
+type BranchAvailability struct {
+	BranchID   *int64 `json:"branch_id"`
+	BranchName string `json:"branch_name"`
+	Total      int    `json:"total"`
+	Available  int    `json:"available"`
+}
+
+type Transfer struct {
+	ID           int64      `json:"id"`
+	CopyID       int64      `json:"copy_id" validate:"required"`
+	FromBranchID *int64     `json:"from_branch_id"`
+	ToBranchID   int64      `json:"to_branch_id" validate:"required"`
+	HoldID       *int64     `json:"hold_id"`
+	Status       string     `json:"status"`
+	RequestedBy  int64      `json:"requested_by"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ShippedAt    *time.Time `json:"shipped_at"`
+	ReceivedAt   *time.Time `json:"received_at"`
+}
+
+func (b Branch) Validate() error {
+	return validationError(validate.Struct(b))
+}
+
+func (inp UpdateBranchInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
+
+func (t Transfer) Validate() error {
+	return validationError(validate.Struct(t))
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrorCopyNotFound         = errors.New("copy not found")
+	ErrorDuplicateBarcode     = errors.New("copy with such barcode already exists")
+	ErrorEmptyUpdateCopyInput = errors.New("empty update copy input")
+	ErrorCopyNotAvailable     = errors.New("copy is already checked out")
+	ErrorCopyNotCheckedOut    = errors.New("copy is not checked out")
+	ErrorLoanLimitReached     = errors.New("maximum number of concurrent loans reached")
+	ErrorCopyHasLoans         = errors.New("copy has loan history")
+	ErrorBookHasLoans         = errors.New("book has copies with loan history")
+)
+
+type Copy struct {
+	ID        int64     `json:"id"`
+	BookID    int64     `json:"book_id"`
+	BranchID  *int64    `json:"branch_id"`
+	Barcode   string    `json:"barcode" validate:"required,max=64"`
+	Location  string    `json:"location" validate:"max=255"`
+	Condition string    `json:"condition" validate:"omitempty,oneof=new good fair poor damaged"`
+	Available bool      `json:"available"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UpdateCopyInput struct {
+	BranchID  *int64  `json:"branch_id"`
+	Barcode   *string `json:"barcode" validate:"omitempty,min=1,max=64"`
+	Location  *string `json:"location" validate:"omitempty,max=255"`
+	Condition *string `json:"condition" validate:"omitempty,oneof=new good fair poor damaged"`
+}
+
+type Loan struct {
+	ID           int64      `json:"id"`
+	CopyID       int64      `json:"copy_id"`
+	BookID       int64      `json:"book_id"`
+	UserID       int64      `json:"user_id"`
+	CheckedOutAt time.Time  `json:"checked_out_at"`
+	DueAt        time.Time  `json:"due_at"`
+	ReturnedAt   *time.Time `json:"returned_at"`
+}
+
+func (c Copy) Validate() error {
+	return validationError(validate.Struct(c))
+}
+
+func (inp UpdateCopyInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "errors"
+
+var ErrorInvalidMerge = errors.New("merge needs a target and other books to merge into it")
+
+// DuplicatePair scores how likely two books are the same record. Books with
+// the same ISBN score 1.
+type DuplicatePair struct {
+	BookIDs          [2]int64 `json:"book_ids"`
+	Score            float64  `json:"score"`
+	TitleSimilarity  float64  `json:"title_similarity"`
+	AuthorSimilarity float64  `json:"author_similarity"`
+	SameISBN         bool     `json:"same_isbn"`
+}
+
+// DuplicateCluster groups books connected by candidate pairs. Its score is
+// that of its strongest pair.
+type DuplicateCluster struct {
+	Score float64         `json:"score"`
+	Books []Book          `json:"books"`
+	Pairs []DuplicatePair `json:"pairs"`
+}
+
+type DuplicatesPage struct {
+	Items   []DuplicateCluster `json:"items"`
+	Total   int64              `json:"total"`
+	Page    int                `json:"page"`
+	PerPage int                `json:"per_page"`
+}
+
+type MergeBooksInput struct {
+	TargetID  int64   `json:"target_id" validate:"required"`
+	SourceIDs []int64 `json:"source_ids" validate:"required,min=1,max=50,dive,required"`
+}
+
+func (inp MergeBooksInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
+
+// MergeResult is the surviving book along with the ids merged into it and
+// the fields it took over from them.
+type MergeResult struct {
+	Book   Book     `json:"book"`
+	Merged []int64  `json:"merged"`
+	Filled []string `json:"filled"`
+}
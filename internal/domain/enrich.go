@@ -0,0 +1,32 @@
+package domain
+
+import "errors"
+
+var (
+	ErrorBookWithoutISBN     = errors.New("book has no isbn to look up")
+	ErrorMetadataNotFound    = errors.New("no metadata found for the book")
+	ErrorUnknownEnrichField  = errors.New("field is not part of the proposal")
+	ErrorMetadataUnavailable = errors.New("metadata provider is unavailable")
+)
+
+// FieldProposal pairs the current value of a book field with the one the
+// metadata provider suggests.
+type FieldProposal struct {
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Proposed interface{} `json:"proposed"`
+	Changed  bool        `json:"changed"`
+}
+
+// EnrichInput lists the proposed fields to apply; none means the proposal
+// is only shown.
+type EnrichInput struct {
+	Accept []string `json:"accept"`
+}
+
+type EnrichResult struct {
+	ISBN     string          `json:"isbn"`
+	Proposal []FieldProposal `json:"proposal"`
+	Applied  []string        `json:"applied"`
+	Book     Book            `json:"book"`
+}
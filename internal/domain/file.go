@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	BookFileEPUB = "epub"
+	BookFilePDF  = "pdf"
+)
+
+var (
+	ErrorBookFileNotFound        = errors.New("book file not found")
+	ErrorBookFileTooLarge        = errors.New("book file is too large")
+	ErrorUnsupportedBookFileType = errors.New("unsupported book file type")
+	ErrorInvalidBookFile         = errors.New("book file is damaged or unreadable")
+)
+
+// BookFile is an electronic edition of a book kept in the blob store.
+type BookFile struct {
+	ID          int64        `json:"id"`
+	BookID      int64        `json:"book_id"`
+	Format      string       `json:"format"`
+	Name        string       `json:"name"`
+	ContentType string       `json:"content_type"`
+	Size        int64        `json:"size"`
+	SHA256      string       `json:"sha256"`
+	Key         string       `json:"-"`
+	Metadata    FileMetadata `json:"metadata"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// FileMetadata is what the file says about the book: the OPF package
+// document of an EPUB or the information dictionary of a PDF.
+type FileMetadata struct {
+	Title       string     `json:"title,omitempty"`
+	Authors     []string   `json:"authors,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Identifiers []string   `json:"identifiers,omitempty"`
+	ISBN        string     `json:"isbn,omitempty"`
+	Publisher   string     `json:"publisher,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Subjects    []string   `json:"subjects,omitempty"`
+	PublishDate *time.Time `json:"publish_date,omitempty"`
+	HasCover    bool       `json:"has_cover"`
+}
+
+// FieldDiff is a book field whose value differs from the one in the file.
+type FieldDiff struct {
+	Field string      `json:"field"`
+	Book  interface{} `json:"book"`
+	File  interface{} `json:"file"`
+}
+
+// BookFileUpload reports a stored file together with how its metadata
+// compares to the book. Prefilled lists the empty book fields that were
+// filled in from the file.
+type BookFileUpload struct {
+	File      BookFile    `json:"file"`
+	Book      Book        `json:"book"`
+	Diff      []FieldDiff `json:"diff"`
+	Prefilled []string    `json:"prefilled"`
+}
+
+// BookFromFile is a book cataloged from an uploaded file along with the
+// fields the file did not provide. CoverError tells why the embedded cover
+// could not be stored; the book is created regardless.
+type BookFromFile struct {
+	Book       Book     `json:"book"`
+	Missing    []string `json:"missing"`
+	CoverError string   `json:"cover_error,omitempty"`
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	FineOpen   = "open"
+	FinePaid   = "paid"
+	FineWaived = "waived"
+)
+
+var (
+	ErrorFineNotFound = errors.New("fine not found")
+	ErrorFineNotOpen  = errors.New("fine is already paid or waived")
+)
+
+// Fine accrues daily on an overdue loan until it is returned, paid or waived.
+// Amount is in minor currency units.
+type Fine struct {
+	ID         int64      `json:"id"`
+	LoanID     int64      `json:"loan_id"`
+	UserID     int64      `json:"user_id"`
+	Amount     int64      `json:"amount"`
+	Currency   string     `json:"currency"`
+	Status     string     `json:"status"`
+	Note       string     `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	ResolvedBy *int64     `json:"resolved_by"`
+}
+
+// FineRate is what an overdue loan costs: DailyAmount for every full day
+// past the due date, up to MaxAmount.
+type FineRate struct {
+	DailyAmount int64
+	MaxAmount   int64
+}
+
+type ResolveFineInput struct {
+	Note string `json:"note" validate:"max=1000"`
+}
+
+// OverdueLoan is an overdue loan together with what is needed to remind the borrower.
+type OverdueLoan struct {
+	Loan
+	UserName  string
+	UserEmail string
+	BookTitle string
+}
+
+func (inp ResolveFineInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
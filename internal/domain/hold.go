@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	HoldWaiting   = "waiting"
+	HoldReady     = "ready"
+	HoldFulfilled = "fulfilled"
+	HoldCancelled = "cancelled"
+	HoldExpired   = "expired"
+)
+
+var (
+	ErrorHoldNotFound      = errors.New("hold not found")
+	ErrorHoldAlreadyExists = errors.New("user already has an active hold on this book")
+	ErrorHoldClosed        = errors.New("hold is no longer active")
+	ErrorCopiesAvailable   = errors.New("book has copies available for checkout")
+	ErrorCopyReserved      = errors.New("copy is reserved for another user")
+)
+
+type Hold struct {
+	ID     int64 `json:"id"`
+	BookID int64 `json:"book_id"`
+	UserID int64 `json:"user_id"`
+	// PickupBranchID limits the hold to copies at that branch; nil accepts any branch.
+	PickupBranchID *int64 `json:"pickup_branch_id"`
+	Status         string `json:"status"`
+	// Position is the 1-based place in the queue of a waiting hold and 0 otherwise.
+	Position       int        `json:"position"`
+	CopyID         *int64     `json:"copy_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReadyAt        *time.Time `json:"ready_at"`
+	PickupDeadline *time.Time `json:"pickup_deadline"`
+	ClosedAt       *time.Time `json:"closed_at"`
+}
+
+type PlaceHoldInput struct {
+	PickupBranchID *int64 `json:"pickup_branch_id"`
+}
+
+// ReturnResult describes a returned loan and the hold the copy was assigned to, if any.
+type ReturnResult struct {
+	Loan Loan  `json:"loan"`
+	Hold *Hold `json:"hold,omitempty"`
+}
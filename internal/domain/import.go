@@ -0,0 +1,41 @@
+package domain
+
+import "errors"
+
+// Formats accepted by the book import; the MARC formats are also used for export.
+const (
+	ImportFormatCSV     = "csv"
+	ImportFormatNDJSON  = "ndjson"
+	ImportFormatMARC    = "marc"
+	ImportFormatMARCXML = "marcxml"
+)
+
+var (
+	ErrorUnsupportedImportFormat = errors.New("unsupported import format")
+	ErrorMissingImportColumn     = errors.New("required import column is missing")
+)
+
+// ImportOptions controls how POST /books/import reads its input. Columns
+// maps book fields (title, author, isbn, publish_date, series_id, volume)
+// to CSV header names; unmapped fields are looked up by their own name.
+type ImportOptions struct {
+	Format    string
+	Columns   map[string]string
+	Delimiter rune
+	DryRun    bool
+}
+
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+type ImportReport struct {
+	DryRun  bool             `json:"dry_run"`
+	Total   int              `json:"total"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrorInvalidISBN = errors.New("invalid isbn")
+
+// ISBN13 normalizes an ISBN-10 or ISBN-13, with or without hyphens and
+// spaces, to its 13-digit form. The check digit of the input is verified.
+func ISBN13(isbn string) (string, error) {
+	isbn = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, c := range isbn {
+			var d int
+			switch {
+			case c >= '0' && c <= '9':
+				d = int(c - '0')
+			case c == 'X' && i == 9:
+				d = 10
+			default:
+				return "", ErrorInvalidISBN
+			}
+			sum += d * (10 - i)
+		}
+		if sum%11 != 0 {
+			return "", ErrorInvalidISBN
+		}
+
+		digits := "978" + isbn[:9]
+		return digits + string(isbn13CheckDigit(digits)), nil
+	case 13:
+		for _, c := range isbn {
+			if c < '0' || c > '9' {
+				return "", ErrorInvalidISBN
+			}
+		}
+		if !strings.HasPrefix(isbn, "978") && !strings.HasPrefix(isbn, "979") {
+			return "", ErrorInvalidISBN
+		}
+		if isbn13CheckDigit(isbn[:12]) != rune(isbn[12]) {
+			return "", ErrorInvalidISBN
+		}
+
+		return isbn, nil
+	default:
+		return "", ErrorInvalidISBN
+	}
+}
+
+func isbn13CheckDigit(digits string) rune {
+	sum := 0
+	for i, c := range digits {
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return rune('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestISBN13(t *testing.T) {
+	tests := []struct {
+		isbn string
+		want string
+	}{
+		{"0-306-40615-2", "9780306406157"},
+		{"080442957X", "9780804429573"},
+		{"080442957x", "9780804429573"},
+		{"978 0 306 40615 7", "9780306406157"},
+		{"9780306406157", "9780306406157"},
+		{"979-10-90636-07-1", "9791090636071"},
+	}
+
+	for _, tt := range tests {
+		got, err := ISBN13(tt.isbn)
+		if err != nil {
+			t.Errorf("ISBN13(%q): %v", tt.isbn, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ISBN13(%q) = %s, want %s", tt.isbn, got, tt.want)
+		}
+	}
+}
+
+func TestISBN13Invalid(t *testing.T) {
+	for _, isbn := range []string{
+		"",
+		"0306406153",     // wrong ISBN-10 check digit
+		"03064061X2",     // X before the check digit
+		"9780306406158",  // wrong ISBN-13 check digit
+		"9770306406152",  // not a book EAN prefix
+		"978030640615a",  // not a digit
+		"97803064061570", // too long
+		"030640615",      // too short
+	} {
+		if _, err := ISBN13(isbn); !errors.Is(err, ErrorInvalidISBN) {
+			t.Errorf("ISBN13(%q) err = %v, want %v", isbn, err, ErrorInvalidISBN)
+		}
+	}
+}
+
+func TestISBN13CheckDigit(t *testing.T) {
+	tests := map[string]rune{
+		"978030640615": '7',
+		"978080442957": '3',
+		"979109063607": '1',
+		"978000000000": '2',
+	}
+
+	for digits, want := range tests {
+		if got := isbn13CheckDigit(digits); got != want {
+			t.Errorf("isbn13CheckDigit(%s) = %c, want %c", digits, got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// OAIDatestamp is the layout of OAI-PMH datestamps at seconds granularity.
+const OAIDatestamp = "2006-01-02T15:04:05Z"
+
+// OAI-PMH error codes, reported inside a successful response.
+const (
+	OAIBadArgument             = "badArgument"
+	OAIBadResumptionToken      = "badResumptionToken"
+	OAIBadVerb                 = "badVerb"
+	OAICannotDisseminateFormat = "cannotDisseminateFormat"
+	OAIIdDoesNotExist          = "idDoesNotExist"
+	OAINoRecordsMatch          = "noRecordsMatch"
+)
+
+type OAIError struct {
+	Code    string
+	Message string
+}
+
+func (e OAIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+type OAIRepository struct {
+	Name              string
+	BaseURL           string
+	AdminEmail        string
+	EarliestDatestamp time.Time
+	Identifier        string
+}
+
+type OAIMetadataFormat struct {
+	Prefix    string
+	Schema    string
+	Namespace string
+}
+
+type OAISet struct {
+	Spec string
+	Name string
+}
+
+// OAIQuery holds the raw selective harvesting arguments of a list request.
+type OAIQuery struct {
+	MetadataPrefix  string
+	From            string
+	Until           string
+	Set             string
+	ResumptionToken string
+}
+
+type OAIRecord struct {
+	Identifier string
+	Datestamp  time.Time
+	SetSpecs   []string
+	Book       Book
+}
+
+type OAIPage struct {
+	Records          []OAIRecord
+	ResumptionToken  string
+	CompleteListSize int
+	Cursor           int
+}
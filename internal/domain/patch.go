@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+// Media types of the patch documents PATCH /books/{id} accepts.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	ErrorUnsupportedPatchType = errors.New("unsupported patch media type")
+	ErrorInvalidPatch         = errors.New("patch document is malformed")
+	ErrorPatchNotApplicable   = errors.New("patch can not be applied to the book")
+	ErrorPatchTestFailed      = errors.New("patch test operation failed")
+)
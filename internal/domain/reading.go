@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+type ReadingSession struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	BookID     int64      `json:"book_id"`
+	Pages      *int       `json:"pages" validate:"required_without=Percent,omitempty,min=0"`
+	Percent    *float64   `json:"percent" validate:"omitempty,min=0,max=100"`
+	StartedAt  time.Time  `json:"started_at" validate:"required"`
+	FinishedAt *time.Time `json:"finished_at" validate:"required_if=Completed true,omitempty,gtefield=StartedAt"`
+	Completed  bool       `json:"completed"`
+}
+
+// ReadingProgress summarizes all sessions a user has logged for a book.
+type ReadingProgress struct {
+	BookID      int64      `json:"book_id"`
+	Sessions    int        `json:"sessions"`
+	PagesRead   int        `json:"pages_read"`
+	Percent     *float64   `json:"percent"`
+	StartedAt   *time.Time `json:"started_at"`
+	LastReadAt  *time.Time `json:"last_read_at"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+type ReadingStats struct {
+	Year          int           `json:"year"`
+	BooksFinished int           `json:"books_finished"`
+	PagesRead     int           `json:"pages_read"`
+	AverageRating *float64      `json:"average_rating"`
+	TopAuthors    []AuthorCount `json:"top_authors"`
+}
+
+type AuthorCount struct {
+	Author string `json:"author"`
+	Books  int    `json:"books"`
+}
+
+func (s ReadingSession) Validate() error {
+	return validationError(validate.Struct(s))
+}
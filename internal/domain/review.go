@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrorReviewNotFound      = errors.New("review not found")
+	ErrorReviewAlreadyExists = errors.New("review already exists")
+)
+
+type Review struct {
+	UserID    int64     `json:"user_id"`
+	BookID    int64     `json:"book_id"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ReviewInput struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Text   string `json:"text" validate:"max=10000"`
+}
+
+type ReviewsPage struct {
+	Items   []Review `json:"items"`
+	Total   int64    `json:"total"`
+	Page    int      `json:"page"`
+	PerPage int      `json:"per_page"`
+}
+
+func (inp ReviewInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
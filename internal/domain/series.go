@@ -0,0 +1,24 @@
+package domain
+
+import "errors"
+
+var (
+	ErrorSeriesNotFound         = errors.New("series not found")
+	ErrorEmptyUpdateSeriesInput = errors.New("empty update series input")
+)
+
+type Series struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Volumes     []Book `json:"volumes,omitempty"`
+}
+
+type UpdateSeriesInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+func (s Series) Validate() bool {
+	return s.Name != ""
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	ShelfWantToRead = "want_to_read"
+	ShelfReading    = "reading"
+	ShelfRead       = "read"
+	ShelfCustom     = "custom"
+)
+
+var (
+	ErrorShelfNotFound         = errors.New("shelf not found")
+	ErrorShelfNotEditable      = errors.New("default shelf can not be renamed or deleted")
+	ErrorBookAlreadyOnShelf    = errors.New("book is already on the shelf")
+	ErrorBookNotOnShelf        = errors.New("book is not on the shelf")
+	ErrorInvalidShelfOrder     = errors.New("order must list every book on the shelf exactly once")
+	ErrorEmptyUpdateShelfInput = errors.New("empty update shelf input")
+)
+
+// DefaultShelves are created for every user on first access.
+var DefaultShelves = []Shelf{
+	{Name: "Want to read", Kind: ShelfWantToRead},
+	{Name: "Reading", Kind: ShelfReading},
+	{Name: "Read", Kind: ShelfRead},
+}
+
+type Shelf struct {
+	ID         int64       `json:"id"`
+	UserID     int64       `json:"user_id"`
+	Name       string      `json:"name" validate:"required,max=100"`
+	Kind       string      `json:"kind"`
+	Public     bool        `json:"public"`
+	ShareToken string      `json:"share_token,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Books      []ShelfBook `json:"books,omitempty"`
+}
+
+type ShelfBook struct {
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+	Book     Book      `json:"book"`
+}
+
+type UpdateShelfInput struct {
+	Name   *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Public *bool   `json:"public"`
+}
+
+type AddShelfBookInput struct {
+	BookID int64 `json:"book_id" validate:"required"`
+}
+
+type ReorderShelfInput struct {
+	BookIDs []int64 `json:"book_ids" validate:"required"`
+}
+
+func (s Shelf) Validate() error {
+	return validationError(validate.Struct(s))
+}
+
+func (inp UpdateShelfInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
+
+func (inp AddShelfBookInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
+
+func (inp ReorderShelfInput) Validate() error {
+	return validationError(validate.Struct(inp))
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "errors"
+
+var (
+	ErrorInvalidLanguage     = errors.New("invalid language tag")
+	ErrorTranslationNotFound = errors.New("translation not found")
+)
+
+// BookTranslation is the title and description of a book in another
+// language, keyed by a BCP 47 locale such as "ru" or "uz-Cyrl".
+type BookTranslation struct {
+	Locale      string `json:"locale"`
+	Title       string `json:"title" validate:"required,max=1000"`
+	Description string `json:"description" validate:"max=20000"`
+}
+
+func (t BookTranslation) Validate() error {
+	return validationError(validate.Struct(t))
+}
@@ -8,10 +8,16 @@ import (
 
 var validate *validator.Validate
 
+const (
+	RoleReader    = "reader"
+	RoleLibrarian = "librarian"
+)
+
 var ErrorUserNotFound = errors.New("user with such credentials not found")
 
 func init() {
 	validate = validator.New()
+	registerValidations(validate)
 }
 
 type User struct {
@@ -19,6 +25,7 @@ type User struct {
 	Name         string    `json:"name"`
 	Email        string    `json:"email"`
 	Password     string    `json:"password"`
+	Role         string    `json:"role"`
 	RegisteredAt time.Time `json:"registered_at"`
 }
 
@@ -34,9 +41,9 @@ type SignInInput struct {
 }
 
 func (inp SignUpInput) Validate() error {
-	return validate.Struct(inp)
+	return validationError(validate.Struct(inp))
 }
 
 func (inp SignInInput) Validate() error {
-	return validate.Struct(inp)
+	return validationError(validate.Struct(inp))
 }
@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// minPublishYear is the year of the earliest printed books; dates of
+// announced editions may lie up to a year ahead.
+const minPublishYear = 1450
+
+var ErrorValidation = errors.New("validation failed")
+
+// FieldError is a field of the input that broke a rule, named as in JSON.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError lists every invalid field of an input. It matches
+// ErrorValidation with errors.Is.
+type ValidationError struct {
+	Fields []FieldError `json:"errors"`
+}
+
+func (e ValidationError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		reasons[i] = f.Field + " " + f.Reason
+	}
+
+	return fmt.Sprintf("%s: %s", ErrorValidation, strings.Join(reasons, "; "))
+}
+
+func (e ValidationError) Unwrap() error {
+	return ErrorValidation
+}
+
+func registerValidations(v *validator.Validate) {
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	// Empty values pass the rules below, so that fields behind pointers
+	// can be cleared; required makes them mandatory.
+	v.RegisterValidation("isbn", func(fl validator.FieldLevel) bool {
+		if fl.Field().String() == "" {
+			return true
+		}
+
+		_, err := ISBN13(fl.Field().String())
+		return err == nil
+	})
+
+	v.RegisterValidation("language", func(fl validator.FieldLevel) bool {
+		if fl.Field().String() == "" {
+			return true
+		}
+
+		_, err := language.Parse(fl.Field().String())
+		return err == nil
+	})
+
+	v.RegisterValidation("publish_date", func(fl validator.FieldLevel) bool {
+		date, ok := fl.Field().Interface().(time.Time)
+		if !ok {
+			return false
+		}
+
+		return date.IsZero() || (date.Year() >= minPublishYear && date.Before(time.Now().AddDate(1, 0, 0)))
+	})
+}
+
+// validationError turns the errors of the validator into a ValidationError
+// and passes any other error through.
+func validationError(err error) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+
+	result := ValidationError{Fields: make([]FieldError, 0, len(errs))}
+	for _, e := range errs {
+		// The namespace starts with the struct name.
+		field := e.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		result.Fields = append(result.Fields, FieldError{Field: field, Reason: fieldReason(e)})
+	}
+
+	return result
+}
+
+func fieldReason(e validator.FieldError) string {
+	isString := e.Kind() == reflect.String
+	isList := e.Kind() == reflect.Slice || e.Kind() == reflect.Map
+
+	switch e.Tag() {
+	case "required", "required_if", "required_without":
+		return "is required"
+	case "min":
+		switch {
+		case isString && e.Param() == "1":
+			return "must not be empty"
+		case isString:
+			return fmt.Sprintf("must be at least %s characters long", e.Param())
+		case isList:
+			return fmt.Sprintf("must have at least %s items", e.Param())
+		}
+		return fmt.Sprintf("must be at least %s", e.Param())
+	case "gte":
+		return fmt.Sprintf("must be at least %s characters long", e.Param())
+	case "gtefield":
+		return "must not be before " + snakeCase(e.Param())
+	case "email":
+		return "is not a valid email address"
+	case "max":
+		switch {
+		case isString:
+			return fmt.Sprintf("must be at most %s characters long", e.Param())
+		case isList:
+			return fmt.Sprintf("must have at most %s items", e.Param())
+		}
+		return fmt.Sprintf("must be at most %s", e.Param())
+	case "isbn":
+		return "is not a valid ISBN-10 or ISBN-13"
+	case "publish_date":
+		return fmt.Sprintf("must be between %d and a year from now", minPublishYear)
+	case "language":
+		return "is not a valid BCP 47 language tag"
+	case "oneof":
+		return "must be one of " + e.Param()
+	default:
+		return fmt.Sprintf("failed the %s rule", e.Tag())
+	}
+}
+
+// snakeCase turns the Go name of a field, as cross-field rules refer to it,
+// into its JSON name.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -5,9 +5,27 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
 	"strings"
+	"time"
 )
 
+const bookColumns = "id, title, author, coalesce(isbn, ''), coalesce(description, ''), coalesce(language, ''), " +
+	"coalesce(page_count, 0), coalesce(subjects, '{}'), publish_date, series_id, volume, " +
+	bookRating + ", rating_count, rating_histogram, " +
+	"coalesce(cover, ''), updated_at, version"
+
+const bookRating = "case when rating_count > 0 then rating_sum::float8 / rating_count else 0 end"
+
+var bookSortOrders = map[string]string{
+	domain.BookSortNewest:   "id desc",
+	domain.BookSortTopRated: bookRating + " desc, rating_count desc, id",
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 type BookRepository struct {
 	db *sql.DB
 }
@@ -19,20 +37,26 @@ func NewBookRepository(db *sql.DB) *BookRepository {
 }
 
 func (r BookRepository) Create(ctx context.Context, book domain.Book) (int64, error) {
-	if !book.Validate() {
-		return 0, domain.ErrorEmptyRequiredField
-	}
-
 	result := r.db.QueryRow(
-		"insert into books (title, author, publish_date, rating) values ($1, $2, $3, $4) returning id",
+		"insert into books (title, author, isbn, description, language, page_count, subjects, publish_date, series_id, volume, updated_at, version) "+
+			"values ($1, $2, nullif($3, ''), nullif($4, ''), nullif($5, ''), nullif($6, 0), $7, $8, $9, $10, now(), 1) returning id",
 		book.Title,
 		book.Author,
+		book.ISBN,
+		book.Description,
+		book.Language,
+		book.PageCount,
+		pq.Array(book.Subjects),
 		book.PublishDate,
-		book.Rating,
+		book.SeriesID,
+		book.Volume,
 	)
 
 	var id int64
 	err := result.Scan(&id)
+	if isUniqueViolation(err) {
+		return 0, domain.ErrorDuplicateISBN
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -40,32 +64,120 @@ func (r BookRepository) Create(ctx context.Context, book domain.Book) (int64, er
 	return id, nil
 }
 
-func (r BookRepository) GetAll(ctx context.Context) ([]domain.Book, error) {
-	rows, err := r.db.Query("select id, title, author, publish_date, rating from books")
+func (r BookRepository) GetAll(ctx context.Context, filter domain.BookFilter) ([]domain.Book, error) {
+	where, args := bookFilterClause(filter)
+
+	rows, err := r.db.QueryContext(ctx, "select "+bookColumns+" from books"+where+" order by id", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	books := make([]domain.Book, 0)
-	for rows.Next() {
-		var book domain.Book
+	return scanBooks(rows)
+}
 
-		err = rows.Scan(&book.ID, &book.Title, &book.Author, &book.PublishDate, &book.Rating)
-		if err != nil {
-			return nil, err
+// GetAfter returns up to limit books matching the filter with an id greater
+// than afterId, ordered by id, for keyset pagination.
+func (r BookRepository) GetAfter(ctx context.Context, filter domain.BookFilter, afterId int64, limit int) ([]domain.Book, error) {
+	where, args := bookFilterClause(filter)
+
+	args = append(args, afterId, limit)
+	condition := fmt.Sprintf("id>$%d", len(args)-1)
+	if where == "" {
+		where = " where " + condition
+	} else {
+		where += " and " + condition
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"select "+bookColumns+" from books"+where+fmt.Sprintf(" order by id limit $%d", len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBooks(rows)
+}
+
+// GetPage returns one page of books matching the filter in the given order
+// along with the number of matching books.
+func (r BookRepository) GetPage(ctx context.Context, filter domain.BookFilter, sort string, limit, offset int) ([]domain.Book, int64, error) {
+	where, args := bookFilterClause(filter)
+
+	var total int64
+	err := r.db.QueryRowContext(ctx, "select count(*) from books"+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := bookSortOrders[sort]
+	if !ok {
+		order = "id"
+	}
+
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx,
+		"select "+bookColumns+" from books"+where+
+			fmt.Sprintf(" order by %s limit $%d offset $%d", order, len(args)-1, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	books, err := scanBooks(rows)
+
+	return books, total, err
+}
+
+// GetAuthors returns one page of distinct authors with their number of books.
+func (r BookRepository) GetAuthors(ctx context.Context, limit, offset int) ([]domain.AuthorCount, int64, error) {
+	var total int64
+	err := r.db.QueryRowContext(ctx, "select count(distinct author) from books").Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"select author, count(*) from books group by author order by author limit $1 offset $2", limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	authors := make([]domain.AuthorCount, 0)
+	for rows.Next() {
+		var author domain.AuthorCount
+		if err := rows.Scan(&author.Author, &author.Books); err != nil {
+			return nil, 0, err
 		}
 
-		books = append(books, book)
+		authors = append(authors, author)
 	}
 
-	return books, nil
+	return authors, total, rows.Err()
+}
+
+func (r BookRepository) Count(ctx context.Context, filter domain.BookFilter) (int, error) {
+	where, args := bookFilterClause(filter)
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "select count(*) from books"+where, args...).Scan(&count)
+
+	return count, err
+}
+
+// GetEarliestUpdate returns the oldest updated_at in the catalog, or the
+// current time when it is empty.
+func (r BookRepository) GetEarliestUpdate(ctx context.Context) (time.Time, error) {
+	var earliest time.Time
+	err := r.db.QueryRowContext(ctx, "select coalesce(min(updated_at), now()) from books").Scan(&earliest)
+
+	return earliest, err
 }
 
 func (r BookRepository) GetById(ctx context.Context, id int64) (domain.Book, error) {
-	row := r.db.QueryRow("select id, title, author, publish_date, rating from books where id=$1", id)
+	row := r.db.QueryRow("select "+bookColumns+" from books where id=$1", id)
 
-	var book domain.Book
-	err := row.Scan(&book.ID, &book.Title, &book.Author, &book.PublishDate, &book.Rating)
+	book, err := scanBook(row)
 	if err == sql.ErrNoRows {
 		return book, domain.ErrorBookNotFound
 	}
@@ -73,7 +185,64 @@ func (r BookRepository) GetById(ctx context.Context, id int64) (domain.Book, err
 	return book, err
 }
 
+func (r BookRepository) GetBySeries(ctx context.Context, seriesId int64) ([]domain.Book, error) {
+	rows, err := r.db.Query(
+		"select "+bookColumns+" from books where series_id=$1 order by volume nulls last, id", seriesId)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBooks(rows)
+}
+
+func (r BookRepository) GetNextInSeries(ctx context.Context, book domain.Book) (domain.Book, error) {
+	if book.SeriesID == nil || book.Volume == nil {
+		return domain.Book{}, domain.ErrorBookNotFound
+	}
+
+	row := r.db.QueryRow(
+		"select "+bookColumns+" from books where series_id=$1 and volume>$2 order by volume, id limit 1",
+		*book.SeriesID,
+		*book.Volume,
+	)
+
+	next, err := scanBook(row)
+	if err == sql.ErrNoRows {
+		return next, domain.ErrorBookNotFound
+	}
+
+	return next, err
+}
+
 func (r BookRepository) Update(ctx context.Context, id int64, input domain.UpdateBookInput) error {
+	query, args, err := bookUpdateQuery(id, input)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, args...)
+	if isUniqueViolation(err) {
+		return domain.ErrorDuplicateISBN
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 && input.Version != 0 {
+		return domain.ErrorVersionMismatch
+	}
+
+	return nil
+}
+
+// bookUpdateQuery builds the update statement for the fields set in input,
+// limited to the expected version of the book when input has one.
+func bookUpdateQuery(id int64, input domain.UpdateBookInput) (string, []interface{}, error) {
 	fields := make([]string, 0)
 	fieldId := 0
 	args := make([]interface{}, 0)
@@ -90,30 +259,228 @@ func (r BookRepository) Update(ctx context.Context, id int64, input domain.Updat
 		args = append(args, input.Author)
 	}
 
+	if input.ISBN != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("isbn=nullif($%d, '')", fieldId))
+		args = append(args, input.ISBN)
+	}
+
+	if input.Description != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("description=nullif($%d, '')", fieldId))
+		args = append(args, input.Description)
+	}
+
+	if input.Language != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("language=nullif($%d, '')", fieldId))
+		args = append(args, input.Language)
+	}
+
+	if input.PageCount != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("page_count=nullif($%d, 0)", fieldId))
+		args = append(args, input.PageCount)
+	}
+
+	if input.Subjects != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("subjects=$%d", fieldId))
+		args = append(args, pq.Array(*input.Subjects))
+	}
+
 	if input.PublishDate != nil {
 		fieldId++
 		fields = append(fields, fmt.Sprintf("publish_date=$%d", fieldId))
 		args = append(args, input.PublishDate)
 	}
 
-	if input.Rating != nil {
+	if input.SeriesID != nil {
 		fieldId++
-		fields = append(fields, fmt.Sprintf("rating=$%d", fieldId))
-		args = append(args, input.Rating)
+		fields = append(fields, fmt.Sprintf("series_id=$%d", fieldId))
+		args = append(args, input.SeriesID)
+	} else if input.ClearSeries {
+		fields = append(fields, "series_id=null")
 	}
 
-	if fieldId == 0 {
-		return domain.ErrorEmptyUpdateBookInput
+	if input.Volume != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("volume=$%d", fieldId))
+		args = append(args, input.Volume)
+	} else if input.ClearVolume {
+		fields = append(fields, "volume=null")
 	}
 
+	if len(fields) == 0 {
+		return "", nil, domain.ErrorEmptyUpdateBookInput
+	}
+
+	fields = append(fields, "updated_at=now()", "version=version+1")
+
 	query := fmt.Sprintf("update books set %s where id=%d", strings.Join(fields, ", "), id)
+	if input.Version != 0 {
+		fieldId++
+		query += fmt.Sprintf(" and version=$%d", fieldId)
+		args = append(args, input.Version)
+	}
 
-	_, err := r.db.Exec(query, args...)
+	return query, args, nil
+}
 
+func (r BookRepository) SetCover(ctx context.Context, id int64, cover string) error {
+	_, err := r.db.Exec("update books set cover=$1, updated_at=now(), version=version+1 where id=$2", cover, id)
 	return err
 }
 
-func (r BookRepository) Delete(ctx context.Context, id int64) error {
-	_, err := r.db.Exec("delete from books where id=$1", id)
-	return err
+// Delete removes the book; a non-zero version has to match the current one.
+// Books whose copies have been lent are kept for their loan history.
+func (r BookRepository) Delete(ctx context.Context, id, version int64) error {
+	result, err := r.db.Exec("delete from books where id=$1 and ($2=0 or version=$2)", id, version)
+	if isForeignKeyViolation(err) {
+		return domain.ErrorBookHasLoans
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 && version != 0 {
+		return domain.ErrorVersionMismatch
+	}
+
+	return nil
+}
+
+// bookFilterClause builds the where clause, with its arguments, for a book filter.
+func bookFilterClause(filter domain.BookFilter) (string, []interface{}) {
+	conditions := make([]string, 0)
+	fieldId := 0
+	args := make([]interface{}, 0)
+
+	if filter.Query != "" {
+		fieldId++
+		conditions = append(conditions,
+			fmt.Sprintf("(title ilike '%%' || $%d || '%%' or author ilike '%%' || $%d || '%%')", fieldId, fieldId))
+		args = append(args, filter.Query)
+	}
+
+	if filter.Search != "" {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf(
+			"(to_tsvector(%[1]s, books.title || ' ' || coalesce(books.description, '')) @@ websearch_to_tsquery(%[1]s, $%[3]d) "+
+				"or exists (select 1 from book_translations t where t.book_id=books.id and "+
+				"to_tsvector(%[2]s, t.title || ' ' || coalesce(t.description, '')) @@ websearch_to_tsquery(%[2]s, $%[3]d)))",
+			searchConfig("books.language"), searchConfig("t.locale"), fieldId))
+		args = append(args, filter.Search)
+	}
+
+	if filter.Title != "" {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("title ilike '%%' || $%d || '%%'", fieldId))
+		args = append(args, filter.Title)
+	}
+
+	if filter.Author != "" {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("author ilike '%%' || $%d || '%%'", fieldId))
+		args = append(args, filter.Author)
+	}
+
+	if filter.ExactAuthor != "" {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("author=$%d", fieldId))
+		args = append(args, filter.ExactAuthor)
+	}
+
+	if filter.ISBN != "" {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("isbn=$%d", fieldId))
+		args = append(args, filter.ISBN)
+	}
+
+	if filter.SeriesID != nil {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("series_id=$%d", fieldId))
+		args = append(args, *filter.SeriesID)
+	}
+
+	if filter.PublishedFrom != nil {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("publish_date>=$%d", fieldId))
+		args = append(args, *filter.PublishedFrom)
+	}
+
+	if filter.PublishedTo != nil {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("publish_date<=$%d", fieldId))
+		args = append(args, *filter.PublishedTo)
+	}
+
+	if filter.UpdatedFrom != nil {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("updated_at>=$%d", fieldId))
+		args = append(args, *filter.UpdatedFrom)
+	}
+
+	if filter.UpdatedBefore != nil {
+		fieldId++
+		conditions = append(conditions, fmt.Sprintf("updated_at<$%d", fieldId))
+		args = append(args, *filter.UpdatedBefore)
+	}
+
+	if fieldId == 0 {
+		return "", args
+	}
+
+	return " where " + strings.Join(conditions, " and "), args
+}
+
+func scanBook(row rowScanner) (domain.Book, error) {
+	var book domain.Book
+	err := row.Scan(bookDest(&book)...)
+
+	return book, err
+}
+
+// bookDest returns scan destinations matching bookColumns.
+func bookDest(book *domain.Book) []interface{} {
+	return []interface{}{
+		&book.ID,
+		&book.Title,
+		&book.Author,
+		&book.ISBN,
+		&book.Description,
+		&book.Language,
+		&book.PageCount,
+		pq.Array(&book.Subjects),
+		&book.PublishDate,
+		&book.SeriesID,
+		&book.Volume,
+		&book.Rating,
+		&book.RatingCount,
+		pq.Array(&book.RatingHistogram),
+		&book.Cover,
+		&book.UpdatedAt,
+		&book.Version,
+	}
+}
+
+func scanBooks(rows *sql.Rows) ([]domain.Book, error) {
+	defer rows.Close()
+
+	books := make([]domain.Book, 0)
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		books = append(books, book)
+	}
+
+	return books, rows.Err()
 }
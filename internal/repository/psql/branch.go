@@ -0,0 +1,132 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type BranchRepository struct {
+	db *sql.DB
+}
+
+func NewBranchRepository(db *sql.DB) *BranchRepository {
+	return &BranchRepository{db: db}
+}
+
+func (r BranchRepository) Create(ctx context.Context, branch domain.Branch) (int64, error) {
+	hours, err := json.Marshal(branch.OpeningHours)
+	if err != nil {
+		return 0, err
+	}
+
+	result := r.db.QueryRowContext(ctx,
+		"insert into branches (name, address, opening_hours, created_at) values ($1, $2, $3, $4) returning id",
+		branch.Name,
+		branch.Address,
+		hours,
+		branch.CreatedAt,
+	)
+
+	var id int64
+	err = result.Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r BranchRepository) GetAll(ctx context.Context) ([]domain.Branch, error) {
+	rows, err := r.db.QueryContext(ctx, "select id, name, address, opening_hours, created_at from branches order by name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	branches := make([]domain.Branch, 0)
+	for rows.Next() {
+		branch, err := scanBranch(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		branches = append(branches, branch)
+	}
+
+	return branches, rows.Err()
+}
+
+func (r BranchRepository) GetById(ctx context.Context, id int64) (domain.Branch, error) {
+	row := r.db.QueryRowContext(ctx, "select id, name, address, opening_hours, created_at from branches where id=$1", id)
+
+	branch, err := scanBranch(row)
+	if err == sql.ErrNoRows {
+		return branch, domain.ErrorBranchNotFound
+	}
+
+	return branch, err
+}
+
+func (r BranchRepository) Update(ctx context.Context, id int64, input domain.UpdateBranchInput) error {
+	fields := make([]string, 0)
+	fieldId := 0
+	args := make([]interface{}, 0)
+
+	if input.Name != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("name=$%d", fieldId))
+		args = append(args, input.Name)
+	}
+
+	if input.Address != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("address=$%d", fieldId))
+		args = append(args, input.Address)
+	}
+
+	if input.OpeningHours != nil {
+		hours, err := json.Marshal(input.OpeningHours)
+		if err != nil {
+			return err
+		}
+
+		fieldId++
+		fields = append(fields, fmt.Sprintf("opening_hours=$%d", fieldId))
+		args = append(args, hours)
+	}
+
+	if fieldId == 0 {
+		return domain.ErrorEmptyUpdateBranchInput
+	}
+
+	query := fmt.Sprintf("update branches set %s where id=%d", strings.Join(fields, ", "), id)
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+
+	return err
+}
+
+func (r BranchRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "delete from branches where id=$1", id)
+	return err
+}
+
+func scanBranch(row rowScanner) (domain.Branch, error) {
+	var branch domain.Branch
+	var hours []byte
+
+	err := row.Scan(&branch.ID, &branch.Name, &branch.Address, &hours, &branch.CreatedAt)
+	if err != nil {
+		return branch, err
+	}
+
+	if len(hours) > 0 {
+		err = json.Unmarshal(hours, &branch.OpeningHours)
+	}
+
+	return branch, err
+}
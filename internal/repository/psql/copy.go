@@ -0,0 +1,210 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// copyAvailable is true for copies that are not on loan, not waiting for a
+// hold pickup and not being transferred between branches.
+const copyAvailable = "(not exists (select 1 from loans where loans.copy_id=copies.id and loans.returned_at is null) " +
+	"and not exists (select 1 from holds where holds.copy_id=copies.id and holds.status='ready') " +
+	"and not exists (select 1 from transfers where transfers.copy_id=copies.id and transfers.status in ('requested', 'in_transit')))"
+
+const copyColumns = "copies.id, copies.book_id, copies.branch_id, copies.barcode, copies.location, copies.condition, " +
+	copyAvailable + ", copies.created_at"
+
+type CopyRepository struct {
+	db *sql.DB
+}
+
+func NewCopyRepository(db *sql.DB) *CopyRepository {
+	return &CopyRepository{db: db}
+}
+
+// Create adds a copy of a book. If somebody is waiting for the book the new
+// copy is reserved for the oldest hold that can be picked up at its branch.
+func (r CopyRepository) Create(ctx context.Context, c domain.Copy, pickupDeadline time.Time) (int64, error) {
+	var id int64
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockBook(ctx, tx, c.BookID); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowContext(ctx,
+			"insert into copies (book_id, branch_id, barcode, location, condition, created_at) values ($1, $2, $3, $4, $5, $6) returning id",
+			c.BookID,
+			c.BranchID,
+			c.Barcode,
+			c.Location,
+			c.Condition,
+			c.CreatedAt,
+		).Scan(&id)
+		if isUniqueViolation(err) {
+			return domain.ErrorDuplicateBarcode
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = promoteNextHold(ctx, tx, c.BookID, id, c.CreatedAt, pickupDeadline)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r CopyRepository) GetById(ctx context.Context, id int64) (domain.Copy, error) {
+	row := r.db.QueryRowContext(ctx, "select "+copyColumns+" from copies where id=$1", id)
+
+	c, err := scanCopy(row)
+	if err == sql.ErrNoRows {
+		return c, domain.ErrorCopyNotFound
+	}
+
+	return c, err
+}
+
+func (r CopyRepository) GetByBook(ctx context.Context, bookId int64) ([]domain.Copy, error) {
+	rows, err := r.db.QueryContext(ctx, "select "+copyColumns+" from copies where book_id=$1 order by id", bookId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	copies := make([]domain.Copy, 0)
+	for rows.Next() {
+		c, err := scanCopy(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		copies = append(copies, c)
+	}
+
+	return copies, rows.Err()
+}
+
+func (r CopyRepository) Update(ctx context.Context, id int64, input domain.UpdateCopyInput) error {
+	fields := make([]string, 0)
+	fieldId := 0
+	args := make([]interface{}, 0)
+
+	if input.BranchID != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("branch_id=$%d", fieldId))
+		args = append(args, input.BranchID)
+	}
+
+	if input.Barcode != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("barcode=$%d", fieldId))
+		args = append(args, input.Barcode)
+	}
+
+	if input.Location != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("location=$%d", fieldId))
+		args = append(args, input.Location)
+	}
+
+	if input.Condition != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("condition=$%d", fieldId))
+		args = append(args, input.Condition)
+	}
+
+	if fieldId == 0 {
+		return domain.ErrorEmptyUpdateCopyInput
+	}
+
+	query := fmt.Sprintf("update copies set %s where id=%d", strings.Join(fields, ", "), id)
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return domain.ErrorDuplicateBarcode
+	}
+
+	return err
+}
+
+// GetAvailability counts copies of the book per branch. Copies without a
+// branch are reported under a nil branch id.
+func (r CopyRepository) GetAvailability(ctx context.Context, bookId int64) ([]domain.BranchAvailability, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"select copies.branch_id, coalesce(branches.name, ''), count(*), count(*) filter (where "+copyAvailable+") "+
+			"from copies left join branches on branches.id=copies.branch_id where copies.book_id=$1 "+
+			"group by copies.branch_id, branches.name order by branches.name nulls last",
+		bookId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	availability := make([]domain.BranchAvailability, 0)
+	for rows.Next() {
+		var item domain.BranchAvailability
+		if err := rows.Scan(&item.BranchID, &item.BranchName, &item.Total, &item.Available); err != nil {
+			return nil, err
+		}
+
+		availability = append(availability, item)
+	}
+
+	return availability, rows.Err()
+}
+
+// Delete removes a copy that is available and has never been lent; the
+// copy is locked so that a concurrent checkout cannot slip in between.
+func (r CopyRepository) Delete(ctx context.Context, id int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var lent, available bool
+		err := tx.QueryRowContext(ctx,
+			"select exists (select 1 from loans where loans.copy_id=copies.id), "+copyAvailable+" from copies where id=$1 for update",
+			id,
+		).Scan(&lent, &available)
+		if err == sql.ErrNoRows {
+			return domain.ErrorCopyNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if lent {
+			return domain.ErrorCopyHasLoans
+		}
+		if !available {
+			return domain.ErrorCopyNotAvailable
+		}
+
+		_, err = tx.ExecContext(ctx, "delete from copies where id=$1", id)
+
+		return err
+	})
+}
+
+func scanCopy(row rowScanner) (domain.Copy, error) {
+	var c domain.Copy
+	err := row.Scan(
+		&c.ID,
+		&c.BookID,
+		&c.BranchID,
+		&c.Barcode,
+		&c.Location,
+		&c.Condition,
+		&c.Available,
+		&c.CreatedAt,
+	)
+
+	return c, err
+}
@@ -0,0 +1,63 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+const exportFetchSize = 500
+
+// Export walks the books matching the filter through a server-side cursor,
+// so only one batch of rows is held in memory at a time.
+func (r BookRepository) Export(ctx context.Context, filter domain.BookFilter, fn func(domain.Book) error) error {
+	where, args := bookFilterClause(filter)
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"declare export_books no scroll cursor for select "+bookColumns+" from books"+where+" order by id", args...)
+	if err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf("fetch forward %d from export_books", exportFetchSize)
+	for {
+		rows, err := tx.QueryContext(ctx, fetch)
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		for rows.Next() {
+			n++
+
+			book, err := scanBook(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+
+			if err := fn(book); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if n < exportFetchSize {
+			break
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,146 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/lib/pq"
+)
+
+const bookFileColumns = "id, book_id, format, name, content_type, size, sha256, storage_key, metadata, created_at"
+
+type BookFileRepository struct {
+	db *sql.DB
+}
+
+func NewBookFileRepository(db *sql.DB) *BookFileRepository {
+	return &BookFileRepository{db: db}
+}
+
+func (r BookFileRepository) Create(ctx context.Context, file domain.BookFile) (int64, error) {
+	metadata, err := json.Marshal(file.Metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	result := r.db.QueryRowContext(ctx,
+		"insert into book_files (book_id, format, name, content_type, size, sha256, storage_key, metadata, created_at) "+
+			"values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id",
+		file.BookID,
+		file.Format,
+		file.Name,
+		file.ContentType,
+		file.Size,
+		file.SHA256,
+		file.Key,
+		metadata,
+		file.CreatedAt,
+	)
+
+	var id int64
+	err = result.Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r BookFileRepository) GetById(ctx context.Context, bookId, id int64) (domain.BookFile, error) {
+	row := r.db.QueryRowContext(ctx, "select "+bookFileColumns+" from book_files where id=$1 and book_id=$2", id, bookId)
+
+	file, err := scanBookFile(row)
+	if err == sql.ErrNoRows {
+		return file, domain.ErrorBookFileNotFound
+	}
+
+	return file, err
+}
+
+func (r BookFileRepository) GetByBook(ctx context.Context, bookId int64) ([]domain.BookFile, error) {
+	rows, err := r.db.QueryContext(ctx, "select "+bookFileColumns+" from book_files where book_id=$1 order by id", bookId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := make([]domain.BookFile, 0)
+	for rows.Next() {
+		file, err := scanBookFile(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	return files, rows.Err()
+}
+
+// GetByBooks returns the files of the given books keyed by book id.
+func (r BookFileRepository) GetByBooks(ctx context.Context, bookIds []int64) (map[int64][]domain.BookFile, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"select "+bookFileColumns+" from book_files where book_id=any($1) order by book_id, id", pq.Array(bookIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := make(map[int64][]domain.BookFile)
+	for rows.Next() {
+		file, err := scanBookFile(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		files[file.BookID] = append(files[file.BookID], file)
+	}
+
+	return files, rows.Err()
+}
+
+func (r BookFileRepository) Delete(ctx context.Context, bookId, id int64) error {
+	result, err := r.db.ExecContext(ctx, "delete from book_files where id=$1 and book_id=$2", id, bookId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrorBookFileNotFound
+	}
+
+	return nil
+}
+
+func scanBookFile(row rowScanner) (domain.BookFile, error) {
+	var file domain.BookFile
+	var metadata []byte
+
+	err := row.Scan(
+		&file.ID,
+		&file.BookID,
+		&file.Format,
+		&file.Name,
+		&file.ContentType,
+		&file.Size,
+		&file.SHA256,
+		&file.Key,
+		&metadata,
+		&file.CreatedAt,
+	)
+	if err != nil {
+		return file, err
+	}
+
+	if len(metadata) > 0 {
+		err = json.Unmarshal(metadata, &file.Metadata)
+	}
+
+	return file, err
+}
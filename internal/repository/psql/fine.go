@@ -0,0 +1,147 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+const fineColumns = "id, loan_id, user_id, amount, status, coalesce(note, ''), created_at, updated_at, resolved_at, resolved_by"
+
+// accrueFines charges open fines for loans overdue as of $1: $2 for every
+// full day past the due date, capped at $3. Returned loans are charged up to
+// their return, once their fine has not been brought up to it yet. A loan id
+// in $4 limits it to that loan.
+const accrueFines = "insert into fines (loan_id, user_id, amount, status, created_at, updated_at) " +
+	"select id, user_id, least(days * $2, $3), 'open', $1, $1 from (" +
+	"select loans.id, loans.user_id, " +
+	"floor(extract(epoch from (coalesce(loans.returned_at, $1) - loans.due_at)) / 86400)::bigint as days " +
+	"from loans where loans.due_at < $1 and ($4::bigint is null or loans.id=$4) and (loans.returned_at is null " +
+	"or (loans.returned_at > loans.due_at and not exists (select 1 from fines where fines.loan_id=loans.id " +
+	"and (fines.status<>'open' or fines.updated_at >= loans.returned_at))))" +
+	") overdue where days > 0 " +
+	"on conflict (loan_id) do update set amount=excluded.amount, updated_at=excluded.updated_at " +
+	"where fines.status='open' and fines.amount <> excluded.amount"
+
+type FineRepository struct {
+	db *sql.DB
+}
+
+func NewFineRepository(db *sql.DB) *FineRepository {
+	return &FineRepository{db: db}
+}
+
+// Accrue recalculates open fines of loans that are or were returned overdue:
+// dailyAmount for every full day past the due date, capped at maxAmount.
+// It returns the number of fines created or changed.
+func (r FineRepository) Accrue(ctx context.Context, now time.Time, dailyAmount, maxAmount int64) (int64, error) {
+	result, err := r.db.ExecContext(ctx, accrueFines, now, dailyAmount, maxAmount, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r FineRepository) GetById(ctx context.Context, id int64) (domain.Fine, error) {
+	row := r.db.QueryRowContext(ctx, "select "+fineColumns+" from fines where id=$1", id)
+
+	fine, err := scanFine(row)
+	if err == sql.ErrNoRows {
+		return fine, domain.ErrorFineNotFound
+	}
+
+	return fine, err
+}
+
+func (r FineRepository) GetByUser(ctx context.Context, userId int64, status string) ([]domain.Fine, error) {
+	query := "select " + fineColumns + " from fines where user_id=$1"
+	args := []interface{}{userId}
+	if status != "" {
+		query += " and status=$2"
+		args = append(args, status)
+	}
+	query += " order by created_at desc, id desc"
+
+	return r.query(ctx, query, args...)
+}
+
+func (r FineRepository) GetAll(ctx context.Context, status string) ([]domain.Fine, error) {
+	query := "select " + fineColumns + " from fines"
+	args := []interface{}{}
+	if status != "" {
+		query += " where status=$1"
+		args = append(args, status)
+	}
+	query += " order by created_at desc, id desc"
+
+	return r.query(ctx, query, args...)
+}
+
+// Resolve marks an open fine as paid or waived.
+func (r FineRepository) Resolve(ctx context.Context, id int64, status string, by int64, at time.Time, note string) error {
+	result, err := r.db.ExecContext(ctx,
+		"update fines set status=$1, resolved_by=$2, resolved_at=$3, updated_at=$3, note=$4 where id=$5 and status='open'",
+		status,
+		by,
+		at,
+		note,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		if _, err := r.GetById(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrorFineNotOpen
+	}
+
+	return nil
+}
+
+func (r FineRepository) query(ctx context.Context, query string, args ...interface{}) ([]domain.Fine, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fines := make([]domain.Fine, 0)
+	for rows.Next() {
+		fine, err := scanFine(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		fines = append(fines, fine)
+	}
+
+	return fines, rows.Err()
+}
+
+func scanFine(row rowScanner) (domain.Fine, error) {
+	var fine domain.Fine
+	err := row.Scan(
+		&fine.ID,
+		&fine.LoanID,
+		&fine.UserID,
+		&fine.Amount,
+		&fine.Status,
+		&fine.Note,
+		&fine.CreatedAt,
+		&fine.UpdatedAt,
+		&fine.ResolvedAt,
+		&fine.ResolvedBy,
+	)
+
+	return fine, err
+}
@@ -0,0 +1,282 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+const holdColumns = "holds.id, holds.book_id, holds.user_id, holds.pickup_branch_id, holds.status, " +
+	"case when holds.status='waiting' then (select count(*) from holds queue where queue.book_id=holds.book_id " +
+	"and queue.status='waiting' and (queue.created_at, queue.id) <= (holds.created_at, holds.id)) else 0 end, " +
+	"holds.copy_id, holds.created_at, holds.ready_at, holds.pickup_deadline, holds.closed_at"
+
+// readyHoldColumns matches holdColumns for holds that just left the queue.
+const readyHoldColumns = "id, book_id, user_id, pickup_branch_id, status, 0, copy_id, created_at, ready_at, pickup_deadline, closed_at"
+
+type HoldRepository struct {
+	db *sql.DB
+}
+
+func NewHoldRepository(db *sql.DB) *HoldRepository {
+	return &HoldRepository{db: db}
+}
+
+// Create appends a hold to the book queue. Holds are only accepted while no
+// copy of the book is available at the pickup branch, or anywhere if the
+// hold has no pickup branch.
+func (r HoldRepository) Create(ctx context.Context, hold domain.Hold) (int64, error) {
+	var id int64
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockBook(ctx, tx, hold.BookID); err != nil {
+			return err
+		}
+
+		var exists bool
+		err := tx.QueryRowContext(ctx,
+			"select exists (select 1 from holds where book_id=$1 and user_id=$2 and status in ('waiting', 'ready'))",
+			hold.BookID,
+			hold.UserID,
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrorHoldAlreadyExists
+		}
+
+		if hold.PickupBranchID != nil {
+			err = tx.QueryRowContext(ctx,
+				"select exists (select 1 from branches where id=$1)", *hold.PickupBranchID).Scan(&exists)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return domain.ErrorBranchNotFound
+			}
+		}
+
+		var available int
+		err = tx.QueryRowContext(ctx,
+			"select count(*) from copies where book_id=$1 and ($2::bigint is null or branch_id=$2) and "+copyAvailable,
+			hold.BookID,
+			hold.PickupBranchID,
+		).Scan(&available)
+		if err != nil {
+			return err
+		}
+		if available > 0 {
+			return domain.ErrorCopiesAvailable
+		}
+
+		return tx.QueryRowContext(ctx,
+			"insert into holds (book_id, user_id, pickup_branch_id, status, created_at) values ($1, $2, $3, $4, $5) returning id",
+			hold.BookID,
+			hold.UserID,
+			hold.PickupBranchID,
+			domain.HoldWaiting,
+			hold.CreatedAt,
+		).Scan(&id)
+	})
+
+	return id, err
+}
+
+func (r HoldRepository) GetById(ctx context.Context, id int64) (domain.Hold, error) {
+	row := r.db.QueryRowContext(ctx, "select "+holdColumns+" from holds where holds.id=$1", id)
+
+	hold, err := scanHold(row)
+	if err == sql.ErrNoRows {
+		return hold, domain.ErrorHoldNotFound
+	}
+
+	return hold, err
+}
+
+func (r HoldRepository) GetByUser(ctx context.Context, userId int64, onlyActive bool) ([]domain.Hold, error) {
+	query := "select " + holdColumns + " from holds where holds.user_id=$1"
+	if onlyActive {
+		query += " and holds.status in ('waiting', 'ready')"
+	}
+	query += " order by holds.created_at desc, holds.id desc"
+
+	return r.query(ctx, query, userId)
+}
+
+// GetQueue returns the active holds of the book in pickup order.
+func (r HoldRepository) GetQueue(ctx context.Context, bookId int64) ([]domain.Hold, error) {
+	return r.query(ctx,
+		"select "+holdColumns+" from holds where holds.book_id=$1 and holds.status in ('waiting', 'ready') "+
+			"order by holds.status='waiting', holds.created_at, holds.id",
+		bookId,
+	)
+}
+
+// Cancel closes an active hold. A copy that was waiting for pickup is passed
+// on to the next hold in the queue.
+func (r HoldRepository) Cancel(ctx context.Context, id int64, now, pickupDeadline time.Time) error {
+	return r.close(ctx, id, domain.HoldCancelled, now, pickupDeadline, false)
+}
+
+// Expire closes ready holds whose pickup deadline has passed and passes their
+// copies on. It returns the number of expired holds.
+func (r HoldRepository) Expire(ctx context.Context, now, pickupDeadline time.Time) (int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"select id from holds where status='ready' and pickup_deadline < $1 order by pickup_deadline", now)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, id := range ids {
+		err := r.close(ctx, id, domain.HoldExpired, now, pickupDeadline, true)
+		if err == domain.ErrorHoldClosed {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+func (r HoldRepository) close(ctx context.Context, id int64, status string, now, pickupDeadline time.Time, onlyOverdue bool) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var bookId int64
+		err := tx.QueryRowContext(ctx, "select book_id from holds where id=$1", id).Scan(&bookId)
+		if err == sql.ErrNoRows {
+			return domain.ErrorHoldNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := lockBook(ctx, tx, bookId); err != nil {
+			return err
+		}
+
+		var current string
+		var copyId *int64
+		var deadline *time.Time
+		err = tx.QueryRowContext(ctx,
+			"select status, copy_id, pickup_deadline from holds where id=$1 for update", id,
+		).Scan(&current, &copyId, &deadline)
+		if err != nil {
+			return err
+		}
+
+		if current != domain.HoldWaiting && current != domain.HoldReady {
+			return domain.ErrorHoldClosed
+		}
+		if onlyOverdue && (current != domain.HoldReady || deadline == nil || !deadline.Before(now)) {
+			return domain.ErrorHoldClosed
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"update holds set status=$1, closed_at=$2 where id=$3", status, now, id)
+		if err != nil {
+			return err
+		}
+
+		if current == domain.HoldReady && copyId != nil {
+			_, err = promoteNextHold(ctx, tx, bookId, *copyId, now, pickupDeadline)
+		}
+
+		return err
+	})
+}
+
+func (r HoldRepository) query(ctx context.Context, query string, args ...interface{}) ([]domain.Hold, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := make([]domain.Hold, 0)
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// promoteNextHold assigns the copy to the oldest waiting hold of the book that
+// can be picked up at the copy's branch and starts its pickup window. It
+// returns nil when nobody is waiting there.
+func promoteNextHold(ctx context.Context, tx *sql.Tx, bookId, copyId int64, now, pickupDeadline time.Time) (*domain.Hold, error) {
+	row := tx.QueryRowContext(ctx,
+		"update holds set status='ready', copy_id=$1, ready_at=$2, pickup_deadline=$3 "+
+			"where id=(select id from holds where book_id=$4 and status='waiting' "+
+			"and (pickup_branch_id is null or pickup_branch_id=(select branch_id from copies where id=$1)) "+
+			"order by created_at, id limit 1) "+
+			"returning "+readyHoldColumns,
+		copyId,
+		now,
+		pickupDeadline,
+		bookId,
+	)
+
+	hold, err := scanHold(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// lockBook serializes changes to the hold queue of a book.
+func lockBook(ctx context.Context, tx *sql.Tx, bookId int64) error {
+	var id int64
+	err := tx.QueryRowContext(ctx, "select id from books where id=$1 for update", bookId).Scan(&id)
+	if err == sql.ErrNoRows {
+		return domain.ErrorBookNotFound
+	}
+
+	return err
+}
+
+func scanHold(row rowScanner) (domain.Hold, error) {
+	var hold domain.Hold
+	err := row.Scan(
+		&hold.ID,
+		&hold.BookID,
+		&hold.UserID,
+		&hold.PickupBranchID,
+		&hold.Status,
+		&hold.Position,
+		&hold.CopyID,
+		&hold.CreatedAt,
+		&hold.ReadyAt,
+		&hold.PickupDeadline,
+		&hold.ClosedAt,
+	)
+
+	return hold, err
+}
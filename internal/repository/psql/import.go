@@ -0,0 +1,100 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"time"
+)
+
+// Import upserts the books by ISBN inside one transaction. The rows are
+// streamed into a temporary table with COPY and inserted from it in their
+// input order; a row whose ISBN is taken updates that book instead, so the
+// unique ISBN index keeps concurrent imports from creating duplicates.
+// Optional fields left empty in a row keep the values the book already has.
+// An ISBN repeated within one call fails the whole call.
+func (r BookRepository) Import(ctx context.Context, books []domain.Book) (created, updated int, err error) {
+	err = withTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"create temp table import_books ("+
+				"row_no int, title text, author text, isbn text, publish_date timestamptz, series_id bigint, volume float8"+
+				") on commit drop")
+		if err != nil {
+			return err
+		}
+
+		stmt, err := tx.PrepareContext(ctx,
+			pq.CopyIn("import_books", "row_no", "title", "author", "isbn", "publish_date", "series_id", "volume"))
+		if err != nil {
+			return err
+		}
+
+		for i, book := range books {
+			var isbn interface{}
+			if book.ISBN != "" {
+				isbn = book.ISBN
+			}
+
+			_, err = stmt.ExecContext(ctx, i, book.Title, book.Author, isbn, book.PublishDate, book.SeriesID, book.Volume)
+			if err != nil {
+				stmt.Close()
+				return err
+			}
+		}
+
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			stmt.Close()
+			return err
+		}
+		if err := stmt.Close(); err != nil {
+			return err
+		}
+
+		// xmax is zero on rows the statement inserted rather than updated.
+		err = tx.QueryRowContext(ctx,
+			"with upserted as ("+
+				"insert into books (title, author, isbn, publish_date, series_id, volume, updated_at, version) "+
+				"select i.title, i.author, i.isbn, i.publish_date, i.series_id, i.volume, now(), 1 from import_books i "+
+				"order by i.row_no "+
+				"on conflict (isbn) where isbn is not null do update set title=excluded.title, author=excluded.author, "+
+				"publish_date=coalesce(nullif(excluded.publish_date, $1), books.publish_date), "+
+				"series_id=coalesce(excluded.series_id, books.series_id), volume=coalesce(excluded.volume, books.volume), "+
+				"updated_at=now(), version=books.version+1 "+
+				"returning xmax = 0 as inserted"+
+				") select count(*) filter (where inserted), count(*) filter (where not inserted) from upserted",
+			time.Time{},
+		).Scan(&created, &updated)
+
+		return err
+	})
+	if isForeignKeyViolation(err) {
+		return 0, 0, domain.ErrorSeriesNotFound
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return created, updated, nil
+}
+
+// GetExistingISBNs reports which of the given ISBNs already belong to a book.
+func (r BookRepository) GetExistingISBNs(ctx context.Context, isbns []string) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx, "select isbn from books where isbn = any($1)", pq.Array(isbns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var isbn string
+		if err := rows.Scan(&isbn); err != nil {
+			return nil, err
+		}
+
+		existing[isbn] = true
+	}
+
+	return existing, rows.Err()
+}
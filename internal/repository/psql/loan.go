@@ -0,0 +1,283 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+const loanColumns = "loans.id, loans.copy_id, copies.book_id, loans.user_id, loans.checked_out_at, loans.due_at, loans.returned_at"
+
+type LoanRepository struct {
+	db *sql.DB
+}
+
+func NewLoanRepository(db *sql.DB) *LoanRepository {
+	return &LoanRepository{db: db}
+}
+
+// Checkout lends the copy to the user. The user, copy and book rows are locked
+// for the duration of the transaction so concurrent checkouts of the same copy
+// or by the same user are serialized and the loan limit can not be exceeded.
+// A copy waiting for pickup can only be checked out by the holder. The
+// checkout fulfills the user's hold on the book; if that hold was waiting for
+// pickup of another copy, the other copy goes to the next hold in the queue.
+func (r LoanRepository) Checkout(ctx context.Context, loan domain.Loan, maxLoans int, pickupDeadline time.Time) (domain.Loan, error) {
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "select id from users where id=$1 for update", loan.UserID); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowContext(ctx,
+			"select book_id from copies where id=$1 for update", loan.CopyID).Scan(&loan.BookID)
+		if err == sql.ErrNoRows {
+			return domain.ErrorCopyNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := lockBook(ctx, tx, loan.BookID); err != nil {
+			return err
+		}
+
+		var holder int64
+		err = tx.QueryRowContext(ctx,
+			"select user_id from holds where copy_id=$1 and status='ready'", loan.CopyID).Scan(&holder)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil && holder != loan.UserID {
+			return domain.ErrorCopyReserved
+		}
+
+		var inTransit bool
+		err = tx.QueryRowContext(ctx,
+			"select exists (select 1 from transfers where copy_id=$1 and status in ('requested', 'in_transit'))",
+			loan.CopyID,
+		).Scan(&inTransit)
+		if err != nil {
+			return err
+		}
+		if inTransit {
+			return domain.ErrorCopyInTransit
+		}
+
+		var onLoan bool
+		err = tx.QueryRowContext(ctx,
+			"select exists (select 1 from loans where copy_id=$1 and returned_at is null)", loan.CopyID).Scan(&onLoan)
+		if err != nil {
+			return err
+		}
+		if onLoan {
+			return domain.ErrorCopyNotAvailable
+		}
+
+		if maxLoans > 0 {
+			var active int
+			err = tx.QueryRowContext(ctx,
+				"select count(*) from loans where user_id=$1 and returned_at is null", loan.UserID).Scan(&active)
+			if err != nil {
+				return err
+			}
+			if active >= maxLoans {
+				return domain.ErrorLoanLimitReached
+			}
+		}
+
+		err = tx.QueryRowContext(ctx,
+			"insert into loans (copy_id, user_id, checked_out_at, due_at) values ($1, $2, $3, $4) returning id",
+			loan.CopyID,
+			loan.UserID,
+			loan.CheckedOutAt,
+			loan.DueAt,
+		).Scan(&loan.ID)
+		if isUniqueViolation(err) {
+			return domain.ErrorCopyNotAvailable
+		}
+		if err != nil {
+			return err
+		}
+
+		var heldCopyId *int64
+		err = tx.QueryRowContext(ctx,
+			"update holds set status='fulfilled', closed_at=$1 where book_id=$2 and user_id=$3 and status in ('waiting', 'ready') "+
+				"returning copy_id",
+			loan.CheckedOutAt,
+			loan.BookID,
+			loan.UserID,
+		).Scan(&heldCopyId)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if heldCopyId != nil && *heldCopyId != loan.CopyID {
+			_, err = promoteNextHold(ctx, tx, loan.BookID, *heldCopyId, loan.CheckedOutAt, pickupDeadline)
+		}
+
+		return err
+	})
+
+	return loan, err
+}
+
+// Return closes the active loan of the copy and, in the same transaction,
+// settles the fine of an overdue loan at the return time and reserves the
+// copy for the oldest waiting hold on the book.
+func (r LoanRepository) Return(ctx context.Context, copyId int64, returnedAt, pickupDeadline time.Time, rate domain.FineRate) (domain.ReturnResult, error) {
+	var result domain.ReturnResult
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var bookId int64
+		err := tx.QueryRowContext(ctx, "select book_id from copies where id=$1 for update", copyId).Scan(&bookId)
+		if err == sql.ErrNoRows {
+			return domain.ErrorCopyNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := lockBook(ctx, tx, bookId); err != nil {
+			return err
+		}
+
+		result.Loan, err = returnLoan(ctx, tx, copyId, returnedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, accrueFines, returnedAt, rate.DailyAmount, rate.MaxAmount, result.Loan.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = promoteNextHold(ctx, tx, bookId, copyId, returnedAt, pickupDeadline)
+
+		return err
+	})
+
+	return result, err
+}
+
+func (r LoanRepository) GetByUser(ctx context.Context, userId int64, onlyActive bool) ([]domain.Loan, error) {
+	query := "select " + loanColumns + " from loans join copies on copies.id=loans.copy_id where loans.user_id=$1"
+	if onlyActive {
+		query += " and loans.returned_at is null"
+	}
+	query += " order by loans.checked_out_at desc, loans.id desc"
+
+	return r.query(ctx, query, userId)
+}
+
+func (r LoanRepository) GetByCopy(ctx context.Context, copyId int64) ([]domain.Loan, error) {
+	return r.query(ctx,
+		"select "+loanColumns+" from loans join copies on copies.id=loans.copy_id where loans.copy_id=$1 "+
+			"order by loans.checked_out_at desc, loans.id desc",
+		copyId,
+	)
+}
+
+// GetOverdueForReminder returns active overdue loans whose borrower was not
+// reminded since remindedBefore.
+func (r LoanRepository) GetOverdueForReminder(ctx context.Context, now, remindedBefore time.Time) ([]domain.OverdueLoan, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"select "+loanColumns+", users.name, users.email, books.title from loans "+
+			"join copies on copies.id=loans.copy_id "+
+			"join books on books.id=copies.book_id "+
+			"join users on users.id=loans.user_id "+
+			"where loans.returned_at is null and loans.due_at < $1 "+
+			"and (loans.reminded_at is null or loans.reminded_at < $2) "+
+			"order by loans.due_at",
+		now,
+		remindedBefore,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loans := make([]domain.OverdueLoan, 0)
+	for rows.Next() {
+		var loan domain.OverdueLoan
+
+		err = rows.Scan(
+			&loan.ID,
+			&loan.CopyID,
+			&loan.BookID,
+			&loan.UserID,
+			&loan.CheckedOutAt,
+			&loan.DueAt,
+			&loan.ReturnedAt,
+			&loan.UserName,
+			&loan.UserEmail,
+			&loan.BookTitle,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		loans = append(loans, loan)
+	}
+
+	return loans, rows.Err()
+}
+
+func (r LoanRepository) MarkReminded(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, "update loans set reminded_at=$1 where id=$2", at, id)
+	return err
+}
+
+func (r LoanRepository) query(ctx context.Context, query string, args ...interface{}) ([]domain.Loan, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loans := make([]domain.Loan, 0)
+	for rows.Next() {
+		loan, err := scanLoan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		loans = append(loans, loan)
+	}
+
+	return loans, rows.Err()
+}
+
+func returnLoan(ctx context.Context, tx *sql.Tx, copyId int64, returnedAt time.Time) (domain.Loan, error) {
+	row := tx.QueryRowContext(ctx,
+		"update loans set returned_at=$1 from copies where copies.id=loans.copy_id "+
+			"and loans.copy_id=$2 and loans.returned_at is null returning "+loanColumns,
+		returnedAt,
+		copyId,
+	)
+
+	loan, err := scanLoan(row)
+	if err == sql.ErrNoRows {
+		return loan, domain.ErrorCopyNotCheckedOut
+	}
+
+	return loan, err
+}
+
+func scanLoan(row rowScanner) (domain.Loan, error) {
+	var loan domain.Loan
+	err := row.Scan(
+		&loan.ID,
+		&loan.CopyID,
+		&loan.BookID,
+		&loan.UserID,
+		&loan.CheckedOutAt,
+		&loan.DueAt,
+		&loan.ReturnedAt,
+	)
+
+	return loan, err
+}
@@ -0,0 +1,112 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+)
+
+// mergeStatements re-point everything that refers to the source books ($2)
+// to the target ($1). Rows a user, shelf or locale would end up having
+// twice are dropped first: the target's review, shelf entry and translation
+// win, otherwise the latest review and the entry and translation of the
+// lowest book id; of duplicate active holds a ready one or else the oldest
+// is kept.
+var mergeStatements = []string{
+	"delete from reviews r where r.book_id=any($2) and exists (" +
+		"select 1 from reviews o where o.user_id=r.user_id and o.book_id<>r.book_id and " +
+		"(o.book_id=$1 or (o.book_id=any($2) and (o.updated_at, o.book_id) > (r.updated_at, r.book_id))))",
+	"update reviews set book_id=$1 where book_id=any($2)",
+
+	"delete from shelf_books s where s.book_id=any($2) and exists (" +
+		"select 1 from shelf_books o where o.shelf_id=s.shelf_id and o.book_id<>s.book_id and " +
+		"(o.book_id=$1 or (o.book_id=any($2) and o.book_id<s.book_id)))",
+	"update shelf_books set book_id=$1 where book_id=any($2)",
+
+	"update holds h set status='cancelled', closed_at=now() where (h.book_id=$1 or h.book_id=any($2)) " +
+		"and h.status='waiting' and exists (" +
+		"select 1 from holds o where o.user_id=h.user_id and o.id<>h.id and o.status in ('waiting', 'ready') and " +
+		"(o.book_id=$1 or o.book_id=any($2)) and (o.status='ready' or o.id<h.id))",
+	"update holds set book_id=$1 where book_id=any($2)",
+
+	"update copies set book_id=$1 where book_id=any($2)",
+	"update reading_sessions set book_id=$1 where book_id=any($2)",
+	"update book_files set book_id=$1 where book_id=any($2)",
+
+	"delete from book_translations t where t.book_id=any($2) and exists (" +
+		"select 1 from book_translations o where o.locale=t.locale and o.book_id<>t.book_id and " +
+		"(o.book_id=$1 or (o.book_id=any($2) and o.book_id<t.book_id)))",
+	"update book_translations set book_id=$1 where book_id=any($2)",
+}
+
+// recountRatings rebuilds the rating aggregates of a book from its reviews.
+const recountRatings = "update books set rating_count=(select count(*) from reviews where book_id=$1), " +
+	"rating_sum=(select coalesce(sum(rating), 0) from reviews where book_id=$1), " +
+	"rating_histogram=array(select count(reviews.rating) from generate_series(1, 5) g " +
+	"left join reviews on reviews.book_id=$1 and reviews.rating=g group by g order by g), " +
+	"version=version+1 where id=$1"
+
+// Merge folds the source books into the target in one transaction: their
+// reviews, shelf entries, holds, copies, reading sessions, files and
+// translations move to the target, the sources are deleted and the merged
+// fields and cover, unless empty, are written to the target.
+func (r BookRepository) Merge(ctx context.Context, targetId int64, sourceIds []int64, input domain.UpdateBookInput, cover string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		ids := append([]int64{targetId}, sourceIds...)
+
+		rows, err := tx.QueryContext(ctx, "select id from books where id=any($1) for update", pq.Array(ids))
+		if err != nil {
+			return err
+		}
+
+		found := 0
+		for rows.Next() {
+			found++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if found != len(ids) {
+			return domain.ErrorBookNotFound
+		}
+
+		for _, statement := range mergeStatements {
+			if _, err := tx.ExecContext(ctx, statement, targetId, pq.Array(sourceIds)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, recountRatings, targetId); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "delete from books where id=any($1)", pq.Array(sourceIds))
+		if err != nil {
+			return err
+		}
+
+		query, args, err := bookUpdateQuery(targetId, input)
+		if err != nil && !errors.Is(err, domain.ErrorEmptyUpdateBookInput) {
+			return err
+		}
+
+		if err == nil {
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return err
+			}
+		}
+
+		if cover != "" {
+			_, err := tx.ExecContext(ctx, "update books set cover=$1, updated_at=now(), version=version+1 where id=$2", cover, targetId)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
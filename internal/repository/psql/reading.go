@@ -0,0 +1,139 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+const topAuthorsLimit = 5
+
+type ReadingRepository struct {
+	db *sql.DB
+}
+
+func NewReadingRepository(db *sql.DB) *ReadingRepository {
+	return &ReadingRepository{db: db}
+}
+
+func (r ReadingRepository) Create(ctx context.Context, session domain.ReadingSession) (int64, error) {
+	result := r.db.QueryRowContext(ctx,
+		"insert into reading_sessions (user_id, book_id, pages, percent, started_at, finished_at, completed) "+
+			"values ($1, $2, $3, $4, $5, $6, $7) returning id",
+		session.UserID,
+		session.BookID,
+		session.Pages,
+		session.Percent,
+		session.StartedAt,
+		session.FinishedAt,
+		session.Completed,
+	)
+
+	var id int64
+	err := result.Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r ReadingRepository) GetByBook(ctx context.Context, userId, bookId int64) ([]domain.ReadingSession, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"select id, user_id, book_id, pages, percent, started_at, finished_at, completed from reading_sessions "+
+			"where user_id=$1 and book_id=$2 order by started_at, id",
+		userId,
+		bookId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.ReadingSession, 0)
+	for rows.Next() {
+		var s domain.ReadingSession
+
+		err = rows.Scan(&s.ID, &s.UserID, &s.BookID, &s.Pages, &s.Percent, &s.StartedAt, &s.FinishedAt, &s.Completed)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r ReadingRepository) GetProgress(ctx context.Context, userId, bookId int64) (domain.ReadingProgress, error) {
+	progress := domain.ReadingProgress{BookID: bookId}
+
+	err := r.db.QueryRowContext(ctx,
+		"select count(*), coalesce(sum(pages), 0), min(started_at), max(coalesce(finished_at, started_at)), "+
+			"coalesce(bool_or(completed), false), max(finished_at) filter (where completed), "+
+			"(select percent from reading_sessions where user_id=$1 and book_id=$2 and percent is not null "+
+			"order by coalesce(finished_at, started_at) desc, id desc limit 1) "+
+			"from reading_sessions where user_id=$1 and book_id=$2",
+		userId,
+		bookId,
+	).Scan(
+		&progress.Sessions,
+		&progress.PagesRead,
+		&progress.StartedAt,
+		&progress.LastReadAt,
+		&progress.Completed,
+		&progress.CompletedAt,
+		&progress.Percent,
+	)
+
+	return progress, err
+}
+
+func (r ReadingRepository) GetStats(ctx context.Context, userId int64, year int) (domain.ReadingStats, error) {
+	stats := domain.ReadingStats{Year: year}
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+
+	err := r.db.QueryRowContext(ctx,
+		"select "+
+			"(select count(distinct book_id) from reading_sessions "+
+			"where user_id=$1 and completed and finished_at >= $2 and finished_at < $3), "+
+			"(select coalesce(sum(pages), 0) from reading_sessions "+
+			"where user_id=$1 and coalesce(finished_at, started_at) >= $2 and coalesce(finished_at, started_at) < $3), "+
+			"(select avg(rating)::float8 from reviews where user_id=$1 and created_at >= $2 and created_at < $3)",
+		userId,
+		from,
+		to,
+	).Scan(&stats.BooksFinished, &stats.PagesRead, &stats.AverageRating)
+	if err != nil {
+		return stats, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"select books.author, count(distinct reading_sessions.book_id) from reading_sessions "+
+			"join books on books.id=reading_sessions.book_id "+
+			"where reading_sessions.user_id=$1 and completed and finished_at >= $2 and finished_at < $3 "+
+			"group by books.author order by 2 desc, books.author limit $4",
+		userId,
+		from,
+		to,
+		topAuthorsLimit,
+	)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	stats.TopAuthors = make([]domain.AuthorCount, 0)
+	for rows.Next() {
+		var author domain.AuthorCount
+		if err := rows.Scan(&author.Author, &author.Books); err != nil {
+			return stats, err
+		}
+
+		stats.TopAuthors = append(stats.TopAuthors, author)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,170 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+)
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create stores the review and adds its rating to the book aggregates in the same transaction.
+func (r ReviewRepository) Create(ctx context.Context, review domain.Review) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"insert into reviews (user_id, book_id, rating, text, created_at, updated_at) values ($1, $2, $3, $4, $5, $6) "+
+				"on conflict (user_id, book_id) do nothing",
+			review.UserID,
+			review.BookID,
+			review.Rating,
+			review.Text,
+			review.CreatedAt,
+			review.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := result.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return domain.ErrorReviewAlreadyExists
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"update books set rating_count=rating_count+1, rating_sum=rating_sum+$1, "+
+				"rating_histogram[$1]=rating_histogram[$1]+1 where id=$2",
+			review.Rating,
+			review.BookID,
+		)
+
+		return err
+	})
+}
+
+// Update replaces the rating and text of an existing review, moving its
+// rating between histogram buckets.
+func (r ReviewRepository) Update(ctx context.Context, review domain.Review) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var old int
+		err := tx.QueryRowContext(ctx,
+			"select rating from reviews where user_id=$1 and book_id=$2 for update",
+			review.UserID,
+			review.BookID,
+		).Scan(&old)
+		if err == sql.ErrNoRows {
+			return domain.ErrorReviewNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"update reviews set rating=$1, text=$2, updated_at=$3 where user_id=$4 and book_id=$5",
+			review.Rating,
+			review.Text,
+			review.UpdatedAt,
+			review.UserID,
+			review.BookID,
+		)
+		if err != nil {
+			return err
+		}
+
+		if old == review.Rating {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"update books set rating_sum=rating_sum-$1+$2, "+
+				"rating_histogram[$1]=rating_histogram[$1]-1, rating_histogram[$2]=rating_histogram[$2]+1 where id=$3",
+			old,
+			review.Rating,
+			review.BookID,
+		)
+
+		return err
+	})
+}
+
+func (r ReviewRepository) Delete(ctx context.Context, userId, bookId int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var rating int
+		err := tx.QueryRowContext(ctx,
+			"delete from reviews where user_id=$1 and book_id=$2 returning rating",
+			userId,
+			bookId,
+		).Scan(&rating)
+		if err == sql.ErrNoRows {
+			return domain.ErrorReviewNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"update books set rating_count=rating_count-1, rating_sum=rating_sum-$1, "+
+				"rating_histogram[$1]=rating_histogram[$1]-1 where id=$2",
+			rating,
+			bookId,
+		)
+
+		return err
+	})
+}
+
+func (r ReviewRepository) Get(ctx context.Context, userId, bookId int64) (domain.Review, error) {
+	row := r.db.QueryRowContext(ctx,
+		"select user_id, book_id, rating, text, created_at, updated_at from reviews where user_id=$1 and book_id=$2",
+		userId,
+		bookId,
+	)
+
+	var review domain.Review
+	err := row.Scan(&review.UserID, &review.BookID, &review.Rating, &review.Text, &review.CreatedAt, &review.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return review, domain.ErrorReviewNotFound
+	}
+
+	return review, err
+}
+
+func (r ReviewRepository) GetByBook(ctx context.Context, bookId int64, limit, offset int) ([]domain.Review, int64, error) {
+	var total int64
+	err := r.db.QueryRowContext(ctx, "select count(*) from reviews where book_id=$1", bookId).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"select user_id, book_id, rating, text, created_at, updated_at from reviews where book_id=$1 "+
+			"order by created_at desc, user_id limit $2 offset $3",
+		bookId,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reviews := make([]domain.Review, 0)
+	for rows.Next() {
+		var review domain.Review
+
+		err = rows.Scan(&review.UserID, &review.BookID, &review.Rating, &review.Text, &review.CreatedAt, &review.UpdatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews, total, rows.Err()
+}
@@ -0,0 +1,104 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type SeriesRepository struct {
+	db *sql.DB
+}
+
+func NewSeriesRepository(db *sql.DB) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+func (r SeriesRepository) Create(ctx context.Context, series domain.Series) (int64, error) {
+	if !series.Validate() {
+		return 0, domain.ErrorEmptyRequiredField
+	}
+
+	result := r.db.QueryRow(
+		"insert into series (name, description) values ($1, $2) returning id",
+		series.Name,
+		series.Description,
+	)
+
+	var id int64
+	err := result.Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r SeriesRepository) GetAll(ctx context.Context) ([]domain.Series, error) {
+	rows, err := r.db.Query("select id, name, description from series order by name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]domain.Series, 0)
+	for rows.Next() {
+		var series domain.Series
+
+		err = rows.Scan(&series.ID, &series.Name, &series.Description)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, series)
+	}
+
+	return list, rows.Err()
+}
+
+func (r SeriesRepository) GetById(ctx context.Context, id int64) (domain.Series, error) {
+	row := r.db.QueryRow("select id, name, description from series where id=$1", id)
+
+	var series domain.Series
+	err := row.Scan(&series.ID, &series.Name, &series.Description)
+	if err == sql.ErrNoRows {
+		return series, domain.ErrorSeriesNotFound
+	}
+
+	return series, err
+}
+
+func (r SeriesRepository) Update(ctx context.Context, id int64, input domain.UpdateSeriesInput) error {
+	fields := make([]string, 0)
+	fieldId := 0
+	args := make([]interface{}, 0)
+
+	if input.Name != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("name=$%d", fieldId))
+		args = append(args, input.Name)
+	}
+
+	if input.Description != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("description=$%d", fieldId))
+		args = append(args, input.Description)
+	}
+
+	if fieldId == 0 {
+		return domain.ErrorEmptyUpdateSeriesInput
+	}
+
+	query := fmt.Sprintf("update series set %s where id=%d", strings.Join(fields, ", "), id)
+
+	_, err := r.db.Exec(query, args...)
+
+	return err
+}
+
+func (r SeriesRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.Exec("delete from series where id=$1", id)
+	return err
+}
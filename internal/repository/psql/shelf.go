@@ -0,0 +1,285 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const shelfColumns = "id, user_id, name, kind, public, coalesce(share_token, ''), created_at"
+
+type ShelfRepository struct {
+	db *sql.DB
+}
+
+func NewShelfRepository(db *sql.DB) *ShelfRepository {
+	return &ShelfRepository{db: db}
+}
+
+// EnsureDefaults creates the built-in shelves the user does not have yet.
+func (r ShelfRepository) EnsureDefaults(ctx context.Context, userId int64) error {
+	for _, shelf := range domain.DefaultShelves {
+		_, err := r.db.ExecContext(ctx,
+			"insert into shelves (user_id, name, kind, public, created_at) values ($1, $2, $3, false, $4) "+
+				"on conflict (user_id, kind) where kind <> 'custom' do nothing",
+			userId,
+			shelf.Name,
+			shelf.Kind,
+			time.Now(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r ShelfRepository) Create(ctx context.Context, shelf domain.Shelf) (int64, error) {
+	result := r.db.QueryRowContext(ctx,
+		"insert into shelves (user_id, name, kind, public, created_at) values ($1, $2, $3, $4, $5) returning id",
+		shelf.UserID,
+		shelf.Name,
+		shelf.Kind,
+		shelf.Public,
+		shelf.CreatedAt,
+	)
+
+	var id int64
+	err := result.Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r ShelfRepository) GetByUser(ctx context.Context, userId int64, onlyPublic bool) ([]domain.Shelf, error) {
+	query := "select " + shelfColumns + " from shelves where user_id=$1"
+	if onlyPublic {
+		query += " and public"
+	}
+	query += " order by kind = 'custom', id"
+
+	rows, err := r.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shelves := make([]domain.Shelf, 0)
+	for rows.Next() {
+		shelf, err := scanShelf(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		shelves = append(shelves, shelf)
+	}
+
+	return shelves, rows.Err()
+}
+
+func (r ShelfRepository) GetById(ctx context.Context, id int64) (domain.Shelf, error) {
+	row := r.db.QueryRowContext(ctx, "select "+shelfColumns+" from shelves where id=$1", id)
+
+	shelf, err := scanShelf(row)
+	if err == sql.ErrNoRows {
+		return shelf, domain.ErrorShelfNotFound
+	}
+
+	return shelf, err
+}
+
+func (r ShelfRepository) GetByShareToken(ctx context.Context, token string) (domain.Shelf, error) {
+	row := r.db.QueryRowContext(ctx, "select "+shelfColumns+" from shelves where share_token=$1", token)
+
+	shelf, err := scanShelf(row)
+	if err == sql.ErrNoRows {
+		return shelf, domain.ErrorShelfNotFound
+	}
+
+	return shelf, err
+}
+
+func (r ShelfRepository) Update(ctx context.Context, id int64, input domain.UpdateShelfInput) error {
+	fields := make([]string, 0)
+	fieldId := 0
+	args := make([]interface{}, 0)
+
+	if input.Name != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("name=$%d", fieldId))
+		args = append(args, input.Name)
+	}
+
+	if input.Public != nil {
+		fieldId++
+		fields = append(fields, fmt.Sprintf("public=$%d", fieldId))
+		args = append(args, input.Public)
+	}
+
+	if fieldId == 0 {
+		return domain.ErrorEmptyUpdateShelfInput
+	}
+
+	query := fmt.Sprintf("update shelves set %s where id=%d", strings.Join(fields, ", "), id)
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+
+	return err
+}
+
+func (r ShelfRepository) SetShareToken(ctx context.Context, id int64, token string) error {
+	var value interface{}
+	if token != "" {
+		value = token
+	}
+
+	_, err := r.db.ExecContext(ctx, "update shelves set share_token=$1 where id=$2", value, id)
+	return err
+}
+
+func (r ShelfRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "delete from shelves where id=$1", id)
+	return err
+}
+
+func (r ShelfRepository) GetBooks(ctx context.Context, shelfId int64) ([]domain.ShelfBook, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"select position, added_at, "+bookColumns+" from shelf_books join books on books.id=shelf_books.book_id "+
+			"where shelf_id=$1 order by position",
+		shelfId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	books := make([]domain.ShelfBook, 0)
+	for rows.Next() {
+		var item domain.ShelfBook
+
+		dest := append([]interface{}{&item.Position, &item.AddedAt}, bookDest(&item.Book)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		books = append(books, item)
+	}
+
+	return books, rows.Err()
+}
+
+// AddBook appends the book to the end of the shelf.
+func (r ShelfRepository) AddBook(ctx context.Context, shelfId, bookId int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Lock the shelf so concurrent additions do not get the same position.
+		_, err := tx.ExecContext(ctx, "select id from shelves where id=$1 for update", shelfId)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"insert into shelf_books (shelf_id, book_id, position, added_at) "+
+				"select $1, $2, coalesce(max(position), 0) + 1, $3 from shelf_books where shelf_id=$1 "+
+				"on conflict (shelf_id, book_id) do nothing",
+			shelfId,
+			bookId,
+			time.Now(),
+		)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := result.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return domain.ErrorBookAlreadyOnShelf
+		}
+
+		return nil
+	})
+}
+
+func (r ShelfRepository) RemoveBook(ctx context.Context, shelfId, bookId int64) error {
+	result, err := r.db.ExecContext(ctx, "delete from shelf_books where shelf_id=$1 and book_id=$2", shelfId, bookId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrorBookNotOnShelf
+	}
+
+	return nil
+}
+
+// Reorder assigns positions following the given order, which must contain
+// every book on the shelf exactly once.
+func (r ShelfRepository) Reorder(ctx context.Context, shelfId int64, bookIds []int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, "select book_id from shelf_books where shelf_id=$1 for update", shelfId)
+		if err != nil {
+			return err
+		}
+
+		current := make(map[int64]bool)
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			current[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(current) != len(bookIds) {
+			return domain.ErrorInvalidShelfOrder
+		}
+
+		for _, id := range bookIds {
+			if !current[id] {
+				return domain.ErrorInvalidShelfOrder
+			}
+			delete(current, id)
+		}
+
+		for i, id := range bookIds {
+			_, err := tx.ExecContext(ctx,
+				"update shelf_books set position=$1 where shelf_id=$2 and book_id=$3", i+1, shelfId, id)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func scanShelf(row rowScanner) (domain.Shelf, error) {
+	var shelf domain.Shelf
+	err := row.Scan(
+		&shelf.ID,
+		&shelf.UserID,
+		&shelf.Name,
+		&shelf.Kind,
+		&shelf.Public,
+		&shelf.ShareToken,
+		&shelf.CreatedAt,
+	)
+
+	return shelf, err
+}
@@ -0,0 +1,296 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+const transferColumns = "id, copy_id, from_branch_id, to_branch_id, hold_id, status, requested_by, requested_at, shipped_at, received_at"
+
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// Create requests moving an available copy to another branch. When the
+// transfer is made for a hold, the hold must be waiting for the same book
+// and be picked up at the destination branch.
+func (r TransferRepository) Create(ctx context.Context, t domain.Transfer) (int64, error) {
+	var id int64
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var bookId int64
+		var available bool
+		err := tx.QueryRowContext(ctx,
+			"select book_id, branch_id, "+copyAvailable+" from copies where id=$1 for update", t.CopyID,
+		).Scan(&bookId, &t.FromBranchID, &available)
+		if err == sql.ErrNoRows {
+			return domain.ErrorCopyNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := lockBook(ctx, tx, bookId); err != nil {
+			return err
+		}
+
+		if !available {
+			return domain.ErrorCopyNotAvailable
+		}
+
+		if t.FromBranchID != nil && *t.FromBranchID == t.ToBranchID {
+			return domain.ErrorSameBranchTransfer
+		}
+
+		var exists bool
+		err = tx.QueryRowContext(ctx, "select exists (select 1 from branches where id=$1)", t.ToBranchID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrorBranchNotFound
+		}
+
+		if t.HoldID != nil {
+			var pickupBranchId *int64
+			err = tx.QueryRowContext(ctx,
+				"select pickup_branch_id from holds where id=$1 and book_id=$2 and status='waiting'",
+				*t.HoldID,
+				bookId,
+			).Scan(&pickupBranchId)
+			if err == sql.ErrNoRows {
+				return domain.ErrorHoldNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if pickupBranchId != nil && *pickupBranchId != t.ToBranchID {
+				return domain.ErrorTransferBranchMismatch
+			}
+		}
+
+		return tx.QueryRowContext(ctx,
+			"insert into transfers (copy_id, from_branch_id, to_branch_id, hold_id, status, requested_by, requested_at) "+
+				"values ($1, $2, $3, $4, $5, $6, $7) returning id",
+			t.CopyID,
+			t.FromBranchID,
+			t.ToBranchID,
+			t.HoldID,
+			domain.TransferRequested,
+			t.RequestedBy,
+			t.RequestedAt,
+		).Scan(&id)
+	})
+
+	return id, err
+}
+
+func (r TransferRepository) GetById(ctx context.Context, id int64) (domain.Transfer, error) {
+	row := r.db.QueryRowContext(ctx, "select "+transferColumns+" from transfers where id=$1", id)
+
+	t, err := scanTransfer(row)
+	if err == sql.ErrNoRows {
+		return t, domain.ErrorTransferNotFound
+	}
+
+	return t, err
+}
+
+func (r TransferRepository) GetAll(ctx context.Context, status string) ([]domain.Transfer, error) {
+	query := "select " + transferColumns + " from transfers"
+	args := []interface{}{}
+	if status != "" {
+		query += " where status=$1"
+		args = append(args, status)
+	}
+	query += " order by requested_at desc, id desc"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]domain.Transfer, 0)
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		transfers = append(transfers, t)
+	}
+
+	return transfers, rows.Err()
+}
+
+func (r TransferRepository) Ship(ctx context.Context, id int64, at time.Time) error {
+	return r.move(ctx,
+		"update transfers set status='in_transit', shipped_at=$1 where id=$2 and status='requested'", at, id)
+}
+
+// Cancel stops a transfer that has not been received. The copy stays at its
+// branch and, available again, is reserved for the next hold waiting there.
+func (r TransferRepository) Cancel(ctx context.Context, id int64, at, pickupDeadline time.Time) (*domain.Hold, error) {
+	var hold *domain.Hold
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var copyId, bookId int64
+		err := tx.QueryRowContext(ctx,
+			"select copies.id, copies.book_id from transfers join copies on copies.id=transfers.copy_id "+
+				"where transfers.id=$1 for update of copies",
+			id,
+		).Scan(&copyId, &bookId)
+		if err == sql.ErrNoRows {
+			return domain.ErrorTransferNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := lockBook(ctx, tx, bookId); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"update transfers set status='cancelled' where id=$1 and status in ('requested', 'in_transit')", id)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return domain.ErrorInvalidTransferState
+		}
+
+		hold, err = promoteNextHold(ctx, tx, bookId, copyId, at, pickupDeadline)
+
+		return err
+	})
+
+	return hold, err
+}
+
+// Receive moves the copy to the destination branch. The copy is then reserved
+// for the hold the transfer was made for, or for the next hold waiting at the
+// branch, with the pickup window starting now.
+func (r TransferRepository) Receive(ctx context.Context, id int64, at, pickupDeadline time.Time) (*domain.Hold, error) {
+	var hold *domain.Hold
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var copyId, bookId int64
+		err := tx.QueryRowContext(ctx,
+			"select copies.id, copies.book_id from transfers join copies on copies.id=transfers.copy_id "+
+				"where transfers.id=$1 for update of copies",
+			id,
+		).Scan(&copyId, &bookId)
+		if err == sql.ErrNoRows {
+			return domain.ErrorTransferNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := lockBook(ctx, tx, bookId); err != nil {
+			return err
+		}
+
+		var toBranchId int64
+		var holdId *int64
+		err = tx.QueryRowContext(ctx,
+			"update transfers set status='received', received_at=$1 where id=$2 and status='in_transit' "+
+				"returning to_branch_id, hold_id",
+			at,
+			id,
+		).Scan(&toBranchId, &holdId)
+		if err == sql.ErrNoRows {
+			return domain.ErrorInvalidTransferState
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "update copies set branch_id=$1 where id=$2", toBranchId, copyId); err != nil {
+			return err
+		}
+
+		if holdId != nil {
+			row := tx.QueryRowContext(ctx,
+				"update holds set status='ready', copy_id=$1, ready_at=$2, pickup_deadline=$3 "+
+					"where id=$4 and status='waiting' returning "+readyHoldColumns,
+				copyId,
+				at,
+				pickupDeadline,
+				*holdId,
+			)
+
+			ready, err := scanHold(row)
+			if err == nil {
+				hold = &ready
+				return nil
+			}
+			if err != sql.ErrNoRows {
+				return err
+			}
+		}
+
+		hold, err = promoteNextHold(ctx, tx, bookId, copyId, at, pickupDeadline)
+
+		return err
+	})
+
+	return hold, err
+}
+
+func (r TransferRepository) move(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		var exists bool
+		id := args[len(args)-1]
+		if err := r.db.QueryRowContext(ctx, "select exists (select 1 from transfers where id=$1)", id).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrorTransferNotFound
+		}
+		return domain.ErrorInvalidTransferState
+	}
+
+	return nil
+}
+
+func scanTransfer(row rowScanner) (domain.Transfer, error) {
+	var t domain.Transfer
+	err := row.Scan(
+		&t.ID,
+		&t.CopyID,
+		&t.FromBranchID,
+		&t.ToBranchID,
+		&t.HoldID,
+		&t.Status,
+		&t.RequestedBy,
+		&t.RequestedAt,
+		&t.ShippedAt,
+		&t.ReceivedAt,
+	)
+
+	return t, err
+}
@@ -0,0 +1,112 @@
+package psql
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"sort"
+	"strings"
+)
+
+// searchConfigs maps languages to the PostgreSQL text search configuration
+// that stems them. Languages without one, Uzbek among them, are searched
+// with the "simple" configuration, which only lower-cases words.
+var searchConfigs = map[string]string{
+	"de": "german",
+	"en": "english",
+	"es": "spanish",
+	"fr": "french",
+	"it": "italian",
+	"pt": "portuguese",
+	"ru": "russian",
+	"tr": "turkish",
+}
+
+// searchConfig returns an SQL expression picking the text search
+// configuration for the language tag held in column.
+func searchConfig(column string) string {
+	languages := make([]string, 0, len(searchConfigs))
+	for language := range searchConfigs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "(case lower(split_part(coalesce(%s, ''), '-', 1))", column)
+	for _, language := range languages {
+		fmt.Fprintf(&b, " when '%s' then '%s'", language, searchConfigs[language])
+	}
+	b.WriteString(" else 'simple' end)::regconfig")
+
+	return b.String()
+}
+
+// GetTranslations returns the translations of the given books keyed by
+// book id.
+func (r BookRepository) GetTranslations(ctx context.Context, bookIds []int64) (map[int64][]domain.BookTranslation, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"select book_id, locale, title, coalesce(description, '') from book_translations "+
+			"where book_id=any($1) order by book_id, locale",
+		pq.Array(bookIds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[int64][]domain.BookTranslation)
+	for rows.Next() {
+		var bookId int64
+		var t domain.BookTranslation
+		if err := rows.Scan(&bookId, &t.Locale, &t.Title, &t.Description); err != nil {
+			return nil, err
+		}
+
+		translations[bookId] = append(translations[bookId], t)
+	}
+
+	return translations, rows.Err()
+}
+
+// SetTranslation creates or replaces the translation of a book into
+// t.Locale. Translations are part of the book, so its version grows.
+func (r BookRepository) SetTranslation(ctx context.Context, bookId int64, t domain.BookTranslation) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"insert into book_translations (book_id, locale, title, description) values ($1, $2, $3, $4) "+
+				"on conflict (book_id, locale) do update set title=excluded.title, description=excluded.description",
+			bookId, t.Locale, t.Title, t.Description,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "update books set version=version+1 where id=$1", bookId)
+
+		return err
+	})
+}
+
+func (r BookRepository) DeleteTranslation(ctx context.Context, bookId int64, locale string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "delete from book_translations where book_id=$1 and locale=$2", bookId, locale)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return domain.ErrorTranslationNotFound
+		}
+
+		_, err = tx.ExecContext(ctx, "update books set version=version+1 where id=$1", bookId)
+
+		return err
+	})
+}
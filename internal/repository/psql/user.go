@@ -16,10 +16,11 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r UserRepository) Create(ctx context.Context, user domain.User) (int64, error) {
 	result := r.db.QueryRow(
-		"insert into users (name, email, password, registered_at) values ($1, $2, $3, $4) returning id",
+		"insert into users (name, email, password, role, registered_at) values ($1, $2, $3, $4, $5) returning id",
 		user.Name,
 		user.Email,
 		user.Password,
+		user.Role,
 		user.RegisteredAt,
 	)
 
@@ -34,10 +35,22 @@ func (r UserRepository) Create(ctx context.Context, user domain.User) (int64, er
 
 func (r UserRepository) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
 	row := r.db.QueryRow(
-		"select id, name, email, registered_at from users where email=$1 and password=$2", email, password)
+		"select id, name, email, role, registered_at from users where email=$1 and password=$2", email, password)
 
 	var user domain.User
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.RegisteredAt)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.RegisteredAt)
+	if err == sql.ErrNoRows {
+		return user, domain.ErrorUserNotFound
+	}
+
+	return user, err
+}
+
+func (r UserRepository) GetById(ctx context.Context, id int64) (domain.User, error) {
+	row := r.db.QueryRow("select id, name, email, role, registered_at from users where id=$1", id)
+
+	var user domain.User
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.RegisteredAt)
 	if err == sql.ErrNoRows {
 		return user, domain.ErrorUserNotFound
 	}
@@ -3,42 +3,181 @@ package service
 import (
 	"book_api/internal/domain"
 	"context"
+	"time"
 )
 
 type BookRepository interface {
 	Create(ctx context.Context, book domain.Book) (int64, error)
-	GetAll(ctx context.Context) ([]domain.Book, error)
+	GetAll(ctx context.Context, filter domain.BookFilter) ([]domain.Book, error)
 	GetById(ctx context.Context, id int64) (domain.Book, error)
 	Update(ctx context.Context, id int64, input domain.UpdateBookInput) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id, version int64) error
+	GetBySeries(ctx context.Context, seriesId int64) ([]domain.Book, error)
+	GetNextInSeries(ctx context.Context, book domain.Book) (domain.Book, error)
+	SetCover(ctx context.Context, id int64, cover string) error
+	Import(ctx context.Context, books []domain.Book) (created, updated int, err error)
+	GetExistingISBNs(ctx context.Context, isbns []string) (map[string]bool, error)
+	Export(ctx context.Context, filter domain.BookFilter, fn func(domain.Book) error) error
+	GetAfter(ctx context.Context, filter domain.BookFilter, afterId int64, limit int) ([]domain.Book, error)
+	Count(ctx context.Context, filter domain.BookFilter) (int, error)
+	GetEarliestUpdate(ctx context.Context) (time.Time, error)
+	GetPage(ctx context.Context, filter domain.BookFilter, sort string, limit, offset int) ([]domain.Book, int64, error)
+	GetAuthors(ctx context.Context, limit, offset int) ([]domain.AuthorCount, int64, error)
+	Merge(ctx context.Context, targetId int64, sourceIds []int64, input domain.UpdateBookInput, cover string) error
+	GetTranslations(ctx context.Context, bookIds []int64) (map[int64][]domain.BookTranslation, error)
+	SetTranslation(ctx context.Context, bookId int64, t domain.BookTranslation) error
+	DeleteTranslation(ctx context.Context, bookId int64, locale string) error
 }
 
 type BookService struct {
-	repo BookRepository
+	repo   BookRepository
+	store  BlobStore
+	covers CoverOptions
 }
 
-func NewBookService(repo BookRepository) *BookService {
+func NewBookService(repo BookRepository, store BlobStore, covers CoverOptions) *BookService {
 	return &BookService{
-		repo: repo,
+		repo:   repo,
+		store:  store,
+		covers: covers,
 	}
 }
 
+// Create stores a valid book with its language and ISBN in canonical form;
+// ISBNs are kept as ISBN-13 so that import and duplicate checks find them.
 func (s BookService) Create(ctx context.Context, book domain.Book) (int64, error) {
+	if err := book.Validate(); err != nil {
+		return 0, err
+	}
+
+	language, err := canonicalLanguage(book.Language)
+	if err != nil {
+		return 0, err
+	}
+	book.Language = language
+
+	if book.ISBN != "" {
+		if book.ISBN, err = domain.ISBN13(book.ISBN); err != nil {
+			return 0, err
+		}
+	}
+
 	return s.repo.Create(ctx, book)
 }
 
-func (s BookService) GetAll(ctx context.Context) ([]domain.Book, error) {
-	return s.repo.GetAll(ctx)
+func (s BookService) GetAll(ctx context.Context, filter domain.BookFilter) ([]domain.Book, error) {
+	books, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range books {
+		books[i] = s.withCoverURLs(books[i])
+	}
+
+	return books, nil
+}
+
+// List returns one page of the books matching the filter in the given order.
+func (s BookService) List(ctx context.Context, filter domain.BookFilter, sort string, page, perPage int) (domain.BooksPage, error) {
+	books, total, err := s.repo.GetPage(ctx, filter, sort, perPage, (page-1)*perPage)
+	if err != nil {
+		return domain.BooksPage{}, err
+	}
+
+	for i := range books {
+		books[i] = s.withCoverURLs(books[i])
+	}
+
+	return domain.BooksPage{
+		Items:   books,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+func (s BookService) GetAuthors(ctx context.Context, page, perPage int) (domain.AuthorsPage, error) {
+	authors, total, err := s.repo.GetAuthors(ctx, perPage, (page-1)*perPage)
+	if err != nil {
+		return domain.AuthorsPage{}, err
+	}
+
+	return domain.AuthorsPage{
+		Items:   authors,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+// Export calls fn for every book matching the filter, ordered by id,
+// without loading the whole catalog into memory.
+func (s BookService) Export(ctx context.Context, filter domain.BookFilter, fn func(domain.Book) error) error {
+	return s.repo.Export(ctx, filter, func(book domain.Book) error {
+		return fn(s.withCoverURLs(book))
+	})
 }
 
 func (s BookService) GetById(ctx context.Context, id int64) (domain.Book, error) {
-	return s.repo.GetById(ctx, id)
+	book, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return book, err
+	}
+
+	return s.withCoverURLs(book), nil
 }
 
+// Update validates the changes and, like Create, canonicalizes the language
+// and ISBN before they are stored.
 func (s BookService) Update(ctx context.Context, id int64, input domain.UpdateBookInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if input.Language != nil {
+		language, err := canonicalLanguage(*input.Language)
+		if err != nil {
+			return err
+		}
+		input.Language = &language
+	}
+
+	if input.ISBN != nil && *input.ISBN != "" {
+		isbn, err := domain.ISBN13(*input.ISBN)
+		if err != nil {
+			return err
+		}
+		input.ISBN = &isbn
+	}
+
 	return s.repo.Update(ctx, id, input)
 }
 
-func (s BookService) Delete(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+// Delete removes the book; a non-zero version has to be the current one.
+func (s BookService) Delete(ctx context.Context, id, version int64) error {
+	book, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id, version); err != nil {
+		return err
+	}
+
+	if book.Cover != "" {
+		s.deleteCover(ctx, book.Cover)
+	}
+
+	return nil
+}
+
+// GetNextInSeries returns the book with the closest greater volume number in the same series.
+func (s BookService) GetNextInSeries(ctx context.Context, book domain.Book) (domain.Book, error) {
+	next, err := s.repo.GetNextInSeries(ctx, book)
+	if err != nil {
+		return next, err
+	}
+
+	return s.withCoverURLs(next), nil
 }
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"time"
+)
+
+type BranchRepository interface {
+	Create(ctx context.Context, branch domain.Branch) (int64, error)
+	GetAll(ctx context.Context) ([]domain.Branch, error)
+	GetById(ctx context.Context, id int64) (domain.Branch, error)
+	Update(ctx context.Context, id int64, input domain.UpdateBranchInput) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type TransferRepository interface {
+	Create(ctx context.Context, t domain.Transfer) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Transfer, error)
+	GetAll(ctx context.Context, status string) ([]domain.Transfer, error)
+	Ship(ctx context.Context, id int64, at time.Time) error
+	Receive(ctx context.Context, id int64, at, pickupDeadline time.Time) (*domain.Hold, error)
+	Cancel(ctx context.Context, id int64, at, pickupDeadline time.Time) (*domain.Hold, error)
+}
+
+type BranchService struct {
+	repo         BranchRepository
+	copies       CopyRepository
+	transfers    TransferRepository
+	books        BookRepository
+	pickupPeriod time.Duration
+}
+
+func NewBranchService(
+	repo BranchRepository,
+	copies CopyRepository,
+	transfers TransferRepository,
+	books BookRepository,
+	pickupPeriod time.Duration,
+) *BranchService {
+	return &BranchService{
+		repo:         repo,
+		copies:       copies,
+		transfers:    transfers,
+		books:        books,
+		pickupPeriod: pickupPeriod,
+	}
+}
+
+func (s BranchService) Create(ctx context.Context, branch domain.Branch) (int64, error) {
+	branch.CreatedAt = time.Now()
+
+	return s.repo.Create(ctx, branch)
+}
+
+func (s BranchService) GetAll(ctx context.Context) ([]domain.Branch, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s BranchService) GetById(ctx context.Context, id int64) (domain.Branch, error) {
+	return s.repo.GetById(ctx, id)
+}
+
+func (s BranchService) Update(ctx context.Context, id int64, input domain.UpdateBranchInput) error {
+	if _, err := s.repo.GetById(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, id, input)
+}
+
+func (s BranchService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// GetBookAvailability reports how many copies of the book each branch holds and how many can be checked out.
+func (s BranchService) GetBookAvailability(ctx context.Context, bookId int64) ([]domain.BranchAvailability, error) {
+	return s.copies.GetAvailability(ctx, bookId)
+}
+
+func (s BranchService) RequestTransfer(ctx context.Context, librarianId int64, t domain.Transfer) (domain.Transfer, error) {
+	t.RequestedBy = librarianId
+	t.RequestedAt = time.Now()
+
+	id, err := s.transfers.Create(ctx, t)
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+
+	return s.transfers.GetById(ctx, id)
+}
+
+func (s BranchService) GetTransfer(ctx context.Context, id int64) (domain.Transfer, error) {
+	return s.transfers.GetById(ctx, id)
+}
+
+func (s BranchService) GetTransfers(ctx context.Context, status string) ([]domain.Transfer, error) {
+	return s.transfers.GetAll(ctx, status)
+}
+
+func (s BranchService) ShipTransfer(ctx context.Context, id int64) (domain.Transfer, error) {
+	if err := s.transfers.Ship(ctx, id, time.Now()); err != nil {
+		return domain.Transfer{}, err
+	}
+
+	return s.transfers.GetById(ctx, id)
+}
+
+// ReceiveTransfer completes the transfer and returns the hold the copy was reserved for, if any.
+func (s BranchService) ReceiveTransfer(ctx context.Context, id int64) (*domain.Hold, error) {
+	now := time.Now()
+
+	return s.transfers.Receive(ctx, id, now, now.Add(s.pickupPeriod))
+}
+
+// CancelTransfer stops the transfer, leaving the copy at its branch, and
+// returns the hold the copy was then reserved for, if any.
+func (s BranchService) CancelTransfer(ctx context.Context, id int64) (*domain.Hold, error) {
+	now := time.Now()
+
+	return s.transfers.Cancel(ctx, id, now, now.Add(s.pickupPeriod))
+}
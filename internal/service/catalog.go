@@ -0,0 +1,97 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"strings"
+)
+
+// CreateFromFile catalogs an EPUB: the book is created from the package
+// document and the embedded cover is uploaded. Missing lists the fields the
+// file did not provide; without a title or author no book is created. Once
+// the book exists a cover that could not be stored is only reported.
+func (s BookService) CreateFromFile(ctx context.Context, r io.ReaderAt, size int64) (domain.BookFromFile, error) {
+	format, parsed, err := parseBookFile(r, size)
+	if err != nil {
+		return domain.BookFromFile{}, err
+	}
+	if format != domain.BookFileEPUB {
+		return domain.BookFromFile{}, domain.ErrorUnsupportedBookFileType
+	}
+
+	meta := fileMetadata(parsed)
+
+	book := domain.Book{
+		Title:       meta.Title,
+		Author:      strings.Join(meta.Authors, "; "),
+		ISBN:        meta.ISBN,
+		Description: meta.Description,
+	}
+	if meta.PublishDate != nil {
+		book.PublishDate = *meta.PublishDate
+	}
+
+	// A language the file gets wrong is no reason to reject it.
+	if language, err := canonicalLanguage(meta.Language); err == nil {
+		book.Language = language
+	}
+
+	result := domain.BookFromFile{Missing: make([]string, 0)}
+	for _, field := range []struct {
+		name    string
+		missing bool
+	}{
+		{"title", book.Title == ""},
+		{"author", book.Author == ""},
+		{"isbn", book.ISBN == ""},
+		{"publish_date", book.PublishDate.IsZero()},
+		{"description", book.Description == ""},
+		{"language", book.Language == ""},
+		{"cover", len(parsed.Cover) == 0},
+	} {
+		if field.missing {
+			result.Missing = append(result.Missing, field.name)
+		}
+	}
+
+	if book.Title == "" || book.Author == "" {
+		return result, domain.ErrorEmptyRequiredField
+	}
+
+	if book.ISBN != "" {
+		existing, err := s.repo.GetExistingISBNs(ctx, []string{book.ISBN})
+		if err != nil {
+			return result, err
+		}
+
+		if existing[book.ISBN] {
+			return result, domain.ErrorDuplicateISBN
+		}
+	}
+
+	id, err := s.Create(ctx, book)
+	if err != nil {
+		return result, err
+	}
+
+	if len(parsed.Cover) > 0 {
+		_, err := s.UploadCover(ctx, id, parsed.Cover)
+		if errors.Is(err, domain.ErrorCoverTooLarge) || errors.Is(err, domain.ErrorUnsupportedCoverType) {
+			result.Missing = append(result.Missing, "cover")
+			result.CoverError = err.Error()
+		} else if err != nil {
+			log.WithFields(log.Fields{
+				"book_id": id,
+				"problem": "upload cover error",
+			}).Error(err)
+			result.CoverError = "the cover could not be stored"
+		}
+	}
+
+	result.Book, err = s.GetById(ctx, id)
+
+	return result, err
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"time"
+)
+
+type CopyRepository interface {
+	Create(ctx context.Context, c domain.Copy, pickupDeadline time.Time) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Copy, error)
+	GetByBook(ctx context.Context, bookId int64) ([]domain.Copy, error)
+	Update(ctx context.Context, id int64, input domain.UpdateCopyInput) error
+	Delete(ctx context.Context, id int64) error
+	GetAvailability(ctx context.Context, bookId int64) ([]domain.BranchAvailability, error)
+}
+
+type LoanRepository interface {
+	Checkout(ctx context.Context, loan domain.Loan, maxLoans int, pickupDeadline time.Time) (domain.Loan, error)
+	Return(ctx context.Context, copyId int64, returnedAt, pickupDeadline time.Time, rate domain.FineRate) (domain.ReturnResult, error)
+	GetByUser(ctx context.Context, userId int64, onlyActive bool) ([]domain.Loan, error)
+	GetByCopy(ctx context.Context, copyId int64) ([]domain.Loan, error)
+}
+
+type CirculationOptions struct {
+	LoanPeriod   time.Duration
+	MaxLoans     int
+	PickupPeriod time.Duration
+
+	// FineRate settles the fine of an overdue loan when it is returned.
+	FineRate domain.FineRate
+
+	// BookURL is a format string taking the book id, encoded in copy labels.
+	BookURL string
+}
+
+type CirculationService struct {
+	copies  CopyRepository
+	loans   LoanRepository
+	books   BookRepository
+	options CirculationOptions
+}
+
+func NewCirculationService(copies CopyRepository, loans LoanRepository, books BookRepository, options CirculationOptions) *CirculationService {
+	return &CirculationService{
+		copies:  copies,
+		loans:   loans,
+		books:   books,
+		options: options,
+	}
+}
+
+// CreateCopy adds a copy of a book, reserving it for the next waiting hold
+// until the pickup deadline.
+func (s CirculationService) CreateCopy(ctx context.Context, c domain.Copy) (int64, error) {
+	if _, err := s.books.GetById(ctx, c.BookID); err != nil {
+		return 0, err
+	}
+
+	c.CreatedAt = time.Now()
+
+	return s.copies.Create(ctx, c, c.CreatedAt.Add(s.options.PickupPeriod))
+}
+
+func (s CirculationService) GetCopy(ctx context.Context, id int64) (domain.Copy, error) {
+	return s.copies.GetById(ctx, id)
+}
+
+func (s CirculationService) GetBookCopies(ctx context.Context, bookId int64) ([]domain.Copy, error) {
+	if _, err := s.books.GetById(ctx, bookId); err != nil {
+		return nil, err
+	}
+
+	return s.copies.GetByBook(ctx, bookId)
+}
+
+func (s CirculationService) UpdateCopy(ctx context.Context, id int64, input domain.UpdateCopyInput) error {
+	if _, err := s.copies.GetById(ctx, id); err != nil {
+		return err
+	}
+
+	return s.copies.Update(ctx, id, input)
+}
+
+// DeleteCopy removes a copy that is on the shelf and has never been lent;
+// copies with loans are kept for their history.
+func (s CirculationService) DeleteCopy(ctx context.Context, id int64) error {
+	return s.copies.Delete(ctx, id)
+}
+
+// Checkout lends the copy to the user for the configured loan period.
+func (s CirculationService) Checkout(ctx context.Context, userId, copyId int64) (domain.Loan, error) {
+	now := time.Now()
+
+	return s.loans.Checkout(ctx, domain.Loan{
+		CopyID:       copyId,
+		UserID:       userId,
+		CheckedOutAt: now,
+		DueAt:        now.Add(s.options.LoanPeriod),
+	}, s.options.MaxLoans, now.Add(s.options.PickupPeriod))
+}
+
+// Return closes the loan of the copy, fixing the fine of an overdue loan at
+// its final amount. If somebody is waiting for the book the copy is reserved
+// for them until the pickup deadline.
+func (s CirculationService) Return(ctx context.Context, copyId int64) (domain.ReturnResult, error) {
+	now := time.Now()
+
+	return s.loans.Return(ctx, copyId, now, now.Add(s.options.PickupPeriod), s.options.FineRate)
+}
+
+func (s CirculationService) GetUserLoans(ctx context.Context, userId int64, onlyActive bool) ([]domain.Loan, error) {
+	return s.loans.GetByUser(ctx, userId, onlyActive)
+}
+
+func (s CirculationService) GetCopyLoans(ctx context.Context, copyId int64) ([]domain.Loan, error) {
+	if _, err := s.copies.GetById(ctx, copyId); err != nil {
+		return nil, err
+	}
+
+	return s.loans.GetByCopy(ctx, copyId)
+}
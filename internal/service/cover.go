@@ -0,0 +1,171 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxCoverPixels bounds the size of a decoded cover, since a small upload
+// can decompress into an image large enough to exhaust memory.
+const maxCoverPixels = 25_000_000
+
+var coverExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+type CoverOptions struct {
+	MaxSize        int64
+	ThumbnailSizes []int
+}
+
+// UploadCover validates the image by its content and dimensions, stores it
+// along with a JPEG thumbnail for every configured width and points the book
+// at the new cover. Nothing stays stored when any step fails.
+func (s BookService) UploadCover(ctx context.Context, id int64, data []byte) (domain.Book, error) {
+	book, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return book, err
+	}
+
+	if int64(len(data)) > s.covers.MaxSize {
+		return book, domain.ErrorCoverTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := coverExtensions[contentType]
+	if !ok {
+		return book, domain.ErrorUnsupportedCoverType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return book, domain.ErrorUnsupportedCoverType
+	}
+	if config.Width < 1 || config.Height < 1 || int64(config.Width)*int64(config.Height) > maxCoverPixels {
+		return book, fmt.Errorf("%w: %dx%d pixels", domain.ErrorCoverTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return book, domain.ErrorUnsupportedCoverType
+	}
+
+	key := fmt.Sprintf("covers/%d/%d%s", id, time.Now().UnixNano(), ext)
+
+	if err := s.storeCover(ctx, key, img, data, contentType); err != nil {
+		s.deleteCover(ctx, key)
+		return book, err
+	}
+
+	if err := s.repo.SetCover(ctx, id, key); err != nil {
+		s.deleteCover(ctx, key)
+		return book, err
+	}
+
+	if book.Cover != "" {
+		s.deleteCover(ctx, book.Cover)
+	}
+
+	book.Cover = key
+
+	return s.withCoverURLs(book), nil
+}
+
+// storeCover puts the image and its thumbnails under the key.
+func (s BookService) storeCover(ctx context.Context, key string, img image.Image, data []byte, contentType string) error {
+	err := s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+	if err != nil {
+		return err
+	}
+
+	for _, size := range s.covers.ThumbnailSizes {
+		thumb, err := thumbnail(img, size)
+		if err != nil {
+			return err
+		}
+
+		err = s.store.Put(ctx, thumbnailKey(key, size), bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s BookService) withCoverURLs(book domain.Book) domain.Book {
+	if book.Cover == "" {
+		return book
+	}
+
+	book.CoverURL = s.store.URL(book.Cover)
+	book.ThumbnailURLs = make(map[string]string, len(s.covers.ThumbnailSizes))
+	for _, size := range s.covers.ThumbnailSizes {
+		book.ThumbnailURLs[strconv.Itoa(size)] = s.store.URL(thumbnailKey(book.Cover, size))
+	}
+
+	return book
+}
+
+func (s BookService) deleteCover(ctx context.Context, key string) {
+	s.store.Delete(ctx, key)
+	for _, size := range s.covers.ThumbnailSizes {
+		s.store.Delete(ctx, thumbnailKey(key, size))
+	}
+}
+
+func thumbnailKey(key string, size int) string {
+	if i := strings.LastIndex(key, "."); i > 0 {
+		key = key[:i]
+	}
+
+	return fmt.Sprintf("%s_%d.jpg", key, size)
+}
+
+// thumbnail scales the image down to the given width keeping its aspect ratio.
+// Images that are already narrower are re-encoded without scaling.
+func thumbnail(img image.Image, width int) ([]byte, error) {
+	bounds := img.Bounds()
+
+	dst := image.Image(img)
+	if bounds.Dx() > width {
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+
+		scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Over, nil)
+		dst = scaled
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/storage"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// coverBooks stands in for the book repository; only the methods
+// UploadCover uses are implemented.
+type coverBooks struct {
+	BookRepository
+
+	book     domain.Book
+	setCover error
+}
+
+func (b *coverBooks) GetById(ctx context.Context, id int64) (domain.Book, error) {
+	if id != b.book.ID {
+		return domain.Book{}, domain.ErrorBookNotFound
+	}
+
+	return b.book, nil
+}
+
+func (b *coverBooks) SetCover(ctx context.Context, id int64, cover string) error {
+	if b.setCover != nil {
+		return b.setCover
+	}
+	b.book.Cover = cover
+
+	return nil
+}
+
+// newCoverService wires a book service to a local store in a temporary directory.
+func newCoverService(t *testing.T) (*BookService, *coverBooks, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	store, err := storage.NewLocal(root, "http://localhost/files")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	books := &coverBooks{book: domain.Book{ID: 1, Title: "Title"}}
+	covers := CoverOptions{MaxSize: 1 << 20, ThumbnailSizes: []int{2}}
+
+	return NewBookService(books, store, covers), books, root
+}
+
+func coverPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 0xff})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// storedFiles lists the objects under root, relative to it.
+func storedFiles(t *testing.T, root string) []string {
+	t.Helper()
+
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return files
+}
+
+func TestUploadCover(t *testing.T) {
+	s, books, root := newCoverService(t)
+
+	book, err := s.UploadCover(context.Background(), 1, coverPNG(t, 4, 8))
+	if err != nil {
+		t.Fatalf("UploadCover: %v", err)
+	}
+
+	if books.book.Cover == "" || book.Cover != books.book.Cover {
+		t.Fatalf("cover = %q, stored %q", book.Cover, books.book.Cover)
+	}
+	if !strings.HasSuffix(book.Cover, ".png") {
+		t.Errorf("cover key %q lacks the .png extension", book.Cover)
+	}
+	if book.CoverURL != "http://localhost/files/"+book.Cover {
+		t.Errorf("cover URL = %q", book.CoverURL)
+	}
+
+	files := storedFiles(t, root)
+	if len(files) != 2 {
+		t.Fatalf("stored files = %v, want the cover and one thumbnail", files)
+	}
+
+	thumb, err := os.ReadFile(filepath.Join(root, thumbnailKey(book.Cover, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || config.Width != 2 || config.Height != 4 {
+		t.Errorf("thumbnail = %s %dx%d, want jpeg 2x4", format, config.Width, config.Height)
+	}
+}
+
+func TestUploadCoverRejectsLargeDimensions(t *testing.T) {
+	s, books, root := newCoverService(t)
+
+	// Claim 10000x10000 pixels in the header of a tiny PNG.
+	data := coverPNG(t, 1, 1)
+	binary.BigEndian.PutUint32(data[16:20], 10000)
+	binary.BigEndian.PutUint32(data[20:24], 10000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	_, err := s.UploadCover(context.Background(), 1, data)
+	if !errors.Is(err, domain.ErrorCoverTooLarge) {
+		t.Fatalf("err = %v, want %v", err, domain.ErrorCoverTooLarge)
+	}
+
+	if books.book.Cover != "" {
+		t.Errorf("cover set to %q", books.book.Cover)
+	}
+	if files := storedFiles(t, root); len(files) != 0 {
+		t.Errorf("stored files = %v, want none", files)
+	}
+}
+
+func TestUploadCoverRejectsUnsupportedType(t *testing.T) {
+	s, _, _ := newCoverService(t)
+
+	_, err := s.UploadCover(context.Background(), 1, []byte("GIF89a not really"))
+	if !errors.Is(err, domain.ErrorUnsupportedCoverType) {
+		t.Fatalf("err = %v, want %v", err, domain.ErrorUnsupportedCoverType)
+	}
+}
+
+func TestUploadCoverRemovesBlobsWhenSetCoverFails(t *testing.T) {
+	s, books, root := newCoverService(t)
+	books.setCover = errors.New("database is down")
+
+	_, err := s.UploadCover(context.Background(), 1, coverPNG(t, 4, 8))
+	if !errors.Is(err, books.setCover) {
+		t.Fatalf("err = %v, want %v", err, books.setCover)
+	}
+
+	if files := storedFiles(t, root); len(files) != 0 {
+		t.Errorf("stored files = %v, want none", files)
+	}
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/fuzzy"
+	"context"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	titleWeight  = 0.7
+	authorWeight = 0.3
+
+	// Trigrams shared by more titles than this, such as those of "the",
+	// only add to the score of pairs found through rarer trigrams.
+	maxTrigramPostings = 200
+)
+
+type duplicateEntry struct {
+	book   domain.Book
+	isbn   string
+	title  []string
+	author []string
+}
+
+// FindDuplicates lists clusters of books that look like the same record:
+// the same ISBN, or titles and authors similar enough to reach minScore.
+// Books with different ISBNs are different editions and never paired.
+// Clusters come strongest first.
+func (s BookService) FindDuplicates(ctx context.Context, minScore float64, page, perPage int) (domain.DuplicatesPage, error) {
+	entries := make([]duplicateEntry, 0)
+	err := s.repo.Export(ctx, domain.BookFilter{}, func(book domain.Book) error {
+		isbn, err := domain.ISBN13(book.ISBN)
+		if err != nil {
+			isbn = ""
+		}
+
+		entries = append(entries, duplicateEntry{
+			book:   book,
+			isbn:   isbn,
+			title:  fuzzy.Trigrams(book.Title),
+			author: fuzzy.Trigrams(book.Author),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return domain.DuplicatesPage{}, err
+	}
+
+	pairs := duplicatePairs(entries, minScore)
+	clusters := duplicateClusters(entries, pairs)
+
+	result := domain.DuplicatesPage{
+		Items:   make([]domain.DuplicateCluster, 0),
+		Total:   int64(len(clusters)),
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	start := (page - 1) * perPage
+	if start < len(clusters) {
+		end := start + perPage
+		if end > len(clusters) {
+			end = len(clusters)
+		}
+
+		for _, cluster := range clusters[start:end] {
+			for i := range cluster.Books {
+				cluster.Books[i] = s.withCoverURLs(cluster.Books[i])
+			}
+			result.Items = append(result.Items, cluster)
+		}
+	}
+
+	return result, nil
+}
+
+// duplicatePairs scores the pairs sharing an ISBN or a title trigram and
+// keeps those reaching minScore.
+func duplicatePairs(entries []duplicateEntry, minScore float64) []domain.DuplicatePair {
+	byISBN := make(map[string][]int)
+	byTrigram := make(map[string][]int)
+	for i, e := range entries {
+		if e.isbn != "" {
+			byISBN[e.isbn] = append(byISBN[e.isbn], i)
+		}
+		for _, t := range e.title {
+			byTrigram[t] = append(byTrigram[t], i)
+		}
+	}
+
+	pairs := make([]domain.DuplicatePair, 0)
+	for i, a := range entries {
+		candidates := make(map[int]bool)
+		for _, j := range byISBN[a.isbn] {
+			if j > i {
+				candidates[j] = true
+			}
+		}
+		for _, t := range a.title {
+			if postings := byTrigram[t]; len(postings) <= maxTrigramPostings {
+				for _, j := range postings {
+					if j > i {
+						candidates[j] = true
+					}
+				}
+			}
+		}
+
+		for j := range candidates {
+			b := entries[j]
+			if a.isbn != "" && b.isbn != "" && a.isbn != b.isbn {
+				continue
+			}
+
+			pair := domain.DuplicatePair{
+				BookIDs:          [2]int64{a.book.ID, b.book.ID},
+				TitleSimilarity:  round(fuzzy.Similarity(a.title, b.title)),
+				AuthorSimilarity: round(fuzzy.Similarity(a.author, b.author)),
+				SameISBN:         a.isbn != "" && a.isbn == b.isbn,
+			}
+
+			pair.Score = round(titleWeight*pair.TitleSimilarity + authorWeight*pair.AuthorSimilarity)
+			if pair.SameISBN {
+				pair.Score = 1
+			}
+
+			if pair.Score >= minScore {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+
+	return pairs
+}
+
+// duplicateClusters joins the books of overlapping pairs into clusters.
+func duplicateClusters(entries []duplicateEntry, pairs []domain.DuplicatePair) []domain.DuplicateCluster {
+	index := make(map[int64]int, len(entries))
+	for i, e := range entries {
+		index[e.book.ID] = i
+	}
+
+	parent := make(map[int]int)
+	var find func(i int) int
+	find = func(i int) int {
+		p, ok := parent[i]
+		if !ok || p == i {
+			return i
+		}
+		parent[i] = find(p)
+		return parent[i]
+	}
+
+	for _, pair := range pairs {
+		a, b := find(index[pair.BookIDs[0]]), find(index[pair.BookIDs[1]])
+		if a != b {
+			parent[b] = a
+		}
+	}
+
+	byRoot := make(map[int]*domain.DuplicateCluster)
+	for _, pair := range pairs {
+		root := find(index[pair.BookIDs[0]])
+		cluster, ok := byRoot[root]
+		if !ok {
+			cluster = &domain.DuplicateCluster{}
+			byRoot[root] = cluster
+		}
+
+		cluster.Pairs = append(cluster.Pairs, pair)
+		cluster.Score = math.Max(cluster.Score, pair.Score)
+	}
+
+	for i, e := range entries {
+		if cluster, ok := byRoot[find(i)]; ok {
+			cluster.Books = append(cluster.Books, e.book)
+		}
+	}
+
+	clusters := make([]domain.DuplicateCluster, 0, len(byRoot))
+	for _, cluster := range byRoot {
+		sort.Slice(cluster.Pairs, func(i, j int) bool {
+			if cluster.Pairs[i].Score != cluster.Pairs[j].Score {
+				return cluster.Pairs[i].Score > cluster.Pairs[j].Score
+			}
+			return cluster.Pairs[i].BookIDs[0] < cluster.Pairs[j].BookIDs[0]
+		})
+		clusters = append(clusters, *cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Score != clusters[j].Score {
+			return clusters[i].Score > clusters[j].Score
+		}
+		return clusters[i].Books[0].ID < clusters[j].Books[0].ID
+	})
+
+	return clusters
+}
+
+func round(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
+
+// Merge folds the source books into the target. Fields the target lacks are
+// taken from the first source that has them and subjects are combined;
+// everything referring to the sources is moved to the target.
+func (s BookService) Merge(ctx context.Context, input domain.MergeBooksInput) (domain.MergeResult, error) {
+	sourceIds := make([]int64, 0, len(input.SourceIDs))
+	seen := map[int64]bool{input.TargetID: true}
+	for _, id := range input.SourceIDs {
+		if id == input.TargetID {
+			return domain.MergeResult{}, domain.ErrorInvalidMerge
+		}
+		if !seen[id] {
+			seen[id] = true
+			sourceIds = append(sourceIds, id)
+		}
+	}
+
+	target, err := s.repo.GetById(ctx, input.TargetID)
+	if err != nil {
+		return domain.MergeResult{}, err
+	}
+
+	sources := make([]domain.Book, 0, len(sourceIds))
+	for _, id := range sourceIds {
+		book, err := s.repo.GetById(ctx, id)
+		if err != nil {
+			return domain.MergeResult{}, err
+		}
+		sources = append(sources, book)
+	}
+
+	update, cover, filled := mergeFields(target, sources)
+
+	if err := s.repo.Merge(ctx, target.ID, sourceIds, update, cover); err != nil {
+		return domain.MergeResult{}, err
+	}
+
+	for _, source := range sources {
+		if source.Cover != "" && source.Cover != cover {
+			s.deleteCover(ctx, source.Cover)
+		}
+	}
+
+	book, err := s.GetById(ctx, target.ID)
+
+	return domain.MergeResult{
+		Book:   book,
+		Merged: sourceIds,
+		Filled: filled,
+	}, err
+}
+
+func mergeFields(target domain.Book, sources []domain.Book) (domain.UpdateBookInput, string, []string) {
+	var (
+		input  domain.UpdateBookInput
+		cover  string
+		filled = make([]string, 0)
+	)
+
+	subjects := append([]string(nil), target.Subjects...)
+	known := make(map[string]bool)
+	for _, subject := range subjects {
+		known[strings.ToLower(subject)] = true
+	}
+
+	for i := range sources {
+		source := sources[i]
+
+		if target.ISBN == "" && input.ISBN == nil && source.ISBN != "" {
+			input.ISBN = &source.ISBN
+			filled = append(filled, "isbn")
+		}
+
+		if target.Description == "" && input.Description == nil && source.Description != "" {
+			input.Description = &source.Description
+			filled = append(filled, "description")
+		}
+
+		if target.PageCount == 0 && input.PageCount == nil && source.PageCount != 0 {
+			input.PageCount = &source.PageCount
+			filled = append(filled, "page_count")
+		}
+
+		if target.PublishDate.IsZero() && input.PublishDate == nil && !source.PublishDate.IsZero() {
+			input.PublishDate = &source.PublishDate
+			filled = append(filled, "publish_date")
+		}
+
+		if target.SeriesID == nil && input.SeriesID == nil && source.SeriesID != nil {
+			input.SeriesID = source.SeriesID
+			input.Volume = source.Volume
+			filled = append(filled, "series_id")
+		}
+
+		if target.Cover == "" && cover == "" && source.Cover != "" {
+			cover = source.Cover
+			filled = append(filled, "cover")
+		}
+
+		for _, subject := range source.Subjects {
+			if !known[strings.ToLower(subject)] {
+				known[strings.ToLower(subject)] = true
+				subjects = append(subjects, subject)
+			}
+		}
+	}
+
+	if len(subjects) > len(target.Subjects) {
+		input.Subjects = &subjects
+		filled = append(filled, "subjects")
+	}
+
+	return input, cover, filled
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/fuzzy"
+	"reflect"
+	"testing"
+)
+
+func duplicateEntries(books []domain.Book) []duplicateEntry {
+	entries := make([]duplicateEntry, 0, len(books))
+	for _, book := range books {
+		isbn, err := domain.ISBN13(book.ISBN)
+		if err != nil {
+			isbn = ""
+		}
+
+		entries = append(entries, duplicateEntry{
+			book:   book,
+			isbn:   isbn,
+			title:  fuzzy.Trigrams(book.Title),
+			author: fuzzy.Trigrams(book.Author),
+		})
+	}
+
+	return entries
+}
+
+func TestDuplicatePairs(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  domain.Book
+		match bool
+		score float64
+	}{
+		{
+			name:  "same title and author spelled differently",
+			a:     domain.Book{Title: "The Master and Margarita", Author: "Mikhail Bulgakov"},
+			b:     domain.Book{Title: "The master & Margarita", Author: "Bulgakov, Mikhail"},
+			match: true,
+			score: 0.878,
+		},
+		{
+			name:  "identical records",
+			a:     domain.Book{Title: "Dune", Author: "Frank Herbert"},
+			b:     domain.Book{Title: "Dune", Author: "Frank Herbert"},
+			match: true,
+			score: 1,
+		},
+		{
+			name:  "ISBN-10 and ISBN-13 of one edition",
+			a:     domain.Book{Title: "Anna Karenina", Author: "Tolstoy", ISBN: "0-306-40615-2"},
+			b:     domain.Book{Title: "Анна Каренина", Author: "Толстой", ISBN: "9780306406157"},
+			match: true,
+			score: 1,
+		},
+		{
+			name:  "same title by another author",
+			a:     domain.Book{Title: "Collected Poems", Author: "Sylvia Plath"},
+			b:     domain.Book{Title: "Collected Poems", Author: "Philip Larkin"},
+			match: false,
+		},
+		{
+			name: "sequel",
+			a:    domain.Book{Title: "Dune", Author: "Frank Herbert"},
+			b:    domain.Book{Title: "Dune Messiah", Author: "Frank Herbert"},
+		},
+		{
+			name: "different ISBNs are different editions",
+			a:    domain.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780306406157"},
+			b:    domain.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780804429573"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.a.ID, tt.b.ID = 1, 2
+			pairs := duplicatePairs(duplicateEntries([]domain.Book{tt.a, tt.b}), 0.8)
+
+			if !tt.match {
+				if len(pairs) != 0 {
+					t.Errorf("got pairs %+v, want none", pairs)
+				}
+				return
+			}
+
+			if len(pairs) != 1 {
+				t.Fatalf("got pairs %+v, want one", pairs)
+			}
+			if pairs[0].BookIDs != [2]int64{1, 2} || pairs[0].Score != tt.score {
+				t.Errorf("got %+v, want books 1 and 2 with score %v", pairs[0], tt.score)
+			}
+		})
+	}
+}
+
+func TestDuplicateClusters(t *testing.T) {
+	books := []domain.Book{
+		{ID: 1, Title: "War and Peace", Author: "Leo Tolstoy"},
+		{ID: 2, Title: "Dune", Author: "Frank Herbert"},
+		{ID: 3, Title: "War & Peace", Author: "Tolstoy, Leo"},
+		{ID: 4, Title: "War and Peace.", Author: "L. Tolstoy"},
+		{ID: 5, Title: "Dune", Author: "Frank Herbert"},
+		{ID: 6, Title: "Solaris", Author: "Stanislaw Lem"},
+	}
+
+	entries := duplicateEntries(books)
+	clusters := duplicateClusters(entries, duplicatePairs(entries, 0.8))
+
+	var ids [][]int64
+	for _, cluster := range clusters {
+		var clusterIds []int64
+		for _, book := range cluster.Books {
+			clusterIds = append(clusterIds, book.ID)
+		}
+		ids = append(ids, clusterIds)
+	}
+
+	// The exact copies of Dune outscore the differently spelled records of
+	// War and Peace.
+	want := [][]int64{{2, 5}, {1, 3, 4}}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("got clusters %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/metadata"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MetadataProvider looks up an edition in an external catalog.
+type MetadataProvider interface {
+	Lookup(ctx context.Context, isbn string) (metadata.Record, error)
+	Cover(ctx context.Context, record metadata.Record) ([]byte, error)
+}
+
+type EnrichService struct {
+	repo     BookRepository
+	books    BookEditor
+	provider MetadataProvider
+}
+
+func NewEnrichService(repo BookRepository, books BookEditor, provider MetadataProvider) *EnrichService {
+	return &EnrichService{
+		repo:     repo,
+		books:    books,
+		provider: provider,
+	}
+}
+
+// Enrich looks the book up by its ISBN and proposes the description, page
+// count, subjects and cover found, field by field. Accepted fields are
+// validated like any other change and written to the book; every accepted
+// field has to be in the proposal.
+func (s EnrichService) Enrich(ctx context.Context, id int64, input domain.EnrichInput) (domain.EnrichResult, error) {
+	book, err := s.books.GetById(ctx, id)
+	if err != nil {
+		return domain.EnrichResult{}, err
+	}
+
+	if book.ISBN == "" {
+		return domain.EnrichResult{}, domain.ErrorBookWithoutISBN
+	}
+
+	record, err := s.provider.Lookup(ctx, book.ISBN)
+	if errors.Is(err, metadata.ErrorNotFound) {
+		return domain.EnrichResult{}, domain.ErrorMetadataNotFound
+	}
+	if err != nil {
+		return domain.EnrichResult{}, fmt.Errorf("%w: %s", domain.ErrorMetadataUnavailable, err)
+	}
+
+	result := domain.EnrichResult{
+		ISBN:     book.ISBN,
+		Proposal: proposeFields(book, record),
+		Applied:  make([]string, 0),
+		Book:     book,
+	}
+
+	proposed := make(map[string]bool, len(result.Proposal))
+	for _, field := range result.Proposal {
+		proposed[field.Field] = true
+	}
+
+	accepted := make(map[string]bool, len(input.Accept))
+	for _, field := range input.Accept {
+		if !proposed[field] {
+			return result, fmt.Errorf("%w: %s", domain.ErrorUnknownEnrichField, field)
+		}
+		accepted[field] = true
+	}
+
+	if len(accepted) == 0 {
+		return result, nil
+	}
+
+	var update domain.UpdateBookInput
+	if accepted["description"] {
+		update.Description = &record.Description
+		result.Applied = append(result.Applied, "description")
+	}
+	if accepted["page_count"] {
+		update.PageCount = &record.PageCount
+		result.Applied = append(result.Applied, "page_count")
+	}
+	if accepted["subjects"] {
+		update.Subjects = &record.Subjects
+		result.Applied = append(result.Applied, "subjects")
+	}
+
+	if len(result.Applied) > 0 {
+		if err := update.Validate(); err != nil {
+			return result, err
+		}
+
+		if err := s.repo.Update(ctx, id, update); err != nil {
+			return result, err
+		}
+	}
+
+	if accepted["cover"] {
+		data, err := s.provider.Cover(ctx, record)
+		if err != nil {
+			return result, fmt.Errorf("%w: %s", domain.ErrorMetadataUnavailable, err)
+		}
+
+		if _, err := s.books.UploadCover(ctx, id, data); err != nil {
+			return result, err
+		}
+		result.Applied = append(result.Applied, "cover")
+	}
+
+	result.Book, err = s.books.GetById(ctx, id)
+
+	return result, err
+}
+
+// proposeFields lists the fields the record has a value for.
+func proposeFields(book domain.Book, record metadata.Record) []domain.FieldProposal {
+	proposal := make([]domain.FieldProposal, 0, 4)
+
+	if record.Description != "" {
+		proposal = append(proposal, domain.FieldProposal{
+			Field:    "description",
+			Current:  book.Description,
+			Proposed: record.Description,
+			Changed:  book.Description != record.Description,
+		})
+	}
+
+	if record.PageCount > 0 {
+		proposal = append(proposal, domain.FieldProposal{
+			Field:    "page_count",
+			Current:  book.PageCount,
+			Proposed: record.PageCount,
+			Changed:  book.PageCount != record.PageCount,
+		})
+	}
+
+	if len(record.Subjects) > 0 {
+		current := book.Subjects
+		if current == nil {
+			current = make([]string, 0)
+		}
+
+		proposal = append(proposal, domain.FieldProposal{
+			Field:    "subjects",
+			Current:  current,
+			Proposed: record.Subjects,
+			Changed:  !sameSubjects(book.Subjects, record.Subjects),
+		})
+	}
+
+	// Images are not compared; a new cover always counts as a change.
+	if record.CoverURL != "" {
+		var current interface{}
+		if book.CoverURL != "" {
+			current = book.CoverURL
+		}
+
+		proposal = append(proposal, domain.FieldProposal{
+			Field:    "cover",
+			Current:  current,
+			Proposed: record.CoverURL,
+			Changed:  true,
+		})
+	}
+
+	return proposal
+}
+
+// sameSubjects compares subject lists ignoring order and case.
+func sameSubjects(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	seen := make(map[string]int, len(a))
+	for _, subject := range a {
+		seen[strings.ToLower(subject)]++
+	}
+	for _, subject := range b {
+		key := strings.ToLower(subject)
+		if seen[key] == 0 {
+			return false
+		}
+		seen[key]--
+	}
+
+	return true
+}
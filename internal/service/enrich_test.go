@@ -0,0 +1,176 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/metadata"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const enrichISBN = "9780306406157"
+
+// enrichBooks stands in for both the book repository and the book service;
+// only the methods Enrich uses are implemented.
+type enrichBooks struct {
+	BookRepository
+
+	book    domain.Book
+	updates []domain.UpdateBookInput
+	covers  [][]byte
+}
+
+func (b *enrichBooks) GetById(ctx context.Context, id int64) (domain.Book, error) {
+	if id != b.book.ID {
+		return domain.Book{}, domain.ErrorBookNotFound
+	}
+
+	return b.book, nil
+}
+
+func (b *enrichBooks) Update(ctx context.Context, id int64, input domain.UpdateBookInput) error {
+	b.updates = append(b.updates, input)
+	if input.Description != nil {
+		b.book.Description = *input.Description
+	}
+	if input.PageCount != nil {
+		b.book.PageCount = *input.PageCount
+	}
+
+	return nil
+}
+
+func (b *enrichBooks) UploadCover(ctx context.Context, id int64, data []byte) (domain.Book, error) {
+	b.covers = append(b.covers, data)
+
+	return b.book, nil
+}
+
+// newEnrichService wires the service to a stand-in metadata service that
+// answers every request with the handler.
+func newEnrichService(t *testing.T, handler http.HandlerFunc) (*EnrichService, *enrichBooks) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	books := &enrichBooks{book: domain.Book{ID: 1, Title: "Title", Author: "Author", ISBN: enrichISBN}}
+	provider := metadata.NewOpenLibrary(server.URL, server.URL)
+
+	return NewEnrichService(books, books, provider), books
+}
+
+func catalogHandler(edition string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/isbn/" + enrichISBN + ".json":
+			w.Write([]byte(edition))
+		case "/b/id/42-L.jpg":
+			w.Write([]byte("cover"))
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestEnrichApplies(t *testing.T) {
+	service, books := newEnrichService(t, catalogHandler(
+		`{"description": "A novel.", "number_of_pages": 320, "subjects": ["Fiction"], "covers": [42]}`))
+
+	result, err := service.Enrich(context.Background(), 1, domain.EnrichInput{Accept: []string{"page_count", "cover"}})
+	if err != nil {
+		t.Fatalf("enrich: %v", err)
+	}
+
+	fields := make([]string, 0, len(result.Proposal))
+	for _, p := range result.Proposal {
+		fields = append(fields, p.Field)
+	}
+	if got := strings.Join(fields, ","); got != "description,page_count,subjects,cover" {
+		t.Errorf("proposal: got %s", got)
+	}
+
+	if got := strings.Join(result.Applied, ","); got != "page_count,cover" {
+		t.Errorf("applied: got %s", got)
+	}
+
+	if len(books.updates) != 1 || books.updates[0].Description != nil || *books.updates[0].PageCount != 320 {
+		t.Errorf("updates: got %+v", books.updates)
+	}
+	if len(books.covers) != 1 || string(books.covers[0]) != "cover" {
+		t.Errorf("covers: got %q", books.covers)
+	}
+	if result.Book.PageCount != 320 {
+		t.Errorf("book: got page count %d", result.Book.PageCount)
+	}
+}
+
+func TestEnrichProposalOnly(t *testing.T) {
+	service, books := newEnrichService(t, catalogHandler(`{"number_of_pages": 320}`))
+
+	result, err := service.Enrich(context.Background(), 1, domain.EnrichInput{})
+	if err != nil {
+		t.Fatalf("enrich: %v", err)
+	}
+
+	if len(result.Proposal) != 1 || len(result.Applied) != 0 || len(books.updates) != 0 {
+		t.Errorf("got %+v, updates %+v", result, books.updates)
+	}
+
+	_, err = service.Enrich(context.Background(), 1, domain.EnrichInput{Accept: []string{"description"}})
+	if !errors.Is(err, domain.ErrorUnknownEnrichField) {
+		t.Errorf("got %v, want %v", err, domain.ErrorUnknownEnrichField)
+	}
+}
+
+func TestEnrichNotFound(t *testing.T) {
+	service, books := newEnrichService(t, http.NotFound)
+
+	_, err := service.Enrich(context.Background(), 1, domain.EnrichInput{Accept: []string{"description"}})
+	if !errors.Is(err, domain.ErrorMetadataNotFound) {
+		t.Errorf("got %v, want %v", err, domain.ErrorMetadataNotFound)
+	}
+	if len(books.updates) != 0 {
+		t.Errorf("updates: got %+v", books.updates)
+	}
+}
+
+func TestEnrichUpstreamError(t *testing.T) {
+	service, books := newEnrichService(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusBadGateway)
+	})
+
+	_, err := service.Enrich(context.Background(), 1, domain.EnrichInput{Accept: []string{"description"}})
+	if !errors.Is(err, domain.ErrorMetadataUnavailable) {
+		t.Errorf("got %v, want %v", err, domain.ErrorMetadataUnavailable)
+	}
+	if len(books.updates) != 0 {
+		t.Errorf("updates: got %+v", books.updates)
+	}
+}
+
+func TestEnrichValidates(t *testing.T) {
+	service, books := newEnrichService(t, catalogHandler(
+		`{"subjects": ["`+strings.Repeat("x", 300)+`"]}`))
+
+	_, err := service.Enrich(context.Background(), 1, domain.EnrichInput{Accept: []string{"subjects"}})
+	if !errors.Is(err, domain.ErrorValidation) {
+		t.Errorf("got %v, want %v", err, domain.ErrorValidation)
+	}
+	if len(books.updates) != 0 {
+		t.Errorf("updates: got %+v", books.updates)
+	}
+}
+
+func TestEnrichWithoutISBN(t *testing.T) {
+	service, books := newEnrichService(t, http.NotFound)
+	books.book.ISBN = ""
+
+	_, err := service.Enrich(context.Background(), 1, domain.EnrichInput{})
+	if !errors.Is(err, domain.ErrorBookWithoutISBN) {
+		t.Errorf("got %v, want %v", err, domain.ErrorBookWithoutISBN)
+	}
+}
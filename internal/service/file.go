@@ -0,0 +1,396 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/cite"
+	"book_api/pkg/ebook"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var bookFileTypes = map[string]string{
+	domain.BookFileEPUB: "application/epub+zip",
+	domain.BookFilePDF:  "application/pdf",
+}
+
+type BookFileRepository interface {
+	Create(ctx context.Context, file domain.BookFile) (int64, error)
+	GetById(ctx context.Context, bookId, id int64) (domain.BookFile, error)
+	GetByBook(ctx context.Context, bookId int64) ([]domain.BookFile, error)
+	GetByBooks(ctx context.Context, bookIds []int64) (map[int64][]domain.BookFile, error)
+	Delete(ctx context.Context, bookId, id int64) error
+}
+
+// BookEditor is the part of the book service files go through, so that
+// prefilled fields are validated and covers get their thumbnails.
+type BookEditor interface {
+	GetById(ctx context.Context, id int64) (domain.Book, error)
+	Update(ctx context.Context, id int64, input domain.UpdateBookInput) error
+	UploadCover(ctx context.Context, id int64, data []byte) (domain.Book, error)
+}
+
+type FileOptions struct {
+	MaxSize int64
+}
+
+type FileService struct {
+	repo  BookFileRepository
+	books BookEditor
+	store BlobStore
+	opts  FileOptions
+}
+
+func NewFileService(repo BookFileRepository, books BookEditor, store BlobStore, opts FileOptions) *FileService {
+	return &FileService{
+		repo:  repo,
+		books: books,
+		store: store,
+		opts:  opts,
+	}
+}
+
+// Upload stores an EPUB or PDF file of the book and compares the metadata
+// embedded in it with the book record. With prefill set, book fields that
+// are still empty, the cover included, are taken from the file.
+func (s FileService) Upload(ctx context.Context, bookId int64, name string, r io.ReaderAt, size int64, prefill bool) (domain.BookFileUpload, error) {
+	book, err := s.books.GetById(ctx, bookId)
+	if err != nil {
+		return domain.BookFileUpload{}, err
+	}
+
+	if size > s.opts.MaxSize {
+		return domain.BookFileUpload{}, domain.ErrorBookFileTooLarge
+	}
+
+	format, parsed, err := parseBookFile(r, size)
+	if err != nil {
+		return domain.BookFileUpload{}, err
+	}
+
+	file := domain.BookFile{
+		BookID:      bookId,
+		Format:      format,
+		Name:        bookFileName(name, bookId, format),
+		ContentType: bookFileTypes[format],
+		Size:        size,
+		Key:         fmt.Sprintf("ebooks/%d/%d.%s", bookId, time.Now().UnixNano(), format),
+		Metadata:    fileMetadata(parsed),
+		CreatedAt:   time.Now(),
+	}
+
+	hash := sha256.New()
+	err = s.store.Put(ctx, file.Key, io.TeeReader(io.NewSectionReader(r, 0, size), hash), size, file.ContentType)
+	if err != nil {
+		return domain.BookFileUpload{}, err
+	}
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	file.ID, err = s.repo.Create(ctx, file)
+	if err != nil {
+		s.store.Delete(ctx, file.Key)
+		return domain.BookFileUpload{}, err
+	}
+
+	upload := domain.BookFileUpload{
+		File:      file,
+		Prefilled: make([]string, 0),
+	}
+
+	if prefill {
+		upload.Prefilled, err = s.prefill(ctx, book, file.Metadata, parsed.Cover)
+		if err != nil {
+			return upload, err
+		}
+
+		book, err = s.books.GetById(ctx, bookId)
+		if err != nil {
+			return upload, err
+		}
+	}
+
+	upload.Book = book
+	upload.Diff = diffBook(book, file.Metadata)
+
+	return upload, nil
+}
+
+func (s FileService) prefill(ctx context.Context, book domain.Book, meta domain.FileMetadata, cover []byte) ([]string, error) {
+	var input domain.UpdateBookInput
+	filled := make([]string, 0)
+
+	if book.ISBN == "" && meta.ISBN != "" {
+		input.ISBN = &meta.ISBN
+		filled = append(filled, "isbn")
+	}
+
+	if book.Description == "" && meta.Description != "" {
+		input.Description = &meta.Description
+		filled = append(filled, "description")
+	}
+
+	if book.PublishDate.IsZero() && meta.PublishDate != nil {
+		input.PublishDate = meta.PublishDate
+		filled = append(filled, "publish_date")
+	}
+
+	if len(filled) > 0 {
+		if err := s.books.Update(ctx, book.ID, input); err != nil {
+			return nil, err
+		}
+	}
+
+	if book.Cover == "" && len(cover) > 0 {
+		_, err := s.books.UploadCover(ctx, book.ID, cover)
+		switch {
+		case err == nil:
+			filled = append(filled, "cover")
+		case errors.Is(err, domain.ErrorCoverTooLarge), errors.Is(err, domain.ErrorUnsupportedCoverType):
+			// A cover we can not use is no reason to reject the file.
+		default:
+			return nil, err
+		}
+	}
+
+	return filled, nil
+}
+
+func (s FileService) GetByBook(ctx context.Context, bookId int64) ([]domain.BookFile, error) {
+	if _, err := s.books.GetById(ctx, bookId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByBook(ctx, bookId)
+}
+
+// GetByBooks returns the files of the given books keyed by book id; books
+// without files are left out.
+func (s FileService) GetByBooks(ctx context.Context, bookIds []int64) (map[int64][]domain.BookFile, error) {
+	return s.repo.GetByBooks(ctx, bookIds)
+}
+
+// Open returns the file along with a reader over its content that fetches
+// only the ranges actually read.
+func (s FileService) Open(ctx context.Context, bookId, id int64) (domain.BookFile, io.ReadSeekCloser, error) {
+	file, err := s.repo.GetById(ctx, bookId, id)
+	if err != nil {
+		return file, nil, err
+	}
+
+	return file, &blobReader{ctx: ctx, store: s.store, key: file.Key, size: file.Size}, nil
+}
+
+func (s FileService) Delete(ctx context.Context, bookId, id int64) error {
+	file, err := s.repo.GetById(ctx, bookId, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, bookId, id); err != nil {
+		return err
+	}
+
+	s.store.Delete(ctx, file.Key)
+
+	return nil
+}
+
+// parseBookFile tells EPUB from PDF by content and reads the metadata.
+func parseBookFile(r io.ReaderAt, size int64) (string, ebook.Metadata, error) {
+	head := make([]byte, 512)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", ebook.Metadata{}, err
+	}
+
+	var (
+		format string
+		meta   ebook.Metadata
+	)
+	switch http.DetectContentType(head[:n]) {
+	case "application/zip":
+		format = domain.BookFileEPUB
+		meta, err = ebook.ParseEPUB(r, size)
+	case "application/pdf":
+		format = domain.BookFilePDF
+		meta, err = ebook.ParsePDF(r, size)
+	default:
+		return "", meta, domain.ErrorUnsupportedBookFileType
+	}
+
+	if errors.Is(err, ebook.ErrorInvalidFile) {
+		return "", meta, fmt.Errorf("%w: %s", domain.ErrorInvalidBookFile, err)
+	}
+
+	return format, meta, err
+}
+
+func fileMetadata(parsed ebook.Metadata) domain.FileMetadata {
+	meta := domain.FileMetadata{
+		Title:       parsed.Title,
+		Authors:     parsed.Creators,
+		Language:    parsed.Language,
+		Publisher:   parsed.Publisher,
+		Description: parsed.Description,
+		Subjects:    parsed.Subjects,
+		HasCover:    len(parsed.Cover) > 0,
+	}
+
+	for _, id := range parsed.Identifiers {
+		value := id.Value
+		if id.Scheme != "" {
+			value = id.Scheme + ":" + id.Value
+		}
+		meta.Identifiers = append(meta.Identifiers, value)
+
+		// Identifiers explicitly marked as ISBN win over values that just
+		// happen to pass the checksum.
+		if isbn, err := domain.ISBN13(id.Value); err == nil && (meta.ISBN == "" || id.Scheme == "isbn") {
+			meta.ISBN = isbn
+		}
+	}
+
+	if !parsed.Published.IsZero() {
+		published := parsed.Published
+		meta.PublishDate = &published
+	}
+
+	return meta
+}
+
+// diffBook lists the fields the file has a value for that does not match
+// the book.
+func diffBook(book domain.Book, meta domain.FileMetadata) []domain.FieldDiff {
+	diff := make([]domain.FieldDiff, 0)
+
+	if meta.Title != "" && !strings.EqualFold(strings.TrimSpace(book.Title), meta.Title) {
+		diff = append(diff, domain.FieldDiff{Field: "title", Book: book.Title, File: meta.Title})
+	}
+
+	if len(meta.Authors) > 0 && !sameAuthors(cite.ParseAuthors(book.Author), meta.Authors) {
+		diff = append(diff, domain.FieldDiff{Field: "author", Book: book.Author, File: strings.Join(meta.Authors, "; ")})
+	}
+
+	if meta.ISBN != "" {
+		isbn, err := domain.ISBN13(book.ISBN)
+		if err != nil || isbn != meta.ISBN {
+			diff = append(diff, domain.FieldDiff{Field: "isbn", Book: book.ISBN, File: meta.ISBN})
+		}
+	}
+
+	if meta.PublishDate != nil && book.PublishDate.Format("2006-01-02") != meta.PublishDate.Format("2006-01-02") {
+		var published interface{}
+		if !book.PublishDate.IsZero() {
+			published = book.PublishDate
+		}
+		diff = append(diff, domain.FieldDiff{Field: "publish_date", Book: published, File: *meta.PublishDate})
+	}
+
+	return diff
+}
+
+func sameAuthors(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bookFileName keeps the base name of the uploaded file, making sure it
+// carries the extension of the detected format.
+func bookFileName(name string, bookId int64, format string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fmt.Sprintf("book-%d", bookId)
+	}
+
+	if !strings.HasSuffix(strings.ToLower(name), "."+format) {
+		name += "." + format
+	}
+
+	return name
+}
+
+// blobReader reads an object through ranged requests, so seeking to a
+// range does not download what comes before it.
+type blobReader struct {
+	ctx    context.Context
+	store  BlobStore
+	key    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+
+	if b.body == nil {
+		body, err := b.store.GetRange(b.ctx, b.key, b.offset, b.size-b.offset)
+		if err != nil {
+			return 0, err
+		}
+		b.body = body
+	}
+
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+	if err == io.EOF && b.offset < b.size {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
+
+func (b *blobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += b.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != b.offset {
+		b.Close()
+		b.offset = offset
+	}
+
+	return offset, nil
+}
+
+func (b *blobReader) Close() error {
+	if b.body == nil {
+		return nil
+	}
+
+	err := b.body.Close()
+	b.body = nil
+
+	return err
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"archive/zip"
+	"book_api/internal/domain"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseBookFileInvalid(t *testing.T) {
+	var epub bytes.Buffer
+	zw := zip.NewWriter(&epub)
+	if _, err := zw.Create("OEBPS/content.opf"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"EPUB without container.xml", epub.Bytes(), domain.ErrorInvalidBookFile},
+		{"PDF with a truncated trailer", []byte("%PDF-1.4\ntrailer\n<< /Info 1"), domain.ErrorInvalidBookFile},
+		{"neither EPUB nor PDF", []byte("plain text"), domain.ErrorUnsupportedBookFileType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(tt.data)
+			if _, _, err := parseBookFile(r, r.Size()); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
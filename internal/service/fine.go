@@ -0,0 +1,152 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/notify"
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+type FineRepository interface {
+	Accrue(ctx context.Context, now time.Time, dailyAmount, maxAmount int64) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Fine, error)
+	GetByUser(ctx context.Context, userId int64, status string) ([]domain.Fine, error)
+	GetAll(ctx context.Context, status string) ([]domain.Fine, error)
+	Resolve(ctx context.Context, id int64, status string, by int64, at time.Time, note string) error
+}
+
+type OverdueLoanRepository interface {
+	GetOverdueForReminder(ctx context.Context, now, remindedBefore time.Time) ([]domain.OverdueLoan, error)
+	MarkReminded(ctx context.Context, id int64, at time.Time) error
+}
+
+type Notifier interface {
+	Send(ctx context.Context, msg notify.Message) error
+}
+
+type FineOptions struct {
+	DailyAmount      int64
+	MaxAmount        int64
+	Currency         string
+	ReminderInterval time.Duration
+}
+
+type FineService struct {
+	repo     FineRepository
+	loans    OverdueLoanRepository
+	notifier Notifier
+	options  FineOptions
+}
+
+func NewFineService(repo FineRepository, loans OverdueLoanRepository, notifier Notifier, options FineOptions) *FineService {
+	return &FineService{
+		repo:     repo,
+		loans:    loans,
+		notifier: notifier,
+		options:  options,
+	}
+}
+
+// AccrueFines brings open fines of overdue loans up to date.
+func (s FineService) AccrueFines(ctx context.Context) error {
+	changed, err := s.repo.Accrue(ctx, time.Now(), s.options.DailyAmount, s.options.MaxAmount)
+	if err != nil {
+		return err
+	}
+
+	if changed > 0 {
+		log.WithFields(log.Fields{
+			"job":     "accrueFines",
+			"changed": changed,
+		}).Info()
+	}
+
+	return nil
+}
+
+// SendReminders notifies borrowers of overdue loans at most once per reminder interval.
+// A failed delivery is logged and retried on the next run.
+func (s FineService) SendReminders(ctx context.Context) error {
+	now := time.Now()
+
+	loans, err := s.loans.GetOverdueForReminder(ctx, now, now.Add(-s.options.ReminderInterval))
+	if err != nil {
+		return err
+	}
+
+	for _, loan := range loans {
+		days := int(now.Sub(loan.DueAt).Hours() / 24)
+
+		err := s.notifier.Send(ctx, notify.Message{
+			Kind:    "overdue_loan",
+			UserID:  loan.UserID,
+			Email:   loan.UserEmail,
+			Name:    loan.UserName,
+			Subject: fmt.Sprintf("Overdue: %s", loan.BookTitle),
+			Body: fmt.Sprintf("Dear %s,\n\n\"%s\" was due on %s and is %d day(s) overdue. "+
+				"Please return it as soon as possible to avoid further fines.\n",
+				loan.UserName, loan.BookTitle, loan.DueAt.Format("2006-01-02"), days),
+			Data: map[string]interface{}{
+				"loan_id": loan.ID,
+				"copy_id": loan.CopyID,
+				"book_id": loan.BookID,
+				"due_at":  loan.DueAt,
+			},
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"job":     "sendReminders",
+				"loan_id": loan.ID,
+				"problem": "send notification error",
+			}).Error(err)
+			continue
+		}
+
+		if err := s.loans.MarkReminded(ctx, loan.ID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s FineService) GetUserFines(ctx context.Context, userId int64, status string) ([]domain.Fine, error) {
+	fines, err := s.repo.GetByUser(ctx, userId, status)
+
+	return s.withCurrency(fines), err
+}
+
+func (s FineService) GetAll(ctx context.Context, status string) ([]domain.Fine, error) {
+	fines, err := s.repo.GetAll(ctx, status)
+
+	return s.withCurrency(fines), err
+}
+
+func (s FineService) Pay(ctx context.Context, librarianId, id int64, note string) (domain.Fine, error) {
+	return s.resolve(ctx, librarianId, id, domain.FinePaid, note)
+}
+
+func (s FineService) Waive(ctx context.Context, librarianId, id int64, note string) (domain.Fine, error) {
+	return s.resolve(ctx, librarianId, id, domain.FineWaived, note)
+}
+
+func (s FineService) resolve(ctx context.Context, librarianId, id int64, status, note string) (domain.Fine, error) {
+	if err := s.repo.Resolve(ctx, id, status, librarianId, time.Now(), note); err != nil {
+		return domain.Fine{}, err
+	}
+
+	fine, err := s.repo.GetById(ctx, id)
+	fine.Currency = s.options.Currency
+
+	return fine, err
+}
+
+func (s FineService) withCurrency(fines []domain.Fine) []domain.Fine {
+	for i := range fines {
+		fines[i].Currency = s.options.Currency
+	}
+
+	return fines
+}
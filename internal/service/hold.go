@@ -0,0 +1,81 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"time"
+)
+
+type HoldRepository interface {
+	Create(ctx context.Context, hold domain.Hold) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Hold, error)
+	GetByUser(ctx context.Context, userId int64, onlyActive bool) ([]domain.Hold, error)
+	GetQueue(ctx context.Context, bookId int64) ([]domain.Hold, error)
+	Cancel(ctx context.Context, id int64, now, pickupDeadline time.Time) error
+	Expire(ctx context.Context, now, pickupDeadline time.Time) (int, error)
+}
+
+type HoldService struct {
+	repo         HoldRepository
+	pickupPeriod time.Duration
+}
+
+func NewHoldService(repo HoldRepository, pickupPeriod time.Duration) *HoldService {
+	return &HoldService{
+		repo:         repo,
+		pickupPeriod: pickupPeriod,
+	}
+}
+
+func (s HoldService) Place(ctx context.Context, userId, bookId int64, input domain.PlaceHoldInput) (domain.Hold, error) {
+	id, err := s.repo.Create(ctx, domain.Hold{
+		BookID:         bookId,
+		UserID:         userId,
+		PickupBranchID: input.PickupBranchID,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	return s.repo.GetById(ctx, id)
+}
+
+// GetById returns the hold with its current queue position. Holds of other users are not visible.
+func (s HoldService) GetById(ctx context.Context, userId, id int64) (domain.Hold, error) {
+	hold, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.UserID != userId {
+		return domain.Hold{}, domain.ErrorHoldNotFound
+	}
+
+	return hold, nil
+}
+
+func (s HoldService) GetUserHolds(ctx context.Context, userId int64, onlyActive bool) ([]domain.Hold, error) {
+	return s.repo.GetByUser(ctx, userId, onlyActive)
+}
+
+func (s HoldService) GetQueue(ctx context.Context, bookId int64) ([]domain.Hold, error) {
+	return s.repo.GetQueue(ctx, bookId)
+}
+
+func (s HoldService) Cancel(ctx context.Context, userId, id int64) error {
+	if _, err := s.GetById(ctx, userId, id); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	return s.repo.Cancel(ctx, id, now, now.Add(s.pickupPeriod))
+}
+
+// ExpireUnclaimed closes ready holds that were not picked up in time.
+func (s HoldService) ExpireUnclaimed(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	return s.repo.Expire(ctx, now, now.Add(s.pickupPeriod))
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/marc"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	importChunkSize  = 500
+	maxNDJSONLineLen = 1 << 20
+)
+
+var importFields = []string{"title", "author", "isbn", "publish_date", "series_id", "volume"}
+
+var publishDateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01", "2006"}
+
+// importRow holds the raw values of one input record keyed by book field.
+type importRow struct {
+	line   int
+	values map[string]string
+	err    error
+}
+
+type importedBook struct {
+	line int
+	book domain.Book
+}
+
+// Import loads books from CSV, NDJSON or MARC. Every row is validated and rows
+// with an ISBN already in the catalog update that book instead of creating
+// a new one. Valid rows are written in chunks, each in its own transaction,
+// and any row that could not be imported is listed in the report. A chunk
+// the database rejects is retried row by row so that the error is reported
+// against the row that caused it.
+func (s BookService) Import(ctx context.Context, r io.Reader, opts domain.ImportOptions) (domain.ImportReport, error) {
+	report := domain.ImportReport{
+		DryRun: opts.DryRun,
+		Errors: make([]domain.ImportRowError, 0),
+	}
+
+	var rows []importRow
+	var err error
+	switch opts.Format {
+	case domain.ImportFormatCSV:
+		rows, err = readCSVRows(r, opts)
+	case domain.ImportFormatNDJSON:
+		rows, err = readNDJSONRows(r, opts)
+	case domain.ImportFormatMARC:
+		rows, err = readMARCRows(marc.NewReader(r))
+	case domain.ImportFormatMARCXML:
+		rows, err = readMARCRows(marc.NewXMLReader(r))
+	default:
+		return report, domain.ErrorUnsupportedImportFormat
+	}
+	if err != nil {
+		return report, err
+	}
+
+	report.Total = len(rows)
+
+	books := make([]importedBook, 0, len(rows))
+	seen := make(map[string]int)
+	for _, row := range rows {
+		if row.err != nil {
+			report.Errors = append(report.Errors, domain.ImportRowError{Line: row.line, Error: row.err.Error()})
+			report.Failed++
+			continue
+		}
+
+		book, rowErrors := parseImportRow(row)
+		if book.ISBN != "" {
+			if first, ok := seen[book.ISBN]; ok {
+				rowErrors = append(rowErrors, domain.ImportRowError{
+					Line:  row.line,
+					Field: "isbn",
+					Error: fmt.Sprintf("duplicate isbn, first seen on line %d", first),
+				})
+			} else {
+				seen[book.ISBN] = row.line
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			report.Failed++
+			continue
+		}
+
+		books = append(books, importedBook{line: row.line, book: book})
+	}
+
+	if opts.DryRun {
+		return s.dryRunImport(ctx, books, report)
+	}
+
+	for start := 0; start < len(books); start += importChunkSize {
+		end := start + importChunkSize
+		if end > len(books) {
+			end = len(books)
+		}
+
+		chunk := make([]domain.Book, 0, end-start)
+		for _, b := range books[start:end] {
+			chunk = append(chunk, b.book)
+		}
+
+		created, updated, err := s.repo.Import(ctx, chunk)
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+
+			if err := s.importRows(ctx, books[start:end], &report); err != nil {
+				return report, err
+			}
+			continue
+		}
+
+		report.Created += created
+		report.Updated += updated
+	}
+
+	return report, nil
+}
+
+// importRows writes the books one at a time, reporting each failing row.
+func (s BookService) importRows(ctx context.Context, books []importedBook, report *domain.ImportReport) error {
+	for _, b := range books {
+		created, updated, err := s.repo.Import(ctx, []domain.Book{b.book})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			report.Errors = append(report.Errors, domain.ImportRowError{Line: b.line, Error: importErrorMessage(b, err)})
+			report.Failed++
+			continue
+		}
+
+		report.Created += created
+		report.Updated += updated
+	}
+
+	return nil
+}
+
+// importErrorMessage is what the report says about a row the repository
+// rejected. Errors other than the known domain ones are logged and not
+// passed on, since they carry database details.
+func importErrorMessage(b importedBook, err error) string {
+	if errors.Is(err, domain.ErrorSeriesNotFound) {
+		return err.Error()
+	}
+
+	log.WithFields(log.Fields{
+		"line":    b.line,
+		"problem": "import row error",
+	}).Error(err)
+
+	return "the book could not be stored"
+}
+
+func (s BookService) dryRunImport(ctx context.Context, books []importedBook, report domain.ImportReport) (domain.ImportReport, error) {
+	isbns := make([]string, 0, len(books))
+	for _, b := range books {
+		if b.book.ISBN != "" {
+			isbns = append(isbns, b.book.ISBN)
+		}
+	}
+
+	existing, err := s.repo.GetExistingISBNs(ctx, isbns)
+	if err != nil {
+		return report, err
+	}
+
+	for _, b := range books {
+		if existing[b.book.ISBN] {
+			report.Updated++
+		} else {
+			report.Created++
+		}
+	}
+
+	return report, nil
+}
+
+func parseImportRow(row importRow) (domain.Book, []domain.ImportRowError) {
+	var book domain.Book
+	var rowErrors []domain.ImportRowError
+
+	fail := func(field string, err error) {
+		rowErrors = append(rowErrors, domain.ImportRowError{Line: row.line, Field: field, Error: err.Error()})
+	}
+
+	book.Title = row.values["title"]
+	book.Author = row.values["author"]
+
+	if v := row.values["isbn"]; v != "" {
+		isbn, err := domain.ISBN13(v)
+		if err != nil {
+			fail("isbn", err)
+		}
+		book.ISBN = isbn
+	}
+
+	if v := row.values["publish_date"]; v != "" {
+		date, err := parsePublishDate(v)
+		if err != nil {
+			fail("publish_date", err)
+		}
+		book.PublishDate = date
+	}
+
+	if v := row.values["series_id"]; v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			fail("series_id", errors.New("invalid integer"))
+		} else {
+			book.SeriesID = &id
+		}
+	}
+
+	if v := row.values["volume"]; v != "" {
+		volume, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			fail("volume", errors.New("invalid number"))
+		} else {
+			book.Volume = &volume
+		}
+	}
+
+	// Fields that could not even be parsed are reported once.
+	var invalid domain.ValidationError
+	if err := book.Validate(); errors.As(err, &invalid) {
+		failed := make(map[string]bool, len(rowErrors))
+		for _, e := range rowErrors {
+			failed[e.Field] = true
+		}
+
+		for _, f := range invalid.Fields {
+			if !failed[f.Field] {
+				rowErrors = append(rowErrors, domain.ImportRowError{Line: row.line, Field: f.Field, Error: f.Reason})
+			}
+		}
+	}
+
+	return book, rowErrors
+}
+
+func parsePublishDate(v string) (time.Time, error) {
+	for _, layout := range publishDateLayouts {
+		if date, err := time.Parse(layout, v); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, errors.New("invalid date, expected YYYY-MM-DD")
+}
+
+// importColumn returns the lower-cased input column holding the given field.
+func importColumn(opts domain.ImportOptions, field string) string {
+	if column, ok := opts.Columns[field]; ok {
+		return strings.ToLower(strings.TrimSpace(column))
+	}
+
+	return field
+}
+
+func readCSVRows(r io.Reader, opts domain.ImportOptions) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := make(map[string]int)
+	for _, field := range importFields {
+		if i, ok := index[importColumn(opts, field)]; ok {
+			columns[field] = i
+		}
+	}
+
+	for _, field := range []string{"title", "author"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("%w: %s", domain.ErrorMissingImportColumn, importColumn(opts, field))
+		}
+	}
+
+	rows := make([]importRow, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, importRow{line: parseErr.StartLine, err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line, values: make(map[string]string, len(columns))}
+		for field, i := range columns {
+			if i < len(record) {
+				row.values[field] = strings.TrimSpace(record[i])
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func readNDJSONRows(r io.Reader, opts domain.ImportOptions) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineLen)
+
+	rows := make([]importRow, 0)
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var object map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&object); err != nil {
+			rows = append(rows, importRow{line: line, err: errors.New("invalid json object")})
+			continue
+		}
+
+		keys := make(map[string]interface{}, len(object))
+		for key, value := range object {
+			keys[strings.ToLower(key)] = value
+		}
+
+		row := importRow{line: line, values: make(map[string]string)}
+		for _, field := range importFields {
+			switch value := keys[importColumn(opts, field)].(type) {
+			case nil:
+			case string:
+				row.values[field] = strings.TrimSpace(value)
+			case json.Number:
+				row.values[field] = value.String()
+			default:
+				row.err = fmt.Errorf("%s: unsupported value", field)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, scanner.Err()
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/label"
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// CopyLabel renders the printable label of a copy as "png" or "svg".
+func (s CirculationService) CopyLabel(ctx context.Context, id int64, format string) ([]byte, error) {
+	l, err := s.copyLabel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		err = label.PNG(&buf, l)
+	case "svg":
+		err = label.SVG(&buf, l)
+	default:
+		err = fmt.Errorf("unknown label format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// LabelSheet renders the labels of the copies, in the given order, as
+// printable PDF sheets.
+func (s CirculationService) LabelSheet(ctx context.Context, ids []int64) ([]byte, error) {
+	labels := make([]label.Label, 0, len(ids))
+	for _, id := range ids {
+		l, err := s.copyLabel(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, l)
+	}
+
+	var buf bytes.Buffer
+	if err := label.PDF(&buf, labels); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s CirculationService) copyLabel(ctx context.Context, id int64) (label.Label, error) {
+	c, err := s.copies.GetById(ctx, id)
+	if err != nil {
+		return label.Label{}, err
+	}
+
+	book, err := s.books.GetById(ctx, c.BookID)
+	if err != nil {
+		return label.Label{}, err
+	}
+
+	l := label.Label{
+		Title:   book.Title,
+		Author:  book.Author,
+		Barcode: c.Barcode,
+	}
+
+	// Books without a valid ISBN simply get no EAN-13 barcode.
+	if isbn, err := domain.ISBN13(book.ISBN); err == nil {
+		l.ISBN = isbn
+	}
+
+	if s.options.BookURL != "" {
+		l.URL = fmt.Sprintf(s.options.BookURL, book.ID)
+	}
+
+	return l, nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/marc"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const marcLeader = "00000nam a2200000 i 4500"
+
+var marcYear = regexp.MustCompile(`\d{4}`)
+
+type marcReader interface {
+	Read() (marc.Record, error)
+}
+
+// ExportMARC writes the books matching the filter as ISO 2709 or MARCXML
+// records.
+func (s BookService) ExportMARC(ctx context.Context, w io.Writer, filter domain.BookFilter, format string) error {
+	switch format {
+	case domain.ImportFormatMARC:
+		mw := marc.NewWriter(w)
+
+		return s.repo.Export(ctx, filter, func(book domain.Book) error {
+			return mw.Write(bookToMARC(book))
+		})
+	case domain.ImportFormatMARCXML:
+		mw := marc.NewXMLWriter(w)
+
+		err := s.repo.Export(ctx, filter, func(book domain.Book) error {
+			return mw.Write(bookToMARC(book))
+		})
+		if err != nil {
+			return err
+		}
+
+		return mw.Close()
+	default:
+		return fmt.Errorf("unknown marc format %q", format)
+	}
+}
+
+// readMARCRows maps every record to import values, numbering rows by record.
+// A malformed record ends the input since the stream cannot be resynchronized.
+func readMARCRows(reader marcReader) ([]importRow, error) {
+	rows := make([]importRow, 0)
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, marc.ErrorInvalidRecord) {
+			rows = append(rows, importRow{line: n, err: err})
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, importRow{line: n, values: marcImportValues(record)})
+	}
+
+	return rows, nil
+}
+
+// marcImportValues maps 245 to the title, 100 to the author, 020 to the ISBN
+// and 264/260 (or 008) to the publication year.
+func marcImportValues(record marc.Record) map[string]string {
+	values := make(map[string]string)
+
+	if fields := record.Fields("245"); len(fields) > 0 {
+		title := trimISBD(fields[0].Subfield('a'))
+		if subtitle := trimISBD(fields[0].Subfield('b')); subtitle != "" {
+			title += ": " + subtitle
+		}
+		values["title"] = title
+	}
+
+	for _, tag := range []string{"100", "110", "700"} {
+		if fields := record.Fields(tag); len(fields) > 0 {
+			values["author"] = trimISBD(fields[0].Subfield('a'))
+			break
+		}
+	}
+
+	for _, f := range record.Fields("020") {
+		if isbn := strings.Fields(f.Subfield('a')); len(isbn) > 0 {
+			values["isbn"] = isbn[0]
+			break
+		}
+	}
+
+	values["publish_date"] = marcPublishYear(record)
+
+	return values
+}
+
+func marcPublishYear(record marc.Record) string {
+	for _, f := range record.Fields("264") {
+		if f.Ind2 == '1' {
+			if year := marcYear.FindString(f.Subfield('c')); year != "" {
+				return year
+			}
+		}
+	}
+
+	for _, f := range record.Fields("260") {
+		if year := marcYear.FindString(f.Subfield('c')); year != "" {
+			return year
+		}
+	}
+
+	if fixed := record.ControlField("008"); len(fixed) >= 11 {
+		if _, err := strconv.Atoi(fixed[7:11]); err == nil {
+			return fixed[7:11]
+		}
+	}
+
+	return ""
+}
+
+// trimISBD strips the trailing punctuation cataloguers put before the next subfield.
+func trimISBD(s string) string {
+	return strings.TrimRight(strings.TrimSpace(s), " /:;,=")
+}
+
+func bookToMARC(book domain.Book) marc.Record {
+	record := marc.Record{
+		Leader: marcLeader,
+		ControlFields: []marc.ControlField{
+			{Tag: "001", Value: strconv.FormatInt(book.ID, 10)},
+			{Tag: "008", Value: marcFixedData(book)},
+		},
+	}
+
+	if book.ISBN != "" {
+		record.DataFields = append(record.DataFields, marc.DataField{
+			Tag: "020", Ind1: ' ', Ind2: ' ',
+			Subfields: []marc.Subfield{{Code: 'a', Value: book.ISBN}},
+		})
+	}
+
+	record.DataFields = append(record.DataFields,
+		marc.DataField{
+			Tag: "100", Ind1: '1', Ind2: ' ',
+			Subfields: []marc.Subfield{{Code: 'a', Value: book.Author}},
+		},
+		marc.DataField{
+			Tag: "245", Ind1: '1', Ind2: '0',
+			Subfields: []marc.Subfield{{Code: 'a', Value: book.Title}},
+		},
+	)
+
+	if !book.PublishDate.IsZero() {
+		record.DataFields = append(record.DataFields, marc.DataField{
+			Tag: "264", Ind1: ' ', Ind2: '1',
+			Subfields: []marc.Subfield{{Code: 'c', Value: book.PublishDate.Format("2006")}},
+		})
+	}
+
+	return record
+}
+
+// marcFixedData builds the 40 character 008 field with the entry date and
+// the publication year as date 1.
+func marcFixedData(book domain.Book) string {
+	fixed := []byte(strings.Repeat(" ", 40))
+	copy(fixed[0:6], time.Now().Format("060102"))
+
+	if book.PublishDate.IsZero() {
+		copy(fixed[6:11], "nuuuu")
+	} else {
+		copy(fixed[6:11], "s"+book.PublishDate.Format("2006"))
+	}
+
+	copy(fixed[15:18], "xx ")
+	copy(fixed[35:38], "und")
+	fixed[39] = 'd'
+
+	return string(fixed)
+}
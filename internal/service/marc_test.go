@@ -0,0 +1,83 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/marc"
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMARCBookRoundTrip(t *testing.T) {
+	books := []domain.Book{
+		{
+			ID:          1,
+			Title:       "War and Peace",
+			Author:      "Leo Tolstoy",
+			ISBN:        "9780306406157",
+			PublishDate: time.Date(1869, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{ID: 2, Title: "Untitled", Author: "Anonymous"},
+	}
+	want := []map[string]string{
+		{"title": "War and Peace", "author": "Leo Tolstoy", "isbn": "9780306406157", "publish_date": "1869"},
+		{"title": "Untitled", "author": "Anonymous", "publish_date": ""},
+	}
+
+	formats := map[string]struct {
+		write func(*bytes.Buffer) error
+		read  func(*bytes.Buffer) marcReader
+	}{
+		domain.ImportFormatMARC: {
+			write: func(buf *bytes.Buffer) error {
+				w := marc.NewWriter(buf)
+				for _, book := range books {
+					if err := w.Write(bookToMARC(book)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			read: func(buf *bytes.Buffer) marcReader { return marc.NewReader(buf) },
+		},
+		domain.ImportFormatMARCXML: {
+			write: func(buf *bytes.Buffer) error {
+				w := marc.NewXMLWriter(buf)
+				for _, book := range books {
+					if err := w.Write(bookToMARC(book)); err != nil {
+						return err
+					}
+				}
+				return w.Close()
+			},
+			read: func(buf *bytes.Buffer) marcReader { return marc.NewXMLReader(buf) },
+		},
+	}
+
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := format.write(&buf); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+
+			rows, err := readMARCRows(format.read(&buf))
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if len(rows) != len(want) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(want))
+			}
+
+			for i, row := range rows {
+				if row.err != nil {
+					t.Errorf("row %d: %v", row.line, row.err)
+				}
+				if !reflect.DeepEqual(row.values, want[i]) {
+					t.Errorf("row %d: got %v, want %v", row.line, row.values, want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	oaiPageSize      = 100
+	oaiDayDatestamp  = "2006-01-02"
+	oaiSeriesSetSpec = "series:"
+)
+
+var oaiDublinCore = domain.OAIMetadataFormat{
+	Prefix:    "oai_dc",
+	Schema:    "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+	Namespace: "http://www.openarchives.org/OAI/2.0/oai_dc/",
+}
+
+type OAIOptions struct {
+	RepositoryName       string
+	BaseURL              string
+	AdminEmail           string
+	RepositoryIdentifier string
+}
+
+type OAIService struct {
+	books   BookRepository
+	series  SeriesRepository
+	options OAIOptions
+}
+
+func NewOAIService(books BookRepository, series SeriesRepository, options OAIOptions) *OAIService {
+	return &OAIService{
+		books:   books,
+		series:  series,
+		options: options,
+	}
+}
+
+// oaiToken is the state carried between list requests. It is handed to the
+// harvester base64 encoded, so the server keeps nothing between pages.
+type oaiToken struct {
+	Prefix  string     `json:"p"`
+	From    *time.Time `json:"f,omitempty"`
+	Before  *time.Time `json:"b,omitempty"`
+	Set     string     `json:"s,omitempty"`
+	AfterID int64      `json:"a"`
+	Cursor  int        `json:"c"`
+	Total   int        `json:"t"`
+}
+
+func (s OAIService) Identify(ctx context.Context) (domain.OAIRepository, error) {
+	earliest, err := s.books.GetEarliestUpdate(ctx)
+	if err != nil {
+		return domain.OAIRepository{}, err
+	}
+
+	return domain.OAIRepository{
+		Name:              s.options.RepositoryName,
+		BaseURL:           s.options.BaseURL,
+		AdminEmail:        s.options.AdminEmail,
+		EarliestDatestamp: earliest.UTC(),
+		Identifier:        s.options.RepositoryIdentifier,
+	}, nil
+}
+
+// ListMetadataFormats returns the formats of the whole repository or, when
+// an identifier is given, of that item.
+func (s OAIService) ListMetadataFormats(ctx context.Context, identifier string) ([]domain.OAIMetadataFormat, error) {
+	if identifier != "" {
+		if _, err := s.getBook(ctx, identifier); err != nil {
+			return nil, err
+		}
+	}
+
+	return []domain.OAIMetadataFormat{oaiDublinCore}, nil
+}
+
+// ListSets exposes every series as a set.
+func (s OAIService) ListSets(ctx context.Context) ([]domain.OAISet, error) {
+	series, err := s.series.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]domain.OAISet, 0, len(series))
+	for _, item := range series {
+		sets = append(sets, domain.OAISet{
+			Spec: oaiSeriesSetSpec + strconv.FormatInt(item.ID, 10),
+			Name: item.Name,
+		})
+	}
+
+	return sets, nil
+}
+
+// List returns one page of records for ListIdentifiers and ListRecords,
+// with a resumption token when more pages follow.
+func (s OAIService) List(ctx context.Context, query domain.OAIQuery) (domain.OAIPage, error) {
+	var page domain.OAIPage
+
+	token, err := s.listToken(ctx, query)
+	if err != nil {
+		return page, err
+	}
+
+	filter, err := oaiFilter(token)
+	if err != nil {
+		return page, err
+	}
+
+	books, err := s.books.GetAfter(ctx, filter, token.AfterID, oaiPageSize+1)
+	if err != nil {
+		return page, err
+	}
+
+	if len(books) == 0 && token.Cursor == 0 {
+		return page, domain.OAIError{Code: domain.OAINoRecordsMatch, Message: "no records match the request"}
+	}
+
+	more := len(books) > oaiPageSize
+	if more {
+		books = books[:oaiPageSize]
+	}
+
+	page.Cursor = token.Cursor
+	page.CompleteListSize = token.Total
+	page.Records = make([]domain.OAIRecord, 0, len(books))
+	for _, book := range books {
+		page.Records = append(page.Records, s.record(book))
+	}
+
+	if more {
+		token.AfterID = books[len(books)-1].ID
+		token.Cursor += len(books)
+
+		data, err := json.Marshal(token)
+		if err != nil {
+			return page, err
+		}
+		page.ResumptionToken = base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	return page, nil
+}
+
+func (s OAIService) GetRecord(ctx context.Context, identifier, prefix string) (domain.OAIRecord, error) {
+	if prefix != oaiDublinCore.Prefix {
+		return domain.OAIRecord{}, domain.OAIError{
+			Code:    domain.OAICannotDisseminateFormat,
+			Message: fmt.Sprintf("metadata format %q is not supported", prefix),
+		}
+	}
+
+	book, err := s.getBook(ctx, identifier)
+	if err != nil {
+		return domain.OAIRecord{}, err
+	}
+
+	return s.record(book), nil
+}
+
+func (s OAIService) listToken(ctx context.Context, query domain.OAIQuery) (oaiToken, error) {
+	var token oaiToken
+
+	if query.ResumptionToken != "" {
+		data, err := base64.RawURLEncoding.DecodeString(query.ResumptionToken)
+		if err == nil {
+			err = json.Unmarshal(data, &token)
+		}
+		if err != nil || token.Prefix == "" {
+			return token, domain.OAIError{Code: domain.OAIBadResumptionToken, Message: "the resumption token is invalid"}
+		}
+
+		return token, nil
+	}
+
+	if query.MetadataPrefix != oaiDublinCore.Prefix {
+		return token, domain.OAIError{
+			Code:    domain.OAICannotDisseminateFormat,
+			Message: fmt.Sprintf("metadata format %q is not supported", query.MetadataPrefix),
+		}
+	}
+	token.Prefix = query.MetadataPrefix
+	token.Set = query.Set
+
+	from, _, err := parseOAIDatestamp(query.From)
+	if err != nil {
+		return token, err
+	}
+	until, untilDay, err := parseOAIDatestamp(query.Until)
+	if err != nil {
+		return token, err
+	}
+
+	if from != nil && until != nil && len(query.From) != len(query.Until) {
+		return token, domain.OAIError{Code: domain.OAIBadArgument, Message: "from and until must have the same granularity"}
+	}
+
+	// Datestamps are truncated to seconds, so until covers the whole second
+	// or day it names: records are harvested up to the start of the next.
+	if until != nil {
+		end := until.Add(time.Second)
+		if untilDay {
+			end = until.Add(24 * time.Hour)
+		}
+		until = &end
+	}
+
+	if from != nil && until != nil && !from.Before(*until) {
+		return token, domain.OAIError{Code: domain.OAIBadArgument, Message: "from is later than until"}
+	}
+
+	token.From = from
+	token.Before = until
+
+	filter, err := oaiFilter(token)
+	if err != nil {
+		return token, err
+	}
+
+	token.Total, err = s.books.Count(ctx, filter)
+
+	return token, err
+}
+
+func (s OAIService) getBook(ctx context.Context, identifier string) (domain.Book, error) {
+	notFound := domain.OAIError{Code: domain.OAIIdDoesNotExist, Message: fmt.Sprintf("unknown identifier %q", identifier)}
+
+	prefix := "oai:" + s.options.RepositoryIdentifier + ":book/"
+	if !strings.HasPrefix(identifier, prefix) {
+		return domain.Book{}, notFound
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(identifier, prefix), 10, 64)
+	if err != nil {
+		return domain.Book{}, notFound
+	}
+
+	book, err := s.books.GetById(ctx, id)
+	if errors.Is(err, domain.ErrorBookNotFound) {
+		return book, notFound
+	}
+
+	return book, err
+}
+
+func (s OAIService) record(book domain.Book) domain.OAIRecord {
+	record := domain.OAIRecord{
+		Identifier: fmt.Sprintf("oai:%s:book/%d", s.options.RepositoryIdentifier, book.ID),
+		Datestamp:  book.UpdatedAt.UTC(),
+		SetSpecs:   make([]string, 0),
+		Book:       book,
+	}
+
+	if book.SeriesID != nil {
+		record.SetSpecs = append(record.SetSpecs, oaiSeriesSetSpec+strconv.FormatInt(*book.SeriesID, 10))
+	}
+
+	return record
+}
+
+func oaiFilter(token oaiToken) (domain.BookFilter, error) {
+	filter := domain.BookFilter{
+		UpdatedFrom:   token.From,
+		UpdatedBefore: token.Before,
+	}
+
+	if token.Set != "" {
+		id, err := strconv.ParseInt(strings.TrimPrefix(token.Set, oaiSeriesSetSpec), 10, 64)
+		if err != nil || !strings.HasPrefix(token.Set, oaiSeriesSetSpec) {
+			return filter, domain.OAIError{Code: domain.OAINoRecordsMatch, Message: fmt.Sprintf("unknown set %q", token.Set)}
+		}
+		filter.SeriesID = &id
+	}
+
+	return filter, nil
+}
+
+// parseOAIDatestamp accepts both day and seconds granularity and reports
+// which one was used.
+func parseOAIDatestamp(v string) (*time.Time, bool, error) {
+	if v == "" {
+		return nil, false, nil
+	}
+
+	if t, err := time.Parse(domain.OAIDatestamp, v); err == nil {
+		return &t, false, nil
+	}
+
+	if t, err := time.Parse(oaiDayDatestamp, v); err == nil {
+		return &t, true, nil
+	}
+
+	return nil, false, domain.OAIError{Code: domain.OAIBadArgument, Message: fmt.Sprintf("invalid datestamp %q", v)}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/jsonpatch"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Replace sets every writable field of the book to the given one; fields
+// left empty are cleared. A non-zero version has to be the current one.
+func (s BookService) Replace(ctx context.Context, id, version int64, fields domain.BookFields) error {
+	if err := fields.Validate(); err != nil {
+		return err
+	}
+
+	input := fields.UpdateInput()
+	input.Version = version
+
+	return s.Update(ctx, id, input)
+}
+
+// Patch applies a JSON merge patch or JSON patch, as told by patchType, to
+// the writable fields of the book and returns the patched book. Patches
+// may only touch the fields BookFields has. A non-zero version has to be
+// the current one.
+func (s BookService) Patch(ctx context.Context, id, version int64, patchType string, patch []byte) (domain.Book, error) {
+	var apply func(doc, patch []byte) ([]byte, error)
+	switch patchType {
+	case domain.MergePatchType:
+		apply = jsonpatch.MergePatch
+	case domain.JSONPatchType:
+		apply = jsonpatch.Apply
+	default:
+		return domain.Book{}, fmt.Errorf("%w: %s", domain.ErrorUnsupportedPatchType, patchType)
+	}
+
+	book, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	if version != 0 && book.Version != version {
+		return domain.Book{}, domain.ErrorVersionMismatch
+	}
+
+	doc, err := json.Marshal(book.Fields())
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	patched, err := apply(doc, patch)
+	switch {
+	case errors.Is(err, jsonpatch.ErrorInvalidPatch):
+		return domain.Book{}, fmt.Errorf("%w: %s", domain.ErrorInvalidPatch, err)
+	case errors.Is(err, jsonpatch.ErrorPathNotFound):
+		return domain.Book{}, fmt.Errorf("%w: %s", domain.ErrorPatchNotApplicable, err)
+	case errors.Is(err, jsonpatch.ErrorTestFailed):
+		return domain.Book{}, fmt.Errorf("%w: %s", domain.ErrorPatchTestFailed, err)
+	case err != nil:
+		return domain.Book{}, err
+	}
+
+	var fields domain.BookFields
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fields); err != nil {
+		return domain.Book{}, fmt.Errorf("%w: %s", domain.ErrorPatchNotApplicable, err)
+	}
+
+	if err := s.Replace(ctx, id, book.Version, fields); err != nil {
+		return domain.Book{}, err
+	}
+
+	return s.GetById(ctx, id)
+}
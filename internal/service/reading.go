@@ -0,0 +1,49 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+)
+
+type ReadingRepository interface {
+	Create(ctx context.Context, session domain.ReadingSession) (int64, error)
+	GetByBook(ctx context.Context, userId, bookId int64) ([]domain.ReadingSession, error)
+	GetProgress(ctx context.Context, userId, bookId int64) (domain.ReadingProgress, error)
+	GetStats(ctx context.Context, userId int64, year int) (domain.ReadingStats, error)
+}
+
+type ReadingService struct {
+	repo  ReadingRepository
+	books BookRepository
+}
+
+func NewReadingService(repo ReadingRepository, books BookRepository) *ReadingService {
+	return &ReadingService{
+		repo:  repo,
+		books: books,
+	}
+}
+
+func (s ReadingService) LogSession(ctx context.Context, session domain.ReadingSession) (int64, error) {
+	if _, err := s.books.GetById(ctx, session.BookID); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(ctx, session)
+}
+
+func (s ReadingService) GetSessions(ctx context.Context, userId, bookId int64) ([]domain.ReadingSession, error) {
+	return s.repo.GetByBook(ctx, userId, bookId)
+}
+
+func (s ReadingService) GetProgress(ctx context.Context, userId, bookId int64) (domain.ReadingProgress, error) {
+	if _, err := s.books.GetById(ctx, bookId); err != nil {
+		return domain.ReadingProgress{}, err
+	}
+
+	return s.repo.GetProgress(ctx, userId, bookId)
+}
+
+func (s ReadingService) GetStats(ctx context.Context, userId int64, year int) (domain.ReadingStats, error) {
+	return s.repo.GetStats(ctx, userId, year)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"time"
+)
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review domain.Review) error
+	Update(ctx context.Context, review domain.Review) error
+	Delete(ctx context.Context, userId, bookId int64) error
+	Get(ctx context.Context, userId, bookId int64) (domain.Review, error)
+	GetByBook(ctx context.Context, bookId int64, limit, offset int) ([]domain.Review, int64, error)
+}
+
+type ReviewService struct {
+	repo  ReviewRepository
+	books BookRepository
+}
+
+func NewReviewService(repo ReviewRepository, books BookRepository) *ReviewService {
+	return &ReviewService{
+		repo:  repo,
+		books: books,
+	}
+}
+
+func (s ReviewService) Create(ctx context.Context, userId, bookId int64, input domain.ReviewInput) (domain.Review, error) {
+	if _, err := s.books.GetById(ctx, bookId); err != nil {
+		return domain.Review{}, err
+	}
+
+	now := time.Now()
+	review := domain.Review{
+		UserID:    userId,
+		BookID:    bookId,
+		Rating:    input.Rating,
+		Text:      input.Text,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return review, s.repo.Create(ctx, review)
+}
+
+func (s ReviewService) Update(ctx context.Context, userId, bookId int64, input domain.ReviewInput) (domain.Review, error) {
+	review := domain.Review{
+		UserID:    userId,
+		BookID:    bookId,
+		Rating:    input.Rating,
+		Text:      input.Text,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.repo.Update(ctx, review); err != nil {
+		return review, err
+	}
+
+	return s.repo.Get(ctx, userId, bookId)
+}
+
+func (s ReviewService) Delete(ctx context.Context, userId, bookId int64) error {
+	return s.repo.Delete(ctx, userId, bookId)
+}
+
+func (s ReviewService) GetByBook(ctx context.Context, bookId int64, page, perPage int) (domain.ReviewsPage, error) {
+	if _, err := s.books.GetById(ctx, bookId); err != nil {
+		return domain.ReviewsPage{}, err
+	}
+
+	reviews, total, err := s.repo.GetByBook(ctx, bookId, perPage, (page-1)*perPage)
+	if err != nil {
+		return domain.ReviewsPage{}, err
+	}
+
+	return domain.ReviewsPage{
+		Items:   reviews,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+)
+
+type SeriesRepository interface {
+	Create(ctx context.Context, series domain.Series) (int64, error)
+	GetAll(ctx context.Context) ([]domain.Series, error)
+	GetById(ctx context.Context, id int64) (domain.Series, error)
+	Update(ctx context.Context, id int64, input domain.UpdateSeriesInput) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type SeriesService struct {
+	repo  SeriesRepository
+	books BookRepository
+}
+
+func NewSeriesService(repo SeriesRepository, books BookRepository) *SeriesService {
+	return &SeriesService{
+		repo:  repo,
+		books: books,
+	}
+}
+
+func (s SeriesService) Create(ctx context.Context, series domain.Series) (int64, error) {
+	return s.repo.Create(ctx, series)
+}
+
+func (s SeriesService) GetAll(ctx context.Context) ([]domain.Series, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// GetById returns the series together with its books ordered by volume number.
+func (s SeriesService) GetById(ctx context.Context, id int64) (domain.Series, error) {
+	series, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return series, err
+	}
+
+	series.Volumes, err = s.books.GetBySeries(ctx, id)
+
+	return series, err
+}
+
+func (s SeriesService) Update(ctx context.Context, id int64, input domain.UpdateSeriesInput) error {
+	return s.repo.Update(ctx, id, input)
+}
+
+func (s SeriesService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
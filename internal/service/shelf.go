@@ -0,0 +1,178 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+type ShelfRepository interface {
+	EnsureDefaults(ctx context.Context, userId int64) error
+	Create(ctx context.Context, shelf domain.Shelf) (int64, error)
+	GetByUser(ctx context.Context, userId int64, onlyPublic bool) ([]domain.Shelf, error)
+	GetById(ctx context.Context, id int64) (domain.Shelf, error)
+	GetByShareToken(ctx context.Context, token string) (domain.Shelf, error)
+	Update(ctx context.Context, id int64, input domain.UpdateShelfInput) error
+	SetShareToken(ctx context.Context, id int64, token string) error
+	Delete(ctx context.Context, id int64) error
+	GetBooks(ctx context.Context, shelfId int64) ([]domain.ShelfBook, error)
+	AddBook(ctx context.Context, shelfId, bookId int64) error
+	RemoveBook(ctx context.Context, shelfId, bookId int64) error
+	Reorder(ctx context.Context, shelfId int64, bookIds []int64) error
+}
+
+type ShelfService struct {
+	repo  ShelfRepository
+	books BookRepository
+}
+
+func NewShelfService(repo ShelfRepository, books BookRepository) *ShelfService {
+	return &ShelfService{
+		repo:  repo,
+		books: books,
+	}
+}
+
+func (s ShelfService) GetAll(ctx context.Context, userId int64) ([]domain.Shelf, error) {
+	if err := s.repo.EnsureDefaults(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByUser(ctx, userId, false)
+}
+
+func (s ShelfService) GetPublicByUser(ctx context.Context, ownerId int64) ([]domain.Shelf, error) {
+	return s.repo.GetByUser(ctx, ownerId, true)
+}
+
+func (s ShelfService) Create(ctx context.Context, userId int64, shelf domain.Shelf) (int64, error) {
+	shelf.UserID = userId
+	shelf.Kind = domain.ShelfCustom
+	shelf.CreatedAt = time.Now()
+
+	return s.repo.Create(ctx, shelf)
+}
+
+// GetById returns the shelf with its books if it belongs to the user or is public.
+func (s ShelfService) GetById(ctx context.Context, userId, id int64) (domain.Shelf, error) {
+	shelf, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return shelf, err
+	}
+
+	if shelf.UserID != userId {
+		if !shelf.Public {
+			return domain.Shelf{}, domain.ErrorShelfNotFound
+		}
+		shelf.ShareToken = ""
+	}
+
+	shelf.Books, err = s.repo.GetBooks(ctx, id)
+
+	return shelf, err
+}
+
+// GetShared returns the shelf a share link points to regardless of its visibility.
+func (s ShelfService) GetShared(ctx context.Context, token string) (domain.Shelf, error) {
+	shelf, err := s.repo.GetByShareToken(ctx, token)
+	if err != nil {
+		return shelf, err
+	}
+
+	shelf.Books, err = s.repo.GetBooks(ctx, shelf.ID)
+
+	return shelf, err
+}
+
+func (s ShelfService) Update(ctx context.Context, userId, id int64, input domain.UpdateShelfInput) error {
+	shelf, err := s.owned(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	if input.Name != nil && shelf.Kind != domain.ShelfCustom {
+		return domain.ErrorShelfNotEditable
+	}
+
+	return s.repo.Update(ctx, id, input)
+}
+
+func (s ShelfService) Delete(ctx context.Context, userId, id int64) error {
+	shelf, err := s.owned(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	if shelf.Kind != domain.ShelfCustom {
+		return domain.ErrorShelfNotEditable
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Share generates a new read-only link token for the shelf, invalidating the previous one.
+func (s ShelfService) Share(ctx context.Context, userId, id int64) (string, error) {
+	if _, err := s.owned(ctx, userId, id); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	return token, s.repo.SetShareToken(ctx, id, token)
+}
+
+func (s ShelfService) Unshare(ctx context.Context, userId, id int64) error {
+	if _, err := s.owned(ctx, userId, id); err != nil {
+		return err
+	}
+
+	return s.repo.SetShareToken(ctx, id, "")
+}
+
+func (s ShelfService) AddBook(ctx context.Context, userId, id, bookId int64) error {
+	if _, err := s.owned(ctx, userId, id); err != nil {
+		return err
+	}
+
+	if _, err := s.books.GetById(ctx, bookId); err != nil {
+		return err
+	}
+
+	return s.repo.AddBook(ctx, id, bookId)
+}
+
+func (s ShelfService) RemoveBook(ctx context.Context, userId, id, bookId int64) error {
+	if _, err := s.owned(ctx, userId, id); err != nil {
+		return err
+	}
+
+	return s.repo.RemoveBook(ctx, id, bookId)
+}
+
+func (s ShelfService) Reorder(ctx context.Context, userId, id int64, bookIds []int64) error {
+	if _, err := s.owned(ctx, userId, id); err != nil {
+		return err
+	}
+
+	return s.repo.Reorder(ctx, id, bookIds)
+}
+
+// owned loads the shelf and hides shelves of other users behind ErrorShelfNotFound.
+func (s ShelfService) owned(ctx context.Context, userId, id int64) (domain.Shelf, error) {
+	shelf, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return shelf, err
+	}
+
+	if shelf.UserID != userId {
+		return domain.Shelf{}, domain.ErrorShelfNotFound
+	}
+
+	return shelf, nil
+}
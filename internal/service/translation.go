@@ -0,0 +1,133 @@
+package service
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"fmt"
+	"golang.org/x/text/language"
+	"strings"
+)
+
+// canonicalLanguage checks a BCP 47 language tag and returns it in its
+// canonical form, "en-US" for "en_us" for instance. The empty tag is kept.
+func canonicalLanguage(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", nil
+	}
+
+	parsed, err := language.Parse(tag)
+	if err != nil || parsed == language.Und {
+		return "", fmt.Errorf("%w: %q", domain.ErrorInvalidLanguage, tag)
+	}
+
+	return parsed.String(), nil
+}
+
+func (s BookService) GetTranslations(ctx context.Context, bookId int64) ([]domain.BookTranslation, error) {
+	if _, err := s.repo.GetById(ctx, bookId); err != nil {
+		return nil, err
+	}
+
+	translations, err := s.repo.GetTranslations(ctx, []int64{bookId})
+	if err != nil {
+		return nil, err
+	}
+
+	if translations[bookId] == nil {
+		return make([]domain.BookTranslation, 0), nil
+	}
+
+	return translations[bookId], nil
+}
+
+// SetTranslation creates or replaces the translation of the book into
+// t.Locale, returning it with the locale in canonical form.
+func (s BookService) SetTranslation(ctx context.Context, bookId int64, t domain.BookTranslation) (domain.BookTranslation, error) {
+	locale, err := canonicalLanguage(t.Locale)
+	if err != nil {
+		return t, err
+	}
+	if locale == "" {
+		return t, domain.ErrorInvalidLanguage
+	}
+	t.Locale = locale
+
+	if err := t.Validate(); err != nil {
+		return t, err
+	}
+
+	if _, err := s.repo.GetById(ctx, bookId); err != nil {
+		return t, err
+	}
+
+	return t, s.repo.SetTranslation(ctx, bookId, t)
+}
+
+func (s BookService) DeleteTranslation(ctx context.Context, bookId int64, locale string) error {
+	locale, err := canonicalLanguage(locale)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteTranslation(ctx, bookId, locale)
+}
+
+// Localize replaces the title and description of each book with the
+// translation that best matches the Accept-Language header, the original
+// language of the book taking part in the match. Books without a suitable
+// translation are left as they are.
+func (s BookService) Localize(ctx context.Context, books []domain.Book, acceptLanguage string) ([]domain.Book, error) {
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 || len(books) == 0 {
+		return books, nil
+	}
+
+	ids := make([]int64, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	translations, err := s.repo.GetTranslations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, book := range books {
+		available := translations[book.ID]
+		if len(available) == 0 {
+			continue
+		}
+
+		// The first tag is the fallback of the matcher, so the original
+		// language, even when unknown, goes first.
+		original, err := language.Parse(book.Language)
+		if err != nil {
+			original = language.Und
+		}
+
+		tags := []language.Tag{original}
+		for _, t := range available {
+			tags = append(tags, language.Make(t.Locale))
+		}
+
+		_, index, confidence := language.NewMatcher(tags).Match(preferred...)
+		if confidence == language.No {
+			continue
+		}
+
+		if index == 0 {
+			books[i].Locale = book.Language
+			continue
+		}
+
+		t := available[index-1]
+		books[i].Title = t.Title
+		if t.Description != "" {
+			books[i].Description = t.Description
+		}
+		books[i].Locale = t.Locale
+	}
+
+	return books, nil
+}
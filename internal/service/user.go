@@ -18,6 +18,7 @@ type PasswordHasher interface {
 type UserRepository interface {
 	Create(ctx context.Context, user domain.User) (int64, error)
 	GetByCredentials(ctx context.Context, email, password string) (domain.User, error)
+	GetById(ctx context.Context, id int64) (domain.User, error)
 }
 
 type UserService struct {
@@ -46,6 +47,7 @@ func (s UserService) SignUp(ctx context.Context, input domain.SignUpInput) (int6
 		Name:         input.Name,
 		Email:        input.Email,
 		Password:     hash,
+		Role:         domain.RoleReader,
 		RegisteredAt: time.Now(),
 	}
 
@@ -108,3 +110,7 @@ func (s UserService) ParseToken(ctx context.Context, token string) (int64, error
 
 	return int64(id), nil
 }
+
+func (s UserService) GetById(ctx context.Context, id int64) (domain.User, error) {
+	return s.repo.GetById(ctx, id)
+}
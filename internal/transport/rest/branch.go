@@ -0,0 +1,372 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+func (h Handler) createBranch(w http.ResponseWriter, r *http.Request) {
+	var branch domain.Branch
+	if err := readJSON(r, &branch); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createBranch",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := branch.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createBranch",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	id, err := h.branchService.Create(r.Context(), branch)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createBranch",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, id, http.StatusCreated, "createBranch")
+}
+
+func (h Handler) getAllBranches(w http.ResponseWriter, r *http.Request) {
+	branches, err := h.branchService.GetAll(r.Context())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getAllBranches",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, branches, http.StatusOK, "getAllBranches")
+}
+
+func (h Handler) getBranchById(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBranchById",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	branch, err := h.branchService.GetById(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBranchById",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, branch, http.StatusOK, "getBranchById")
+}
+
+func (h Handler) updateBranch(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateBranch",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var input domain.UpdateBranchInput
+	if err := readJSON(r, &input); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateBranch",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateBranch",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	err = h.branchService.Update(r.Context(), id, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateBranch",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) deleteBranch(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteBranch",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.branchService.Delete(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteBranch",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) requestTransfer(w http.ResponseWriter, r *http.Request) {
+	librarianId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "requestTransfer",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var t domain.Transfer
+	if err := readJSON(r, &t); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "requestTransfer",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := t.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "requestTransfer",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	transfer, err := h.branchService.RequestTransfer(r.Context(), librarianId, t)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "requestTransfer",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, transfer, http.StatusCreated, "requestTransfer")
+}
+
+func (h Handler) getTransfers(w http.ResponseWriter, r *http.Request) {
+	transfers, err := h.branchService.GetTransfers(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getTransfers",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, transfers, http.StatusOK, "getTransfers")
+}
+
+func (h Handler) getTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getTransfer",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, err := h.branchService.GetTransfer(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getTransfer",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, transfer, http.StatusOK, "getTransfer")
+}
+
+func (h Handler) shipTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "shipTransfer",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, err := h.branchService.ShipTransfer(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "shipTransfer",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, transfer, http.StatusOK, "shipTransfer")
+}
+
+func (h Handler) receiveTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "receiveTransfer",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	hold, err := h.branchService.ReceiveTransfer(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "receiveTransfer",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	transfer, err := h.branchService.GetTransfer(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "receiveTransfer",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, struct {
+		Transfer domain.Transfer `json:"transfer"`
+		Hold     *domain.Hold    `json:"hold,omitempty"`
+	}{transfer, hold}, http.StatusOK, "receiveTransfer")
+}
+
+func (h Handler) cancelTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "cancelTransfer",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	hold, err := h.branchService.CancelTransfer(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "cancelTransfer",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	transfer, err := h.branchService.GetTransfer(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "cancelTransfer",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, branchErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, struct {
+		Transfer domain.Transfer `json:"transfer"`
+		Hold     *domain.Hold    `json:"hold,omitempty"`
+	}{transfer, hold}, http.StatusOK, "cancelTransfer")
+}
+
+func branchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrorBranchNotFound),
+		errors.Is(err, domain.ErrorTransferNotFound),
+		errors.Is(err, domain.ErrorCopyNotFound),
+		errors.Is(err, domain.ErrorHoldNotFound),
+		errors.Is(err, domain.ErrorBookNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrorInvalidTransferState),
+		errors.Is(err, domain.ErrorCopyNotAvailable),
+		errors.Is(err, domain.ErrorSameBranchTransfer),
+		errors.Is(err, domain.ErrorTransferBranchMismatch):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrorEmptyUpdateBranchInput):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,338 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+func (h Handler) createCopy(w http.ResponseWriter, r *http.Request) {
+	bookId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createCopy",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var c domain.Copy
+	if err := readJSON(r, &c); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createCopy",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	c.BookID = bookId
+
+	if err := c.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createCopy",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	id, err := h.circulationService.CreateCopy(r.Context(), c)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createCopy",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, id, http.StatusCreated, "createCopy")
+}
+
+func (h Handler) getBookCopies(w http.ResponseWriter, r *http.Request) {
+	bookId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookCopies",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	copies, err := h.circulationService.GetBookCopies(r.Context(), bookId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookCopies",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, copies, http.StatusOK, "getBookCopies")
+}
+
+func (h Handler) getCopy(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getCopy",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	c, err := h.circulationService.GetCopy(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getCopy",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, c, http.StatusOK, "getCopy")
+}
+
+func (h Handler) updateCopy(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateCopy",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var input domain.UpdateCopyInput
+	if err := readJSON(r, &input); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateCopy",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateCopy",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	err = h.circulationService.UpdateCopy(r.Context(), id, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateCopy",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) deleteCopy(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteCopy",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.circulationService.DeleteCopy(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteCopy",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) checkoutCopy(w http.ResponseWriter, r *http.Request) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "checkoutCopy",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "checkoutCopy",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	loan, err := h.circulationService.Checkout(r.Context(), userId, id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "checkoutCopy",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, loan, http.StatusCreated, "checkoutCopy")
+}
+
+func (h Handler) returnCopy(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "returnCopy",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.circulationService.Return(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "returnCopy",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, result, http.StatusOK, "returnCopy")
+}
+
+func (h Handler) getCopyLoans(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getCopyLoans",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	loans, err := h.circulationService.GetCopyLoans(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getCopyLoans",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, loans, http.StatusOK, "getCopyLoans")
+}
+
+func (h Handler) getMyLoans(w http.ResponseWriter, r *http.Request) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getMyLoans",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	h.writeUserLoans(w, r, userId, "getMyLoans")
+}
+
+func (h Handler) getUserLoans(w http.ResponseWriter, r *http.Request) {
+	userId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getUserLoans",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.writeUserLoans(w, r, userId, "getUserLoans")
+}
+
+func (h Handler) writeUserLoans(w http.ResponseWriter, r *http.Request, userId int64, handler string) {
+	onlyActive := r.URL.Query().Get("active") == "true"
+
+	loans, err := h.circulationService.GetUserLoans(r.Context(), userId, onlyActive)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, loans, http.StatusOK, handler)
+}
+
+func circulationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrorCopyNotFound),
+		errors.Is(err, domain.ErrorBookNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrorCopyNotAvailable),
+		errors.Is(err, domain.ErrorCopyNotCheckedOut),
+		errors.Is(err, domain.ErrorCopyReserved),
+		errors.Is(err, domain.ErrorCopyInTransit),
+		errors.Is(err, domain.ErrorDuplicateBarcode),
+		errors.Is(err, domain.ErrorCopyHasLoans),
+		errors.Is(err, domain.ErrorLoanLimitReached):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrorEmptyUpdateCopyInput):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
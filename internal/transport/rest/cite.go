@@ -0,0 +1,205 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/cite"
+	"bytes"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type citationFormat struct {
+	contentType string
+	extension   string
+	write       func(w io.Writer, entries []cite.Entry) error
+}
+
+var citationFormats = map[string]citationFormat{
+	"bibtex":   {"application/x-bibtex; charset=utf-8", "bib", cite.BibTeX},
+	"ris":      {"application/x-research-info-systems; charset=utf-8", "ris", cite.RIS},
+	"csl-json": {"application/vnd.citationstyles.csl+json", "json", cite.CSLJSON},
+}
+
+func (h Handler) citeBook(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "citeBook",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	book, err := h.bookService.GetById(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "citeBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorBookNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeCitations(w, r, []domain.Book{book}, "citeBook")
+}
+
+// citeBooks cites either the books listed in "ids" or the books of the shelf
+// given by "shelf_id", which must be visible to the user.
+func (h Handler) citeBooks(w http.ResponseWriter, r *http.Request) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "citeBooks",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	query := r.URL.Query()
+	books := make([]domain.Book, 0)
+
+	switch {
+	case query.Get("shelf_id") != "":
+		shelfId, err := strconv.ParseInt(query.Get("shelf_id"), 10, 64)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "citeBooks",
+				"problem":    "parse shelf_id error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		shelf, err := h.shelfService.GetById(r.Context(), userId, shelfId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "citeBooks",
+				"problem":    "service error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, shelfErrorStatus(err), err)
+			return
+		}
+
+		for _, item := range shelf.Books {
+			books = append(books, item.Book)
+		}
+	case query.Get("ids") != "":
+		ids := strings.Split(query.Get("ids"), ",")
+		if len(ids) > maxPerPage {
+			err := fmt.Errorf("at most %d ids can be cited at once", maxPerPage)
+			log.WithFields(log.Fields{
+				"handler":    "citeBooks",
+				"problem":    "request validation error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		for _, v := range ids {
+			id, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"handler":    "citeBooks",
+					"problem":    "parse ids error",
+					"request_id": r.Context().Value("request_id"),
+				}).Error(err)
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			book, err := h.bookService.GetById(r.Context(), id)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"handler":    "citeBooks",
+					"problem":    "service error",
+					"request_id": r.Context().Value("request_id"),
+				}).Error(err)
+
+				if errors.Is(err, domain.ErrorBookNotFound) {
+					writeError(w, http.StatusNotFound, err)
+					return
+				}
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			books = append(books, book)
+		}
+	default:
+		err := errors.New("either ids or shelf_id is required")
+		log.WithFields(log.Fields{
+			"handler":    "citeBooks",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeCitations(w, r, books, "citeBooks")
+}
+
+func writeCitations(w http.ResponseWriter, r *http.Request, books []domain.Book, handler string) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "bibtex"
+	}
+
+	format, ok := citationFormats[name]
+	if !ok {
+		err := fmt.Errorf("unsupported citation format %q", name)
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "unsupported citation format",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	baseURL := requestBaseURL(r)
+	entries := make([]cite.Entry, 0, len(books))
+	for _, book := range books {
+		entries = append(entries, cite.Entry{
+			ID:        book.ID,
+			Title:     book.Title,
+			Authors:   cite.ParseAuthors(book.Author),
+			Published: book.PublishDate,
+			ISBN:      book.ISBN,
+			URL:       fmt.Sprintf("%s/books/%d", baseURL, book.ID),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := format.write(&buf, entries); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "format citation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", format.contentType)
+	w.Header().Add("Content-Disposition", fmt.Sprintf(`inline; filename="citations.%s"`, format.extension))
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+)
+
+const (
+	maxUploadSize   = 32 << 20
+	coverFormField  = "cover"
+	multipartMemory = 8 << 20
+)
+
+func (h Handler) uploadCover(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadCover",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadCover",
+			"problem":    "parse multipart form error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile(coverFormField)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadCover",
+			"problem":    "get cover file error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadCover",
+			"problem":    "read cover file error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	book, err := h.bookService.UploadCover(r.Context(), id, data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadCover",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		switch {
+		case errors.Is(err, domain.ErrorBookNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrorCoverTooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+		case errors.Is(err, domain.ErrorUnsupportedCoverType):
+			writeError(w, http.StatusUnsupportedMediaType, err)
+		default:
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	result, err := json.Marshal(book)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadCover",
+			"problem":    "book json marshal error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(result)
+}
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+const defaultDuplicateScore = 0.8
+
+func (h Handler) getDuplicateBooks(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := getPageFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getDuplicateBooks",
+			"problem":    "get page from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	minScore := defaultDuplicateScore
+	if v := r.URL.Query().Get("min_score"); v != "" {
+		minScore, err = strconv.ParseFloat(v, 64)
+		if err == nil && (minScore <= 0 || minScore > 1) {
+			err = errors.New("min_score must be greater than 0 and at most 1")
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "getDuplicateBooks",
+				"problem":    "parse min_score error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	duplicates, err := h.bookService.FindDuplicates(r.Context(), minScore, page, perPage)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getDuplicateBooks",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, duplicates, http.StatusOK, "getDuplicateBooks")
+}
+
+func (h Handler) mergeBooks(w http.ResponseWriter, r *http.Request) {
+	var input domain.MergeBooksInput
+	if err := readJSON(r, &input); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "mergeBooks",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "mergeBooks",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	result, err := h.bookService.Merge(r.Context(), input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "mergeBooks",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		switch {
+		case errors.Is(err, domain.ErrorBookNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrorInvalidMerge):
+			writeError(w, http.StatusBadRequest, err)
+		default:
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	writeJSON(w, result, http.StatusOK, "mergeBooks")
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// enrichBook shows what the metadata provider knows about the book and,
+// when the body accepts some of the proposed fields, applies them.
+func (h Handler) enrichBook(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "enrichBook",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var input domain.EnrichInput
+	if r.ContentLength != 0 {
+		if err := readJSON(r, &input); err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "enrichBook",
+				"problem":    "read request body error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	result, err := h.enrichService.Enrich(r.Context(), id, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "enrichBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, enrichErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, result, http.StatusOK, "enrichBook")
+}
+
+func enrichErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrorBookNotFound),
+		errors.Is(err, domain.ErrorMetadataNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrorBookWithoutISBN),
+		errors.Is(err, domain.ErrorValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrorUnknownEnrichField):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrorMetadataUnavailable),
+		errors.Is(err, domain.ErrorUnsupportedCoverType),
+		errors.Is(err, domain.ErrorCoverTooLarge):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errIfMatchRequired = errors.New("the If-Match header with the book's ETag is required")
+
+// bookETag is the strong entity tag of a book representation: the version
+// of the book, which If-Match is checked against, and a digest of the body.
+// The digest changes the tag with everything the body embeds beyond the
+// edited fields, such as the rating, availability and next in series,
+// none of which bump the version.
+func bookETag(version int64, body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return fmt.Sprintf(`"%d-%s"`, version, hex.EncodeToString(sum[:8]))
+}
+
+// etagVersion returns the book version a strong entity tag made by
+// bookETag stands for.
+func etagVersion(tag string) (int64, bool) {
+	if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+		return 0, false
+	}
+
+	tag = strings.Trim(tag, `"`)
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		tag = tag[:i]
+	}
+
+	version, err := strconv.ParseInt(tag, 10, 64)
+
+	return version, err == nil
+}
+
+// etagList splits an If-Match or If-None-Match header into its tags.
+func etagList(header string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// checkIfMatch makes sure the request names the current version of the book
+// in If-Match, "*" standing for any, and returns that version.
+func checkIfMatch(r *http.Request, book domain.Book) (int64, error) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return 0, errIfMatchRequired
+	}
+
+	for _, tag := range etagList(header) {
+		if tag == "*" {
+			return book.Version, nil
+		}
+
+		// Weak tags never match If-Match.
+		if version, ok := etagVersion(tag); ok && version == book.Version {
+			return book.Version, nil
+		}
+	}
+
+	return 0, domain.ErrorVersionMismatch
+}
+
+// noneMatch reports whether If-None-Match lists the tag, compared weakly.
+func noneMatch(r *http.Request, etag string) bool {
+	for _, tag := range etagList(r.Header.Get("If-None-Match")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// preconditionStatus is the status of a failed If-Match check.
+func preconditionStatus(err error) int {
+	if errors.Is(err, errIfMatchRequired) {
+		return http.StatusPreconditionRequired
+	}
+
+	return http.StatusPreconditionFailed
+}
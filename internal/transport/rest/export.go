@@ -0,0 +1,198 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"book_api/pkg/xlsx"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var exportContentTypes = map[string]string{
+	"csv":     "text/csv; charset=utf-8",
+	"ndjson":  "application/x-ndjson",
+	"xlsx":    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"marc":    "application/marc",
+	"marcxml": "application/marcxml+xml",
+}
+
+var exportExtensions = map[string]string{
+	"marc":    "mrc",
+	"marcxml": "xml",
+}
+
+var exportHeader = []string{
+	"id", "title", "author", "isbn", "publish_date", "series_id", "volume", "rating", "rating_count", "cover_url",
+}
+
+// exportBooks streams the books matching the list filters as CSV, NDJSON,
+// XLSX, MARC or MARCXML. Rows go straight from the database cursor to the response, so once
+// streaming has started errors can only be logged.
+func (h Handler) exportBooks(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		err := fmt.Errorf("unsupported export format %q", format)
+		log.WithFields(log.Fields{
+			"handler":    "exportBooks",
+			"problem":    "unsupported export format",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	filter, err := getBookFilterFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "exportBooks",
+			"problem":    "get filter from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ext, ok := exportExtensions[format]
+	if !ok {
+		ext = format
+	}
+
+	w.Header().Add("Content-Type", contentType)
+	w.Header().Add("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="books-%s.%s"`, time.Now().Format("20060102"), ext))
+
+	switch format {
+	case "csv":
+		err = h.exportCSV(w, r, filter)
+	case "ndjson":
+		err = h.exportNDJSON(w, r, filter)
+	case "xlsx":
+		err = h.exportXLSX(w, r, filter)
+	case "marc", "marcxml":
+		err = h.bookService.ExportMARC(r.Context(), w, filter, format)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "exportBooks",
+			"problem":    "export error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+	}
+}
+
+func (h Handler) exportCSV(w http.ResponseWriter, r *http.Request, filter domain.BookFilter) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader); err != nil {
+		return err
+	}
+
+	err := h.bookService.Export(r.Context(), filter, func(book domain.Book) error {
+		return cw.Write([]string{
+			strconv.FormatInt(book.ID, 10),
+			csvText(book.Title),
+			csvText(book.Author),
+			csvText(book.ISBN),
+			book.PublishDate.Format(dateLayout),
+			formatOptionalInt(book.SeriesID),
+			formatOptionalFloat(book.Volume),
+			strconv.FormatFloat(book.Rating, 'f', 2, 64),
+			strconv.FormatInt(book.RatingCount, 10),
+			csvText(book.CoverURL),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func (h Handler) exportNDJSON(w http.ResponseWriter, r *http.Request, filter domain.BookFilter) error {
+	encoder := json.NewEncoder(w)
+
+	return h.bookService.Export(r.Context(), filter, func(book domain.Book) error {
+		return encoder.Encode(book)
+	})
+}
+
+func (h Handler) exportXLSX(w http.ResponseWriter, r *http.Request, filter domain.BookFilter) error {
+	xw, err := xlsx.NewWriter(w, "Books")
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(exportHeader))
+	for i, name := range exportHeader {
+		header[i] = name
+	}
+	if err := xw.WriteRow(header...); err != nil {
+		return err
+	}
+
+	err = h.bookService.Export(r.Context(), filter, func(book domain.Book) error {
+		var seriesId, volume interface{}
+		if book.SeriesID != nil {
+			seriesId = *book.SeriesID
+		}
+		if book.Volume != nil {
+			volume = *book.Volume
+		}
+
+		return xw.WriteRow(
+			book.ID,
+			book.Title,
+			book.Author,
+			book.ISBN,
+			book.PublishDate,
+			seriesId,
+			volume,
+			book.Rating,
+			book.RatingCount,
+			book.CoverURL,
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	return xw.Close()
+}
+
+// csvText keeps spreadsheet applications from evaluating text that starts
+// like a formula by prefixing it with an apostrophe.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
+
+func formatOptionalInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(*v, 10)
+}
+
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
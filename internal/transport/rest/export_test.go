@@ -0,0 +1,24 @@
+package rest
+
+import "testing"
+
+func TestCSVText(t *testing.T) {
+	tests := map[string]string{
+		"":                      "",
+		"War and Peace":         "War and Peace",
+		"=HYPERLINK(\"x\")":     "'=HYPERLINK(\"x\")",
+		"+1":                    "'+1",
+		"-2+3":                  "'-2+3",
+		"@SUM(A1)":              "'@SUM(A1)",
+		"\t=1":                  "'\t=1",
+		"\r=1":                  "'\r=1",
+		"1 = 1":                 "1 = 1",
+		"https://example.org/=": "https://example.org/=",
+	}
+
+	for s, want := range tests {
+		if got := csvText(s); got != want {
+			t.Errorf("csvText(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+const (
+	maxBookFileUploadSize = 512 << 20
+	bookFileFormField     = "file"
+)
+
+func (h Handler) uploadBookFile(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadBookFile",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	prefill := false
+	if value := r.URL.Query().Get("prefill"); value != "" {
+		prefill, err = strconv.ParseBool(value)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "uploadBookFile",
+				"problem":    "parse prefill error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBookFileUploadSize)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadBookFile",
+			"problem":    "parse multipart form error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile(bookFileFormField)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadBookFile",
+			"problem":    "get book file error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	upload, err := h.fileService.Upload(r.Context(), id, header.Filename, file, header.Size, prefill)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "uploadBookFile",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, bookFileErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, upload, http.StatusCreated, "uploadBookFile")
+}
+
+func (h Handler) createBookFromFile(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBookFileUploadSize)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createBookFromFile",
+			"problem":    "parse multipart form error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile(bookFileFormField)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createBookFromFile",
+			"problem":    "get book file error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.bookService.CreateFromFile(r.Context(), file, header.Size)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createBookFromFile",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		// Tell the client which required fields the file lacked when that
+		// kept the book from being created.
+		if errors.Is(err, domain.ErrorEmptyRequiredField) {
+			writeError(w, http.StatusUnprocessableEntity, missingFieldsError(result.Missing))
+			return
+		}
+
+		writeError(w, bookFileErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, result, http.StatusCreated, "createBookFromFile")
+}
+
+func (h Handler) getBookFiles(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookFiles",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	files, err := h.fileService.GetByBook(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookFiles",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, bookFileErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, files, http.StatusOK, "getBookFiles")
+}
+
+// downloadBookFile serves the file content. Range, If-Range and
+// If-None-Match requests are answered by http.ServeContent, with the
+// SHA-256 of the content as a strong ETag.
+func (h Handler) downloadBookFile(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "downloadBookFile",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fileId, err := getInt64VarFromRequest(r, "file_id")
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "downloadBookFile",
+			"problem":    "get file id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	file, content, err := h.fileService.Open(r.Context(), id, fileId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "downloadBookFile",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, bookFileErrorStatus(err), err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	if file.SHA256 != "" {
+		w.Header().Set("ETag", `"`+file.SHA256+`"`)
+	}
+
+	http.ServeContent(w, r, file.Name, file.CreatedAt, content)
+}
+
+func (h Handler) deleteBookFile(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteBookFile",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fileId, err := getInt64VarFromRequest(r, "file_id")
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteBookFile",
+			"problem":    "get file id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.fileService.Delete(r.Context(), id, fileId); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteBookFile",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, bookFileErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func missingFieldsError(missing []string) error {
+	var invalid domain.ValidationError
+	for _, field := range missing {
+		if field == "title" || field == "author" {
+			invalid.Fields = append(invalid.Fields, domain.FieldError{Field: field, Reason: "is missing from the file"})
+		}
+	}
+
+	return invalid
+}
+
+func bookFileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrorBookNotFound),
+		errors.Is(err, domain.ErrorBookFileNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrorBookFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrorUnsupportedBookFileType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrorInvalidBookFile),
+		errors.Is(err, domain.ErrorValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrorDuplicateISBN):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
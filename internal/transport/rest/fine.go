@@ -0,0 +1,140 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+func (h Handler) getMyFines(w http.ResponseWriter, r *http.Request) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getMyFines",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	fines, err := h.fineService.GetUserFines(r.Context(), userId, r.URL.Query().Get("status"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getMyFines",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, fines, http.StatusOK, "getMyFines")
+}
+
+func (h Handler) getUserFines(w http.ResponseWriter, r *http.Request) {
+	userId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getUserFines",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fines, err := h.fineService.GetUserFines(r.Context(), userId, r.URL.Query().Get("status"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getUserFines",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, fines, http.StatusOK, "getUserFines")
+}
+
+func (h Handler) getFines(w http.ResponseWriter, r *http.Request) {
+	fines, err := h.fineService.GetAll(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getFines",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, fines, http.StatusOK, "getFines")
+}
+
+func (h Handler) payFine(w http.ResponseWriter, r *http.Request) {
+	h.resolveFine(w, r, "payFine", h.fineService.Pay)
+}
+
+func (h Handler) waiveFine(w http.ResponseWriter, r *http.Request) {
+	h.resolveFine(w, r, "waiveFine", h.fineService.Waive)
+}
+
+func (h Handler) resolveFine(
+	w http.ResponseWriter,
+	r *http.Request,
+	handler string,
+	resolve func(ctx context.Context, librarianId, id int64, note string) (domain.Fine, error),
+) {
+	librarianId, id, ok := getUserResourceIds(w, r, handler)
+	if !ok {
+		return
+	}
+
+	var input domain.ResolveFineInput
+	if r.ContentLength != 0 {
+		if err := readJSON(r, &input); err != nil {
+			log.WithFields(log.Fields{
+				"handler":    handler,
+				"problem":    "read request body error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err := input.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	fine, err := resolve(r.Context(), librarianId, id, input.Note)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		switch {
+		case errors.Is(err, domain.ErrorFineNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrorFineNotOpen):
+			writeError(w, http.StatusConflict, err)
+		default:
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	writeJSON(w, fine, http.StatusOK, handler)
+}
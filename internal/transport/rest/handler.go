@@ -5,42 +5,228 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+
+	dateLayout = "2006-01-02"
 )
 
 type BookService interface {
 	Create(ctx context.Context, book domain.Book) (int64, error)
-	GetAll(ctx context.Context) ([]domain.Book, error)
+	GetAll(ctx context.Context, filter domain.BookFilter) ([]domain.Book, error)
 	GetById(ctx context.Context, id int64) (domain.Book, error)
-	Update(ctx context.Context, id int64, in domain.UpdateBookInput) error
+	Replace(ctx context.Context, id, version int64, fields domain.BookFields) error
+	Patch(ctx context.Context, id, version int64, patchType string, patch []byte) (domain.Book, error)
+	Delete(ctx context.Context, id, version int64) error
+	GetNextInSeries(ctx context.Context, book domain.Book) (domain.Book, error)
+	UploadCover(ctx context.Context, id int64, data []byte) (domain.Book, error)
+	CreateFromFile(ctx context.Context, r io.ReaderAt, size int64) (domain.BookFromFile, error)
+	FindDuplicates(ctx context.Context, minScore float64, page, perPage int) (domain.DuplicatesPage, error)
+	Merge(ctx context.Context, input domain.MergeBooksInput) (domain.MergeResult, error)
+	Import(ctx context.Context, r io.Reader, opts domain.ImportOptions) (domain.ImportReport, error)
+	Export(ctx context.Context, filter domain.BookFilter, fn func(domain.Book) error) error
+	ExportMARC(ctx context.Context, w io.Writer, filter domain.BookFilter, format string) error
+	List(ctx context.Context, filter domain.BookFilter, sort string, page, perPage int) (domain.BooksPage, error)
+	GetAuthors(ctx context.Context, page, perPage int) (domain.AuthorsPage, error)
+	Localize(ctx context.Context, books []domain.Book, acceptLanguage string) ([]domain.Book, error)
+	GetTranslations(ctx context.Context, bookId int64) ([]domain.BookTranslation, error)
+	SetTranslation(ctx context.Context, bookId int64, t domain.BookTranslation) (domain.BookTranslation, error)
+	DeleteTranslation(ctx context.Context, bookId int64, locale string) error
+}
+
+type SeriesService interface {
+	Create(ctx context.Context, series domain.Series) (int64, error)
+	GetAll(ctx context.Context) ([]domain.Series, error)
+	GetById(ctx context.Context, id int64) (domain.Series, error)
+	Update(ctx context.Context, id int64, in domain.UpdateSeriesInput) error
 	Delete(ctx context.Context, id int64) error
 }
 
+type ReviewService interface {
+	Create(ctx context.Context, userId, bookId int64, input domain.ReviewInput) (domain.Review, error)
+	Update(ctx context.Context, userId, bookId int64, input domain.ReviewInput) (domain.Review, error)
+	Delete(ctx context.Context, userId, bookId int64) error
+	GetByBook(ctx context.Context, bookId int64, page, perPage int) (domain.ReviewsPage, error)
+}
+
+type ShelfService interface {
+	GetAll(ctx context.Context, userId int64) ([]domain.Shelf, error)
+	GetPublicByUser(ctx context.Context, ownerId int64) ([]domain.Shelf, error)
+	Create(ctx context.Context, userId int64, shelf domain.Shelf) (int64, error)
+	GetById(ctx context.Context, userId, id int64) (domain.Shelf, error)
+	GetShared(ctx context.Context, token string) (domain.Shelf, error)
+	Update(ctx context.Context, userId, id int64, input domain.UpdateShelfInput) error
+	Delete(ctx context.Context, userId, id int64) error
+	Share(ctx context.Context, userId, id int64) (string, error)
+	Unshare(ctx context.Context, userId, id int64) error
+	AddBook(ctx context.Context, userId, id, bookId int64) error
+	RemoveBook(ctx context.Context, userId, id, bookId int64) error
+	Reorder(ctx context.Context, userId, id int64, bookIds []int64) error
+}
+
+type ReadingService interface {
+	LogSession(ctx context.Context, session domain.ReadingSession) (int64, error)
+	GetSessions(ctx context.Context, userId, bookId int64) ([]domain.ReadingSession, error)
+	GetProgress(ctx context.Context, userId, bookId int64) (domain.ReadingProgress, error)
+	GetStats(ctx context.Context, userId int64, year int) (domain.ReadingStats, error)
+}
+
+type CirculationService interface {
+	CreateCopy(ctx context.Context, c domain.Copy) (int64, error)
+	GetCopy(ctx context.Context, id int64) (domain.Copy, error)
+	GetBookCopies(ctx context.Context, bookId int64) ([]domain.Copy, error)
+	UpdateCopy(ctx context.Context, id int64, input domain.UpdateCopyInput) error
+	DeleteCopy(ctx context.Context, id int64) error
+	Checkout(ctx context.Context, userId, copyId int64) (domain.Loan, error)
+	Return(ctx context.Context, copyId int64) (domain.ReturnResult, error)
+	GetUserLoans(ctx context.Context, userId int64, onlyActive bool) ([]domain.Loan, error)
+	GetCopyLoans(ctx context.Context, copyId int64) ([]domain.Loan, error)
+	CopyLabel(ctx context.Context, id int64, format string) ([]byte, error)
+	LabelSheet(ctx context.Context, ids []int64) ([]byte, error)
+}
+
+type HoldService interface {
+	Place(ctx context.Context, userId, bookId int64, input domain.PlaceHoldInput) (domain.Hold, error)
+	GetById(ctx context.Context, userId, id int64) (domain.Hold, error)
+	GetUserHolds(ctx context.Context, userId int64, onlyActive bool) ([]domain.Hold, error)
+	GetQueue(ctx context.Context, bookId int64) ([]domain.Hold, error)
+	Cancel(ctx context.Context, userId, id int64) error
+}
+
+type FineService interface {
+	GetUserFines(ctx context.Context, userId int64, status string) ([]domain.Fine, error)
+	GetAll(ctx context.Context, status string) ([]domain.Fine, error)
+	Pay(ctx context.Context, librarianId, id int64, note string) (domain.Fine, error)
+	Waive(ctx context.Context, librarianId, id int64, note string) (domain.Fine, error)
+}
+
+type BranchService interface {
+	Create(ctx context.Context, branch domain.Branch) (int64, error)
+	GetAll(ctx context.Context) ([]domain.Branch, error)
+	GetById(ctx context.Context, id int64) (domain.Branch, error)
+	Update(ctx context.Context, id int64, input domain.UpdateBranchInput) error
+	Delete(ctx context.Context, id int64) error
+	GetBookAvailability(ctx context.Context, bookId int64) ([]domain.BranchAvailability, error)
+	RequestTransfer(ctx context.Context, librarianId int64, t domain.Transfer) (domain.Transfer, error)
+	GetTransfer(ctx context.Context, id int64) (domain.Transfer, error)
+	GetTransfers(ctx context.Context, status string) ([]domain.Transfer, error)
+	ShipTransfer(ctx context.Context, id int64) (domain.Transfer, error)
+	ReceiveTransfer(ctx context.Context, id int64) (*domain.Hold, error)
+	CancelTransfer(ctx context.Context, id int64) (*domain.Hold, error)
+}
+
+type OAIService interface {
+	Identify(ctx context.Context) (domain.OAIRepository, error)
+	ListMetadataFormats(ctx context.Context, identifier string) ([]domain.OAIMetadataFormat, error)
+	ListSets(ctx context.Context) ([]domain.OAISet, error)
+	List(ctx context.Context, query domain.OAIQuery) (domain.OAIPage, error)
+	GetRecord(ctx context.Context, identifier, prefix string) (domain.OAIRecord, error)
+}
+
+type FileService interface {
+	Upload(ctx context.Context, bookId int64, name string, r io.ReaderAt, size int64, prefill bool) (domain.BookFileUpload, error)
+	GetByBook(ctx context.Context, bookId int64) ([]domain.BookFile, error)
+	GetByBooks(ctx context.Context, bookIds []int64) (map[int64][]domain.BookFile, error)
+	Open(ctx context.Context, bookId, id int64) (domain.BookFile, io.ReadSeekCloser, error)
+	Delete(ctx context.Context, bookId, id int64) error
+}
+
+type EnrichService interface {
+	Enrich(ctx context.Context, id int64, input domain.EnrichInput) (domain.EnrichResult, error)
+}
+
 type UserService interface {
 	SignUp(ctx context.Context, input domain.SignUpInput) (int64, error)
 	SignIn(ctx context.Context, input domain.SignInInput) (string, error)
 	ParseToken(ctx context.Context, token string) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.User, error)
 }
 
 type Handler struct {
-	bookService BookService
-	userService UserService
+	bookService    BookService
+	userService    UserService
+	seriesService  SeriesService
+	reviewService  ReviewService
+	shelfService   ShelfService
+	readingService ReadingService
+
+	circulationService CirculationService
+	holdService        HoldService
+	fineService        FineService
+	branchService      BranchService
+
+	oaiService    OAIService
+	fileService   FileService
+	enrichService EnrichService
 }
 
-func NewHandler(books BookService, users UserService) Handler {
+func NewHandler(
+	books BookService,
+	users UserService,
+	series SeriesService,
+	reviews ReviewService,
+	shelves ShelfService,
+	reading ReadingService,
+	circulation CirculationService,
+	holds HoldService,
+	fines FineService,
+	branches BranchService,
+	oai OAIService,
+	files FileService,
+	enrich EnrichService,
+) Handler {
 	return Handler{
-		bookService: books,
-		userService: users,
+		bookService:    books,
+		userService:    users,
+		seriesService:  series,
+		reviewService:  reviews,
+		shelfService:   shelves,
+		readingService: reading,
+
+		circulationService: circulation,
+		holdService:        holds,
+		fineService:        fines,
+		branchService:      branches,
+		oaiService:         oai,
+		fileService:        files,
+		enrichService:      enrich,
 	}
 }
 
+type bookResponse struct {
+	domain.Book
+	NextInSeries *domain.Book                `json:"next_in_series,omitempty"`
+	Availability []domain.BranchAvailability `json:"availability"`
+}
+
 func (h *Handler) InitRoutes() http.Handler {
 	r := mux.NewRouter()
-	r.Use(requestLogging)
+	r.Use(requestId, requestLogging)
+	r.NotFoundHandler = requestId(http.HandlerFunc(notFound))
+	r.MethodNotAllowedHandler = requestId(http.HandlerFunc(methodNotAllowed))
+
+	r.HandleFunc("/oai", h.oai).Methods(http.MethodGet, http.MethodPost)
+
+	opds := r.PathPrefix("/opds").Subrouter()
+	{
+		opds.HandleFunc("", h.getOPDSRoot).Methods(http.MethodGet)
+		opds.HandleFunc("/opensearch.xml", h.getOpenSearch).Methods(http.MethodGet)
+		opds.HandleFunc("/authors", h.getOPDSAuthors).Methods(http.MethodGet)
+		opds.HandleFunc("/{feed:new|top|author|search}", h.getOPDSBooks).Methods(http.MethodGet)
+		opds.HandleFunc("/v2", h.getOPDSRoot).Methods(http.MethodGet)
+		opds.HandleFunc("/v2/authors", h.getOPDSAuthors).Methods(http.MethodGet)
+		opds.HandleFunc("/v2/{feed:new|top|author|search}", h.getOPDSBooks).Methods(http.MethodGet)
+	}
 
 	books := r.PathPrefix("/books").Subrouter()
 	{
@@ -48,11 +234,141 @@ func (h *Handler) InitRoutes() http.Handler {
 
 		books.HandleFunc("", h.createBook).Methods(http.MethodPost)
 		books.HandleFunc("", h.getAllBooks).Methods(http.MethodGet)
+		books.Handle("/import", h.librarianMiddleware(http.HandlerFunc(h.importBooks))).Methods(http.MethodPost)
+		books.Handle("/from-file", h.librarianMiddleware(http.HandlerFunc(h.createBookFromFile))).Methods(http.MethodPost)
+		books.HandleFunc("/duplicates", h.getDuplicateBooks).Methods(http.MethodGet)
+		books.Handle("/merge", h.librarianMiddleware(http.HandlerFunc(h.mergeBooks))).Methods(http.MethodPost)
+		books.HandleFunc("/export", h.exportBooks).Methods(http.MethodGet)
+		books.HandleFunc("/cite", h.citeBooks).Methods(http.MethodGet)
 		books.HandleFunc("/{id:[0-9]+}", h.getBookById).Methods(http.MethodGet)
 		books.HandleFunc("/{id:[0-9]+}", h.updateBook).Methods(http.MethodPut)
+		books.HandleFunc("/{id:[0-9]+}", h.patchBook).Methods(http.MethodPatch)
 		books.HandleFunc("/{id:[0-9]+}", h.deleteBook).Methods(http.MethodDelete)
+		books.Handle("/{id:[0-9]+}/cover", h.librarianMiddleware(http.HandlerFunc(h.uploadCover))).Methods(http.MethodPut)
+		books.HandleFunc("/{id:[0-9]+}/cite", h.citeBook).Methods(http.MethodGet)
+		books.Handle("/{id:[0-9]+}/enrich", h.librarianMiddleware(http.HandlerFunc(h.enrichBook))).Methods(http.MethodPost)
+		books.HandleFunc("/{id:[0-9]+}/translations", h.getBookTranslations).Methods(http.MethodGet)
+		books.Handle("/{id:[0-9]+}/translations/{locale}", h.librarianMiddleware(http.HandlerFunc(h.setBookTranslation))).Methods(http.MethodPut)
+		books.Handle("/{id:[0-9]+}/translations/{locale}", h.librarianMiddleware(http.HandlerFunc(h.deleteBookTranslation))).Methods(http.MethodDelete)
+		books.HandleFunc("/{id:[0-9]+}/files", h.getBookFiles).Methods(http.MethodGet)
+		books.Handle("/{id:[0-9]+}/files", h.librarianMiddleware(http.HandlerFunc(h.uploadBookFile))).Methods(http.MethodPost)
+		books.HandleFunc("/{id:[0-9]+}/files/{file_id:[0-9]+}", h.downloadBookFile).Methods(http.MethodGet, http.MethodHead)
+		books.Handle("/{id:[0-9]+}/files/{file_id:[0-9]+}", h.librarianMiddleware(http.HandlerFunc(h.deleteBookFile))).Methods(http.MethodDelete)
+		books.HandleFunc("/{id:[0-9]+}/reviews", h.getBookReviews).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/reviews", h.createReview).Methods(http.MethodPost)
+		books.HandleFunc("/{id:[0-9]+}/reviews", h.updateReview).Methods(http.MethodPut)
+		books.HandleFunc("/{id:[0-9]+}/reviews", h.deleteReview).Methods(http.MethodDelete)
+		books.HandleFunc("/{id:[0-9]+}/sessions", h.getReadingSessions).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/sessions", h.logReadingSession).Methods(http.MethodPost)
+		books.HandleFunc("/{id:[0-9]+}/progress", h.getReadingProgress).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/copies", h.getBookCopies).Methods(http.MethodGet)
+		books.Handle("/{id:[0-9]+}/copies", h.librarianMiddleware(http.HandlerFunc(h.createCopy))).Methods(http.MethodPost)
+		books.HandleFunc("/{id:[0-9]+}/holds", h.getBookHolds).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/holds", h.placeHold).Methods(http.MethodPost)
+	}
+
+	holds := r.PathPrefix("/holds").Subrouter()
+	{
+		holds.Use(h.authMiddleware)
+
+		holds.HandleFunc("/{id:[0-9]+}", h.getHold).Methods(http.MethodGet)
+		holds.HandleFunc("/{id:[0-9]+}", h.cancelHold).Methods(http.MethodDelete)
+	}
+
+	copies := r.PathPrefix("/copies").Subrouter()
+	{
+		copies.Use(h.authMiddleware)
+
+		copies.HandleFunc("/{id:[0-9]+}", h.getCopy).Methods(http.MethodGet)
+		copies.Handle("/{id:[0-9]+}", h.librarianMiddleware(http.HandlerFunc(h.updateCopy))).Methods(http.MethodPut)
+		copies.Handle("/{id:[0-9]+}", h.librarianMiddleware(http.HandlerFunc(h.deleteCopy))).Methods(http.MethodDelete)
+		copies.HandleFunc("/{id:[0-9]+}/checkout", h.checkoutCopy).Methods(http.MethodPost)
+		copies.Handle("/{id:[0-9]+}/return", h.librarianMiddleware(http.HandlerFunc(h.returnCopy))).Methods(http.MethodPost)
+		copies.HandleFunc("/{id:[0-9]+}/loans", h.getCopyLoans).Methods(http.MethodGet)
+		copies.HandleFunc("/{id:[0-9]+}/label.{format:png|svg}", h.getCopyLabel).Methods(http.MethodGet)
+		copies.HandleFunc("/labels.pdf", h.getLabelSheet).Methods(http.MethodPost)
+	}
+
+	series := r.PathPrefix("/series").Subrouter()
+	{
+		series.Use(h.authMiddleware)
+
+		series.HandleFunc("", h.createSeries).Methods(http.MethodPost)
+		series.HandleFunc("", h.getAllSeries).Methods(http.MethodGet)
+		series.HandleFunc("/{id:[0-9]+}", h.getSeriesById).Methods(http.MethodGet)
+		series.HandleFunc("/{id:[0-9]+}", h.updateSeries).Methods(http.MethodPut)
+		series.HandleFunc("/{id:[0-9]+}", h.deleteSeries).Methods(http.MethodDelete)
 	}
 
+	shelves := r.PathPrefix("/shelves").Subrouter()
+	{
+		shelves.Use(h.authMiddleware)
+
+		shelves.HandleFunc("", h.getAllShelves).Methods(http.MethodGet)
+		shelves.HandleFunc("", h.createShelf).Methods(http.MethodPost)
+		shelves.HandleFunc("/{id:[0-9]+}", h.getShelfById).Methods(http.MethodGet)
+		shelves.HandleFunc("/{id:[0-9]+}", h.updateShelf).Methods(http.MethodPut)
+		shelves.HandleFunc("/{id:[0-9]+}", h.deleteShelf).Methods(http.MethodDelete)
+		shelves.HandleFunc("/{id:[0-9]+}/books", h.addShelfBook).Methods(http.MethodPost)
+		shelves.HandleFunc("/{id:[0-9]+}/books/order", h.reorderShelf).Methods(http.MethodPut)
+		shelves.HandleFunc("/{id:[0-9]+}/books/{book_id:[0-9]+}", h.removeShelfBook).Methods(http.MethodDelete)
+		shelves.HandleFunc("/{id:[0-9]+}/share", h.shareShelf).Methods(http.MethodPost)
+		shelves.HandleFunc("/{id:[0-9]+}/share", h.unshareShelf).Methods(http.MethodDelete)
+	}
+
+	branches := r.PathPrefix("/branches").Subrouter()
+	{
+		branches.Use(h.authMiddleware)
+
+		branches.HandleFunc("", h.getAllBranches).Methods(http.MethodGet)
+		branches.Handle("", h.librarianMiddleware(http.HandlerFunc(h.createBranch))).Methods(http.MethodPost)
+		branches.HandleFunc("/{id:[0-9]+}", h.getBranchById).Methods(http.MethodGet)
+		branches.Handle("/{id:[0-9]+}", h.librarianMiddleware(http.HandlerFunc(h.updateBranch))).Methods(http.MethodPut)
+		branches.Handle("/{id:[0-9]+}", h.librarianMiddleware(http.HandlerFunc(h.deleteBranch))).Methods(http.MethodDelete)
+	}
+
+	transfers := r.PathPrefix("/transfers").Subrouter()
+	{
+		transfers.Use(h.authMiddleware, h.librarianMiddleware)
+
+		transfers.HandleFunc("", h.getTransfers).Methods(http.MethodGet)
+		transfers.HandleFunc("", h.requestTransfer).Methods(http.MethodPost)
+		transfers.HandleFunc("/{id:[0-9]+}", h.getTransfer).Methods(http.MethodGet)
+		transfers.HandleFunc("/{id:[0-9]+}/ship", h.shipTransfer).Methods(http.MethodPost)
+		transfers.HandleFunc("/{id:[0-9]+}/receive", h.receiveTransfer).Methods(http.MethodPost)
+		transfers.HandleFunc("/{id:[0-9]+}/cancel", h.cancelTransfer).Methods(http.MethodPost)
+	}
+
+	fines := r.PathPrefix("/fines").Subrouter()
+	{
+		fines.Use(h.authMiddleware, h.librarianMiddleware)
+
+		fines.HandleFunc("", h.getFines).Methods(http.MethodGet)
+		fines.HandleFunc("/{id:[0-9]+}/pay", h.payFine).Methods(http.MethodPost)
+		fines.HandleFunc("/{id:[0-9]+}/waive", h.waiveFine).Methods(http.MethodPost)
+	}
+
+	users := r.PathPrefix("/users").Subrouter()
+	{
+		users.Use(h.authMiddleware)
+
+		users.HandleFunc("/{id:[0-9]+}/shelves", h.getUserShelves).Methods(http.MethodGet)
+		users.Handle("/{id:[0-9]+}/loans", h.librarianMiddleware(http.HandlerFunc(h.getUserLoans))).Methods(http.MethodGet)
+		users.Handle("/{id:[0-9]+}/fines", h.librarianMiddleware(http.HandlerFunc(h.getUserFines))).Methods(http.MethodGet)
+	}
+
+	me := r.PathPrefix("/me").Subrouter()
+	{
+		me.Use(h.authMiddleware)
+
+		me.HandleFunc("/stats", h.getMyStats).Methods(http.MethodGet)
+		me.HandleFunc("/loans", h.getMyLoans).Methods(http.MethodGet)
+		me.HandleFunc("/holds", h.getMyHolds).Methods(http.MethodGet)
+		me.HandleFunc("/fines", h.getMyFines).Methods(http.MethodGet)
+	}
+
+	r.HandleFunc("/shared/shelves/{token:[0-9a-f]+}", h.getSharedShelf).Methods(http.MethodGet)
+
 	auth := r.PathPrefix("/auth").Subrouter()
 	{
 		auth.HandleFunc("/sign-up", h.signUp).Methods(http.MethodPost)
@@ -66,10 +382,11 @@ func (h Handler) createBook(w http.ResponseWriter, r *http.Request) {
 	reqBody, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "createBook",
-			"problem": "reading request body",
+			"handler":    "createBook",
+			"problem":    "reading request body",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -77,36 +394,49 @@ func (h Handler) createBook(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(reqBody, &book)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "createBook",
-			"problem": "unmarshal request body",
+			"handler":    "createBook",
+			"problem":    "unmarshal request body",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	lastInsertedId, err := h.bookService.Create(r.Context(), book)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "createBook",
-			"problem": "service error",
+			"handler":    "createBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
 
-		if errors.Is(err, domain.ErrorEmptyRequiredField) {
-			w.WriteHeader(http.StatusBadRequest)
+		if errors.Is(err, domain.ErrorValidation) {
+			writeError(w, http.StatusUnprocessableEntity, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, domain.ErrorEmptyRequiredField) || errors.Is(err, domain.ErrorInvalidLanguage) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrorDuplicateISBN) {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	result, err := json.Marshal(lastInsertedId)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "createBook",
-			"problem": "lastInsertId json marshal error",
+			"handler":    "createBook",
+			"problem":    "lastInsertId json marshal error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -116,23 +446,48 @@ func (h Handler) createBook(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h Handler) getAllBooks(w http.ResponseWriter, r *http.Request) {
-	books, err := h.bookService.GetAll(r.Context())
+	filter, err := getBookFilterFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getAllBooks",
+			"problem":    "get filter from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	books, err := h.bookService.GetAll(r.Context(), filter)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "getAllBooks",
-			"problem": "get []book error",
+			"handler":    "getAllBooks",
+			"problem":    "get []book error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	books, err = h.bookService.Localize(r.Context(), books, r.Header.Get("Accept-Language"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getAllBooks",
+			"problem":    "localize error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Add("Vary", "Accept-Language")
+
 	result, err := json.Marshal(books)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "getAllBooks",
-			"problem": "[]book json marshal error",
+			"handler":    "getAllBooks",
+			"problem":    "[]book json marshal error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -144,37 +499,103 @@ func (h Handler) getBookById(w http.ResponseWriter, r *http.Request) {
 	id, err := getIdFromRequest(r)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "getBookById",
-			"problem": "get id from request error",
+			"handler":    "getBookById",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	book, err := h.bookService.GetById(r.Context(), id)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "getBookById",
-			"problem": "service error",
+			"handler":    "getBookById",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
 
 		if errors.Is(err, domain.ErrorBookNotFound) {
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, http.StatusNotFound, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	result, err := json.Marshal(book)
+	h.writeBook(w, r, book, "getBookById")
+}
+
+// bookRepresentation is the book as GET, PUT and PATCH return it: localized
+// for the request, with the next volume of its series and its availability.
+func (h Handler) bookRepresentation(r *http.Request, book domain.Book) (bookResponse, error) {
+	response := bookResponse{Book: book}
+
+	next, err := h.bookService.GetNextInSeries(r.Context(), book)
+	if err == nil {
+		response.NextInSeries = &next
+	} else if !errors.Is(err, domain.ErrorBookNotFound) {
+		return response, fmt.Errorf("next in series: %w", err)
+	}
+
+	localized := []domain.Book{response.Book}
+	if response.NextInSeries != nil {
+		localized = append(localized, *response.NextInSeries)
+	}
+
+	localized, err = h.bookService.Localize(r.Context(), localized, r.Header.Get("Accept-Language"))
+	if err != nil {
+		return response, fmt.Errorf("localize: %w", err)
+	}
+
+	response.Book = localized[0]
+	if response.NextInSeries != nil {
+		response.NextInSeries = &localized[1]
+	}
+
+	response.Availability, err = h.branchService.GetBookAvailability(r.Context(), book.ID)
+	if err != nil {
+		return response, fmt.Errorf("availability: %w", err)
+	}
+
+	return response, nil
+}
+
+// writeBook writes the representation of the book with its ETag, so that
+// every response carrying a version of the book tags it the same way.
+func (h Handler) writeBook(w http.ResponseWriter, r *http.Request, book domain.Book, handler string) {
+	response, err := h.bookRepresentation(r, book)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "getBookById",
-			"problem": "book json marshal error",
+			"handler":    handler,
+			"problem":    "book representation error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
 
-		w.WriteHeader(http.StatusInternalServerError)
+	result, err := json.Marshal(response)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "book json marshal error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Language")
+	if response.Locale != "" {
+		w.Header().Set("Content-Language", response.Locale)
+	}
+
+	etag := bookETag(book.Version, result)
+	w.Header().Set("ETag", etag)
+	if r.Method == http.MethodGet && noneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -182,111 +603,180 @@ func (h Handler) getBookById(w http.ResponseWriter, r *http.Request) {
 	w.Write(result)
 }
 
+// updateBook replaces the writable fields of the book; fields missing from
+// the body are cleared. Partial updates go through patchBook. The response is
+// the updated book with its new ETag.
 func (h Handler) updateBook(w http.ResponseWriter, r *http.Request) {
 	id, err := getIdFromRequest(r)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "updateBook",
-			"problem": "get id from request error",
+			"handler":    "updateBook",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	book, err := h.bookService.GetById(r.Context(), id)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "updateBook",
-			"problem": "service error",
+			"handler":    "updateBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
 
 		if errors.Is(err, domain.ErrorBookNotFound) {
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, http.StatusNotFound, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 
 	}
 
+	version, err := checkIfMatch(r, book)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateBook",
+			"problem":    "precondition error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, preconditionStatus(err), err)
+		return
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "updateBook",
-			"problem": "read request body error",
+			"handler":    "updateBook",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	var input domain.UpdateBookInput
-	err = json.Unmarshal(body, &input)
+	var fields domain.BookFields
+	err = json.Unmarshal(body, &fields)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "updateBook",
-			"problem": "json unmarshal error",
+			"handler":    "updateBook",
+			"problem":    "json unmarshal error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	err = h.bookService.Update(r.Context(), book.ID, input)
+	err = h.bookService.Replace(r.Context(), book.ID, version, fields)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "updateBook",
-			"problem": "service error",
+			"handler":    "updateBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
 
-		if errors.Is(err, domain.ErrorEmptyUpdateBookInput) {
-			w.WriteHeader(http.StatusBadRequest)
+		if errors.Is(err, domain.ErrorVersionMismatch) {
+			writeError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrorValidation) {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrorInvalidLanguage) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrorDuplicateISBN) {
+			writeError(w, http.StatusConflict, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	book, err = h.bookService.GetById(r.Context(), book.ID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeBook(w, r, book, "updateBook")
 }
 
 func (h Handler) deleteBook(w http.ResponseWriter, r *http.Request) {
 	id, err := getIdFromRequest(r)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "deleteBook",
-			"problem": "get id from request error",
+			"handler":    "deleteBook",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
 
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	book, err := h.bookService.GetById(r.Context(), id)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "deleteBook",
-			"problem": "service error",
+			"handler":    "deleteBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
 
 		if errors.Is(err, domain.ErrorBookNotFound) {
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, http.StatusNotFound, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 
 	}
 
-	err = h.bookService.Delete(r.Context(), book.ID)
+	version, err := checkIfMatch(r, book)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteBook",
+			"problem":    "precondition error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, preconditionStatus(err), err)
+		return
+	}
+
+	err = h.bookService.Delete(r.Context(), book.ID, version)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "deleteBook",
-			"problem": "service error",
+			"handler":    "deleteBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
 
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, domain.ErrorVersionMismatch) {
+			writeError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrorBookHasLoans) {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -297,10 +787,11 @@ func (h *Handler) signUp(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signUp",
-			"problem": "read request body error",
+			"handler":    "signUp",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	defer r.Body.Close()
@@ -308,29 +799,32 @@ func (h *Handler) signUp(w http.ResponseWriter, r *http.Request) {
 	var signUpInput domain.SignUpInput
 	if err := json.Unmarshal(body, &signUpInput); err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signUp",
-			"problem": "request body unmarshal error",
+			"handler":    "signUp",
+			"problem":    "request body unmarshal error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := signUpInput.Validate(); err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signUp",
-			"problem": "request validation error",
+			"handler":    "signUp",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusUnprocessableEntity, err)
 		return
 	}
 
 	_, err = h.userService.SignUp(r.Context(), signUpInput)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signUp",
-			"problem": "userService error",
+			"handler":    "signUp",
+			"problem":    "userService error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -341,10 +835,11 @@ func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signIn",
-			"problem": "read request body error",
+			"handler":    "signIn",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	defer r.Body.Close()
@@ -352,29 +847,32 @@ func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
 	var signInInput domain.SignInInput
 	if err := json.Unmarshal(body, &signInInput); err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signIn",
-			"problem": "request body unmarshal error",
+			"handler":    "signIn",
+			"problem":    "request body unmarshal error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := signInInput.Validate(); err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signIn",
-			"problem": "request validation error",
+			"handler":    "signIn",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusUnprocessableEntity, err)
 		return
 	}
 
 	strToken, err := h.userService.SignIn(r.Context(), signInInput)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signIn",
-			"problem": "userService error",
+			"handler":    "signIn",
+			"problem":    "userService error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusUnauthorized)
+		writeError(w, http.StatusUnauthorized, err)
 		return
 	}
 
@@ -383,10 +881,11 @@ func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		log.WithFields(log.Fields{
-			"handler": "signIn",
-			"problem": "response json marshal error",
+			"handler":    "signIn",
+			"problem":    "response json marshal error",
+			"request_id": r.Context().Value("request_id"),
 		}).Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -395,12 +894,143 @@ func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
 }
 
 func getIdFromRequest(r *http.Request) (int64, error) {
+	return getInt64VarFromRequest(r, "id")
+}
+
+func getInt64VarFromRequest(r *http.Request, name string) (int64, error) {
 	vars := mux.Vars(r)
 
-	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	id, err := strconv.ParseInt(vars[name], 10, 64)
 	if err != nil {
 		return 0, err
 	}
 
 	return id, nil
 }
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}, status int, handler string) {
+	result, err := json.Marshal(v)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "response json marshal error",
+			"request_id": w.Header().Get(requestIdHeader),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(result)
+}
+
+func getUserIdFromRequest(r *http.Request) (int64, error) {
+	userId, ok := r.Context().Value("user_id").(int64)
+	if !ok {
+		return 0, errors.New("user id not found in context")
+	}
+
+	return userId, nil
+}
+
+// getUserResourceIds reads the current user and the id path variable,
+// writing the error response itself when either is missing.
+func getUserResourceIds(w http.ResponseWriter, r *http.Request, handler string) (int64, int64, bool) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return 0, 0, false
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return 0, 0, false
+	}
+
+	return userId, id, true
+}
+
+// getPageFromRequest reads the page and per_page query parameters falling
+// back to the first page of defaultPerPage items.
+func getPageFromRequest(r *http.Request) (int, int, error) {
+	page, perPage := 1, defaultPerPage
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("invalid page")
+		}
+		page = n
+	}
+
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPerPage {
+			return 0, 0, errors.New("invalid per_page")
+		}
+		perPage = n
+	}
+
+	return page, perPage, nil
+}
+
+// getBookFilterFromRequest reads the book list filters shared by the list and
+// export endpoints from the query string.
+func getBookFilterFromRequest(r *http.Request) (domain.BookFilter, error) {
+	query := r.URL.Query()
+
+	filter := domain.BookFilter{
+		Query:  query.Get("q"),
+		Search: query.Get("search"),
+		Title:  query.Get("title"),
+		Author: query.Get("author"),
+		ISBN:   query.Get("isbn"),
+	}
+
+	if v := query.Get("series_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid series_id")
+		}
+		filter.SeriesID = &id
+	}
+
+	if v := query.Get("published_from"); v != "" {
+		date, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return filter, errors.New("invalid published_from")
+		}
+		filter.PublishedFrom = &date
+	}
+
+	if v := query.Get("published_to"); v != "" {
+		date, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return filter, errors.New("invalid published_to")
+		}
+		filter.PublishedTo = &date
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+func (h Handler) placeHold(w http.ResponseWriter, r *http.Request) {
+	userId, bookId, ok := getUserResourceIds(w, r, "placeHold")
+	if !ok {
+		return
+	}
+
+	var input domain.PlaceHoldInput
+	if r.ContentLength != 0 {
+		if err := readJSON(r, &input); err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "placeHold",
+				"problem":    "read request body error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	hold, err := h.holdService.Place(r.Context(), userId, bookId, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "placeHold",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, holdErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, hold, http.StatusCreated, "placeHold")
+}
+
+func (h Handler) getBookHolds(w http.ResponseWriter, r *http.Request) {
+	bookId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookHolds",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	holds, err := h.holdService.GetQueue(r.Context(), bookId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookHolds",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, holdErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, holds, http.StatusOK, "getBookHolds")
+}
+
+func (h Handler) getHold(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "getHold")
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdService.GetById(r.Context(), userId, id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getHold",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, holdErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, hold, http.StatusOK, "getHold")
+}
+
+func (h Handler) cancelHold(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "cancelHold")
+	if !ok {
+		return
+	}
+
+	err := h.holdService.Cancel(r.Context(), userId, id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "cancelHold",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, holdErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) getMyHolds(w http.ResponseWriter, r *http.Request) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getMyHolds",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	onlyActive := r.URL.Query().Get("active") == "true"
+
+	holds, err := h.holdService.GetUserHolds(r.Context(), userId, onlyActive)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getMyHolds",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, holds, http.StatusOK, "getMyHolds")
+}
+
+func holdErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrorHoldNotFound),
+		errors.Is(err, domain.ErrorBookNotFound),
+		errors.Is(err, domain.ErrorBranchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrorHoldAlreadyExists),
+		errors.Is(err, domain.ErrorHoldClosed),
+		errors.Is(err, domain.ErrorCopiesAvailable):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
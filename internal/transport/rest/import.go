@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"bufio"
+	"encoding/csv"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxImportSize       = 64 << 20
+	importColumnsPrefix = "map."
+)
+
+var importContentTypes = map[string]string{
+	"text/csv":             domain.ImportFormatCSV,
+	"application/x-ndjson": domain.ImportFormatNDJSON,
+	"application/ndjson":   domain.ImportFormatNDJSON,
+	"application/marc":     domain.ImportFormatMARC,
+
+	"application/marcxml+xml": domain.ImportFormatMARCXML,
+}
+
+// importBooks loads a catalog from CSV, NDJSON, MARC or MARCXML. The format comes from the
+// "format" query parameter or the Content-Type, CSV columns are remapped with
+// "map.<field>=<header>" parameters and "dry_run=true" only validates.
+func (h Handler) importBooks(w http.ResponseWriter, r *http.Request) {
+	opts, err := getImportOptions(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "importBooks",
+			"problem":    "read import options error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	report, err := h.bookService.Import(r.Context(), r.Body, opts)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "importBooks",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, importErrorStatus(err), err)
+		return
+	}
+
+	if r.URL.Query().Get("report") == "csv" || strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		writeImportReportCSV(w, report)
+		return
+	}
+
+	writeJSON(w, report, http.StatusOK, "importBooks")
+}
+
+func getImportOptions(r *http.Request) (domain.ImportOptions, error) {
+	query := r.URL.Query()
+
+	opts := domain.ImportOptions{
+		Format:  query.Get("format"),
+		Columns: make(map[string]string),
+	}
+
+	if opts.Format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		opts.Format = importContentTypes[mediaType]
+	}
+
+	if v := query.Get("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, err
+		}
+		opts.DryRun = dryRun
+	}
+
+	if v := query.Get("delimiter"); v != "" {
+		if utf8.RuneCountInString(v) != 1 {
+			return opts, errors.New("delimiter must be a single character")
+		}
+		opts.Delimiter, _ = utf8.DecodeRuneInString(v)
+	}
+
+	for key, values := range query {
+		if strings.HasPrefix(key, importColumnsPrefix) && len(values) > 0 {
+			opts.Columns[strings.TrimPrefix(key, importColumnsPrefix)] = values[0]
+		}
+	}
+
+	return opts, nil
+}
+
+func writeImportReportCSV(w http.ResponseWriter, report domain.ImportReport) {
+	w.Header().Add("Content-Type", "text/csv")
+	w.Header().Add("Content-Disposition", `attachment; filename="import-report.csv"`)
+
+	bw := bufio.NewWriter(w)
+	cw := csv.NewWriter(bw)
+	cw.Write([]string{"line", "field", "error"})
+	for _, e := range report.Errors {
+		cw.Write([]string{strconv.Itoa(e.Line), e.Field, e.Error})
+	}
+	cw.Flush()
+
+	if err := bw.Flush(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "importBooks",
+			"problem":    "write report error",
+			"request_id": w.Header().Get(requestIdHeader),
+		}).Error(err)
+	}
+}
+
+func importErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, domain.ErrorUnsupportedImportFormat):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrorMissingImportColumn),
+		errors.Is(err, bufio.ErrTooLong):
+		return http.StatusBadRequest
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
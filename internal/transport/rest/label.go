@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const maxLabelsPerSheet = 1000
+
+var labelContentTypes = map[string]string{
+	"png": "image/png",
+	"svg": "image/svg+xml",
+}
+
+type labelSheetRequest struct {
+	CopyIDs []int64 `json:"copy_ids"`
+}
+
+func (h Handler) getCopyLabel(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getCopyLabel",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	format := mux.Vars(r)["format"]
+
+	data, err := h.circulationService.CopyLabel(r.Context(), id, format)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getCopyLabel",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	w.Header().Add("Content-Type", labelContentTypes[format])
+	w.Write(data)
+}
+
+func (h Handler) getLabelSheet(w http.ResponseWriter, r *http.Request) {
+	var req labelSheetRequest
+	if err := readJSON(r, &req); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getLabelSheet",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(req.CopyIDs) == 0 || len(req.CopyIDs) > maxLabelsPerSheet {
+		err := fmt.Errorf("copy_ids must contain 1 to %d ids", maxLabelsPerSheet)
+		log.WithFields(log.Fields{
+			"handler":    "getLabelSheet",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := h.circulationService.LabelSheet(r.Context(), req.CopyIDs)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getLabelSheet",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, circulationErrorStatus(err), err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/pdf")
+	w.Header().Add("Content-Disposition", `inline; filename="labels.pdf"`)
+	w.Write(data)
+}
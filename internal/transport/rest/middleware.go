@@ -1,20 +1,20 @@
 package rest
 
 import (
+	"book_api/internal/domain"
 	"context"
 	"errors"
 	log "github.com/sirupsen/logrus"
 	"net/http"
 	"strings"
-	"time"
 )
 
 func requestLogging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s: [%s] - %s ", time.Now().Format(time.RFC3339), r.Method, r.RequestURI)
 		log.WithFields(log.Fields{
-			"method": r.Method,
-			"uri":    r.RequestURI,
+			"method":     r.Method,
+			"uri":        r.RequestURI,
+			"request_id": r.Context().Value("request_id"),
 		}).Info()
 		next.ServeHTTP(w, r)
 	})
@@ -25,18 +25,20 @@ func (h *Handler) authMiddleware(next http.Handler) http.Handler {
 		token, err := getTokenFromRequest(r)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"handler": "authMiddleware",
+				"handler":    "authMiddleware",
+				"request_id": r.Context().Value("request_id"),
 			}).Error(err)
-			w.WriteHeader(http.StatusUnauthorized)
+			writeError(w, http.StatusUnauthorized, err)
 			return
 		}
 
 		userId, err := h.userService.ParseToken(r.Context(), token)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"handler": "authMiddleware",
+				"handler":    "authMiddleware",
+				"request_id": r.Context().Value("request_id"),
 			}).Error(err)
-			w.WriteHeader(http.StatusUnauthorized)
+			writeError(w, http.StatusUnauthorized, err)
 			return
 		}
 
@@ -64,3 +66,38 @@ func getTokenFromRequest(r *http.Request) (string, error) {
 
 	return headerParts[1], nil
 }
+
+var errLibrarianOnly = errors.New("only librarians are allowed to do this")
+
+// librarianMiddleware lets through only users with the librarian role.
+// It must be used after authMiddleware.
+func (h *Handler) librarianMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, err := getUserIdFromRequest(r)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "librarianMiddleware",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		user, err := h.userService.GetById(r.Context(), userId)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "librarianMiddleware",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if user.Role != domain.RoleLibrarian {
+			writeError(w, http.StatusForbidden, errLibrarianOnly)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
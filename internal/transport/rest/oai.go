@@ -0,0 +1,379 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"encoding/xml"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+const (
+	oaiNamespace      = "http://www.openarchives.org/OAI/2.0/"
+	oaiSchemaLocation = oaiNamespace + " http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+	oaiDCNamespace    = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	oaiDCSchema       = oaiDCNamespace + " http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	dcNamespace       = "http://purl.org/dc/elements/1.1/"
+	xsiNamespace      = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// oaiArguments lists the arguments every verb accepts, true meaning required.
+// A resumptionToken is always exclusive.
+var oaiArguments = map[string]map[string]bool{
+	"Identify":            {},
+	"ListMetadataFormats": {"identifier": false},
+	"ListSets":            {"resumptionToken": false},
+	"ListIdentifiers":     {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+	"ListRecords":         {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+	"GetRecord":           {"identifier": true, "metadataPrefix": true},
+}
+
+type oaiResponse struct {
+	XMLName        xml.Name `xml:"OAI-PMH"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	ResponseDate   string   `xml:"responseDate"`
+	Request        oaiRequest
+	Errors         []oaiError `xml:"error,omitempty"`
+
+	Identify            *oaiIdentify            `xml:"Identify,omitempty"`
+	ListMetadataFormats *oaiListMetadataFormats `xml:"ListMetadataFormats,omitempty"`
+	ListSets            *oaiListSets            `xml:"ListSets,omitempty"`
+	ListIdentifiers     *oaiListIdentifiers     `xml:"ListIdentifiers,omitempty"`
+	ListRecords         *oaiListRecords         `xml:"ListRecords,omitempty"`
+	GetRecord           *oaiGetRecord           `xml:"GetRecord,omitempty"`
+}
+
+type oaiRequest struct {
+	XMLName         xml.Name `xml:"request"`
+	Verb            string   `xml:"verb,attr,omitempty"`
+	Identifier      string   `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string   `xml:"metadataPrefix,attr,omitempty"`
+	From            string   `xml:"from,attr,omitempty"`
+	Until           string   `xml:"until,attr,omitempty"`
+	Set             string   `xml:"set,attr,omitempty"`
+	ResumptionToken string   `xml:"resumptionToken,attr,omitempty"`
+	URL             string   `xml:",chardata"`
+}
+
+type oaiError struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+type oaiIdentify struct {
+	RepositoryName    string `xml:"repositoryName"`
+	BaseURL           string `xml:"baseURL"`
+	ProtocolVersion   string `xml:"protocolVersion"`
+	AdminEmail        string `xml:"adminEmail"`
+	EarliestDatestamp string `xml:"earliestDatestamp"`
+	DeletedRecord     string `xml:"deletedRecord"`
+	Granularity       string `xml:"granularity"`
+}
+
+type oaiListMetadataFormats struct {
+	Formats []oaiMetadataFormat `xml:"metadataFormat"`
+}
+
+type oaiMetadataFormat struct {
+	Prefix    string `xml:"metadataPrefix"`
+	Schema    string `xml:"schema"`
+	Namespace string `xml:"metadataNamespace"`
+}
+
+type oaiListSets struct {
+	Sets []oaiSet `xml:"set"`
+}
+
+type oaiSet struct {
+	Spec string `xml:"setSpec"`
+	Name string `xml:"setName"`
+}
+
+type oaiListIdentifiers struct {
+	Headers         []oaiHeader         `xml:"header"`
+	ResumptionToken *oaiResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+type oaiListRecords struct {
+	Records         []oaiRecord         `xml:"record"`
+	ResumptionToken *oaiResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+type oaiGetRecord struct {
+	Record oaiRecord `xml:"record"`
+}
+
+type oaiHeader struct {
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	SetSpecs   []string `xml:"setSpec"`
+}
+
+type oaiRecord struct {
+	Header   oaiHeader   `xml:"header"`
+	Metadata oaiMetadata `xml:"metadata"`
+}
+
+type oaiMetadata struct {
+	DC oaiDC `xml:"oai_dc:dc"`
+}
+
+type oaiDC struct {
+	XmlnsOAIDC     string   `xml:"xmlns:oai_dc,attr"`
+	XmlnsDC        string   `xml:"xmlns:dc,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Title          string   `xml:"dc:title"`
+	Creator        string   `xml:"dc:creator,omitempty"`
+	Date           string   `xml:"dc:date,omitempty"`
+	Type           string   `xml:"dc:type"`
+	Identifiers    []string `xml:"dc:identifier"`
+}
+
+// oaiResumptionToken is always present on the last page of a list, empty,
+// as the protocol requires once a list has been split.
+type oaiResumptionToken struct {
+	CompleteListSize int    `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+	Value            string `xml:",chardata"`
+}
+
+// oai implements the OAI-PMH 2.0 protocol. Protocol errors are reported in
+// the body of a 200 response as the specification requires.
+func (h Handler) oai(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "oai",
+			"problem":    "parse form error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response := oaiResponse{
+		Xmlns:          oaiNamespace,
+		XmlnsXSI:       xsiNamespace,
+		SchemaLocation: oaiSchemaLocation,
+		ResponseDate:   time.Now().UTC().Format(domain.OAIDatestamp),
+	}
+
+	repository, err := h.oaiService.Identify(r.Context())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "oai",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response.Request.URL = repository.BaseURL
+
+	if oaiErr := validateOAIArguments(r); oaiErr != nil {
+		response.Errors = append(response.Errors, *oaiErr)
+		writeOAI(w, response)
+		return
+	}
+
+	form := r.Form
+	response.Request = oaiRequest{
+		Verb:            form.Get("verb"),
+		Identifier:      form.Get("identifier"),
+		MetadataPrefix:  form.Get("metadataPrefix"),
+		From:            form.Get("from"),
+		Until:           form.Get("until"),
+		Set:             form.Get("set"),
+		ResumptionToken: form.Get("resumptionToken"),
+		URL:             repository.BaseURL,
+	}
+
+	query := domain.OAIQuery{
+		MetadataPrefix:  form.Get("metadataPrefix"),
+		From:            form.Get("from"),
+		Until:           form.Get("until"),
+		Set:             form.Get("set"),
+		ResumptionToken: form.Get("resumptionToken"),
+	}
+
+	switch form.Get("verb") {
+	case "Identify":
+		response.Identify = &oaiIdentify{
+			RepositoryName:    repository.Name,
+			BaseURL:           repository.BaseURL,
+			ProtocolVersion:   "2.0",
+			AdminEmail:        repository.AdminEmail,
+			EarliestDatestamp: repository.EarliestDatestamp.Format(domain.OAIDatestamp),
+			DeletedRecord:     "no",
+			Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+		}
+	case "ListMetadataFormats":
+		var formats []domain.OAIMetadataFormat
+		formats, err = h.oaiService.ListMetadataFormats(r.Context(), form.Get("identifier"))
+		if err == nil {
+			response.ListMetadataFormats = &oaiListMetadataFormats{}
+			for _, f := range formats {
+				response.ListMetadataFormats.Formats = append(response.ListMetadataFormats.Formats,
+					oaiMetadataFormat{Prefix: f.Prefix, Schema: f.Schema, Namespace: f.Namespace})
+			}
+		}
+	case "ListSets":
+		if query.ResumptionToken != "" {
+			err = domain.OAIError{Code: domain.OAIBadResumptionToken, Message: "set lists are never split"}
+			break
+		}
+
+		var sets []domain.OAISet
+		sets, err = h.oaiService.ListSets(r.Context())
+		if err == nil {
+			response.ListSets = &oaiListSets{}
+			for _, set := range sets {
+				response.ListSets.Sets = append(response.ListSets.Sets, oaiSet{Spec: set.Spec, Name: set.Name})
+			}
+		}
+	case "ListIdentifiers":
+		var page domain.OAIPage
+		page, err = h.oaiService.List(r.Context(), query)
+		if err == nil {
+			response.ListIdentifiers = &oaiListIdentifiers{ResumptionToken: oaiToken(page, query)}
+			for _, record := range page.Records {
+				response.ListIdentifiers.Headers = append(response.ListIdentifiers.Headers, oaiRecordHeader(record))
+			}
+		}
+	case "ListRecords":
+		var page domain.OAIPage
+		page, err = h.oaiService.List(r.Context(), query)
+		if err == nil {
+			response.ListRecords = &oaiListRecords{ResumptionToken: oaiToken(page, query)}
+			for _, record := range page.Records {
+				response.ListRecords.Records = append(response.ListRecords.Records, oaiDCRecord(record))
+			}
+		}
+	case "GetRecord":
+		var record domain.OAIRecord
+		record, err = h.oaiService.GetRecord(r.Context(), form.Get("identifier"), form.Get("metadataPrefix"))
+		if err == nil {
+			response.GetRecord = &oaiGetRecord{Record: oaiDCRecord(record)}
+		}
+	}
+
+	var oaiErr domain.OAIError
+	if errors.As(err, &oaiErr) {
+		response.Errors = append(response.Errors, oaiError{Code: oaiErr.Code, Message: oaiErr.Message})
+	} else if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "oai",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeOAI(w, response)
+}
+
+func validateOAIArguments(r *http.Request) *oaiError {
+	verbs := r.Form["verb"]
+	if len(verbs) != 1 {
+		return &oaiError{Code: domain.OAIBadVerb, Message: "exactly one verb is required"}
+	}
+
+	allowed, ok := oaiArguments[verbs[0]]
+	if !ok {
+		return &oaiError{Code: domain.OAIBadVerb, Message: "illegal verb"}
+	}
+
+	for name, values := range r.Form {
+		if name == "verb" {
+			continue
+		}
+		if _, ok := allowed[name]; !ok {
+			return &oaiError{Code: domain.OAIBadArgument, Message: "illegal argument " + name}
+		}
+		if len(values) > 1 {
+			return &oaiError{Code: domain.OAIBadArgument, Message: "repeated argument " + name}
+		}
+	}
+
+	if r.Form.Get("resumptionToken") != "" {
+		if len(r.Form) > 2 {
+			return &oaiError{Code: domain.OAIBadArgument, Message: "resumptionToken is an exclusive argument"}
+		}
+		return nil
+	}
+
+	for name, required := range allowed {
+		if required && r.Form.Get(name) == "" {
+			return &oaiError{Code: domain.OAIBadArgument, Message: "missing argument " + name}
+		}
+	}
+
+	return nil
+}
+
+func oaiToken(page domain.OAIPage, query domain.OAIQuery) *oaiResumptionToken {
+	if page.ResumptionToken == "" && query.ResumptionToken == "" {
+		return nil
+	}
+
+	return &oaiResumptionToken{
+		CompleteListSize: page.CompleteListSize,
+		Cursor:           page.Cursor,
+		Value:            page.ResumptionToken,
+	}
+}
+
+func oaiRecordHeader(record domain.OAIRecord) oaiHeader {
+	return oaiHeader{
+		Identifier: record.Identifier,
+		Datestamp:  record.Datestamp.Format(domain.OAIDatestamp),
+		SetSpecs:   record.SetSpecs,
+	}
+}
+
+func oaiDCRecord(record domain.OAIRecord) oaiRecord {
+	dc := oaiDC{
+		XmlnsOAIDC:     oaiDCNamespace,
+		XmlnsDC:        dcNamespace,
+		XmlnsXSI:       xsiNamespace,
+		SchemaLocation: oaiDCSchema,
+		Title:          record.Book.Title,
+		Creator:        record.Book.Author,
+		Type:           "Text",
+		Identifiers:    []string{record.Identifier},
+	}
+
+	if !record.Book.PublishDate.IsZero() {
+		dc.Date = record.Book.PublishDate.Format(dateLayout)
+	}
+
+	if record.Book.ISBN != "" {
+		dc.Identifiers = append(dc.Identifiers, "urn:isbn:"+record.Book.ISBN)
+	}
+
+	return oaiRecord{
+		Header:   oaiRecordHeader(record),
+		Metadata: oaiMetadata{DC: dc},
+	}
+}
+
+func writeOAI(w http.ResponseWriter, response oaiResponse) {
+	result, err := xml.Marshal(response)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "oai",
+			"problem":    "xml marshal error",
+			"request_id": w.Header().Get(requestIdHeader),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "text/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(result)
+}
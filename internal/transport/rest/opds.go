@@ -0,0 +1,471 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	opdsPath   = "/opds"
+	opds2Path  = "/opds/v2"
+	atomTime   = time.RFC3339
+	opdsAuthor = "Book API"
+
+	opdsNavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	opdsAcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	openSearchType      = "application/opensearchdescription+xml"
+
+	opdsImageRel     = "http://opds-spec.org/image"
+	opdsThumbnailRel = "http://opds-spec.org/image/thumbnail"
+	opdsAcquireRel   = "http://opds-spec.org/acquisition"
+	opdsSortNewRel   = "http://opds-spec.org/sort/new"
+	opdsSortTopRel   = "http://opds-spec.org/sort/popular"
+)
+
+// opdsFeed describes a catalog feed independently of the OPDS version it is
+// rendered in. Hrefs are relative to the version root.
+type opdsFeed struct {
+	ID          string
+	Title       string
+	Self        string
+	Acquisition bool
+	Navigation  []opdsNavigation
+	Books       []domain.Book
+	Files       map[int64][]domain.BookFile
+	Total       int64
+	Page        int
+	PerPage     int
+}
+
+type opdsNavigation struct {
+	ID          string
+	Title       string
+	Href        string
+	Rel         string
+	Content     string
+	Acquisition bool
+}
+
+type atomFeed struct {
+	XMLName         xml.Name `xml:"feed"`
+	Xmlns           string   `xml:"xmlns,attr"`
+	XmlnsDC         string   `xml:"xmlns:dc,attr"`
+	XmlnsOpenSearch string   `xml:"xmlns:opensearch,attr"`
+	ID              string   `xml:"id"`
+	Title           string   `xml:"title"`
+	Updated         string   `xml:"updated"`
+	Author          atomAuthor
+	Links           []atomLink  `xml:"link"`
+	TotalResults    int64       `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage    int         `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex      int         `xml:"opensearch:startIndex,omitempty"`
+	Entries         []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	XMLName xml.Name `xml:"author"`
+	Name    string   `xml:"name"`
+}
+
+type atomLink struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string       `xml:"id"`
+	Title      string       `xml:"title"`
+	Updated    string       `xml:"updated"`
+	Authors    []atomAuthor `xml:"author"`
+	Issued     string       `xml:"dc:issued,omitempty"`
+	Identifier string       `xml:"dc:identifier,omitempty"`
+	Content    *atomContent `xml:"content,omitempty"`
+	Links      []atomLink   `xml:"link"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type openSearchDescription struct {
+	XMLName        xml.Name `xml:"OpenSearchDescription"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	ShortName      string   `xml:"ShortName"`
+	Description    string   `xml:"Description"`
+	InputEncoding  string   `xml:"InputEncoding"`
+	OutputEncoding string   `xml:"OutputEncoding"`
+	URL            struct {
+		Type     string `xml:"type,attr"`
+		Template string `xml:"template,attr"`
+	} `xml:"Url"`
+}
+
+func (h Handler) getOPDSRoot(w http.ResponseWriter, r *http.Request) {
+	writeOPDS(w, r, opdsFeed{
+		ID:    "urn:book-api:opds",
+		Title: "Library catalog",
+		Navigation: []opdsNavigation{
+			{
+				ID:          "urn:book-api:opds:new",
+				Title:       "Newest",
+				Href:        "/new",
+				Rel:         opdsSortNewRel,
+				Content:     "Recently added books",
+				Acquisition: true,
+			},
+			{
+				ID:          "urn:book-api:opds:top",
+				Title:       "Top rated",
+				Href:        "/top",
+				Rel:         opdsSortTopRel,
+				Content:     "Books with the best reader ratings",
+				Acquisition: true,
+			},
+			{
+				ID:      "urn:book-api:opds:authors",
+				Title:   "By author",
+				Href:    "/authors",
+				Rel:     "subsection",
+				Content: "Browse books by author",
+			},
+		},
+	})
+}
+
+func (h Handler) getOPDSAuthors(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := getPageFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getOPDSAuthors",
+			"problem":    "get page from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	authors, err := h.bookService.GetAuthors(r.Context(), page, perPage)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getOPDSAuthors",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	feed := opdsFeed{
+		ID:      "urn:book-api:opds:authors",
+		Title:   "By author",
+		Self:    "/authors",
+		Total:   authors.Total,
+		Page:    authors.Page,
+		PerPage: authors.PerPage,
+	}
+
+	for _, author := range authors.Items {
+		feed.Navigation = append(feed.Navigation, opdsNavigation{
+			ID:          "urn:book-api:opds:author:" + url.QueryEscape(author.Author),
+			Title:       author.Author,
+			Href:        "/author?name=" + url.QueryEscape(author.Author),
+			Rel:         "subsection",
+			Content:     fmt.Sprintf("%d books", author.Books),
+			Acquisition: true,
+		})
+	}
+
+	writeOPDS(w, r, feed)
+}
+
+// getOPDSBooks serves the acquisition feeds: newest, top rated, books of one
+// author and search results.
+func (h Handler) getOPDSBooks(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := getPageFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getOPDSBooks",
+			"problem":    "get page from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	query := r.URL.Query()
+	feed := opdsFeed{Acquisition: true}
+
+	var filter domain.BookFilter
+	var order string
+
+	switch mux.Vars(r)["feed"] {
+	case "new":
+		feed.ID, feed.Title, feed.Self = "urn:book-api:opds:new", "Newest", "/new"
+		order = domain.BookSortNewest
+	case "top":
+		feed.ID, feed.Title, feed.Self = "urn:book-api:opds:top", "Top rated", "/top"
+		order = domain.BookSortTopRated
+	case "author":
+		filter.ExactAuthor = query.Get("name")
+		if filter.ExactAuthor == "" {
+			err = errors.New("missing author name")
+		}
+		feed.ID = "urn:book-api:opds:author:" + url.QueryEscape(filter.ExactAuthor)
+		feed.Title = filter.ExactAuthor
+		feed.Self = "/author?name=" + url.QueryEscape(filter.ExactAuthor)
+	case "search":
+		// OPDS 2.0 search templates use "query", OpenSearch uses "q".
+		filter.Query = query.Get("q")
+		if filter.Query == "" {
+			filter.Query = query.Get("query")
+		}
+		if filter.Query == "" {
+			err = errors.New("missing search query")
+		}
+		feed.ID = "urn:book-api:opds:search:" + url.QueryEscape(filter.Query)
+		feed.Title = "Search: " + filter.Query
+		feed.Self = "/search?q=" + url.QueryEscape(filter.Query)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getOPDSBooks",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	books, err := h.bookService.List(r.Context(), filter, order, page, perPage)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getOPDSBooks",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ids := make([]int64, 0, len(books.Items))
+	for _, book := range books.Items {
+		ids = append(ids, book.ID)
+	}
+
+	feed.Files, err = h.fileService.GetByBooks(r.Context(), ids)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getOPDSBooks",
+			"problem":    "book files error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	feed.Books = books.Items
+	feed.Total = books.Total
+	feed.Page = books.Page
+	feed.PerPage = books.PerPage
+
+	writeOPDS(w, r, feed)
+}
+
+func (h Handler) getOpenSearch(w http.ResponseWriter, r *http.Request) {
+	description := openSearchDescription{
+		Xmlns:          "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:      "Library",
+		Description:    "Search the library catalog by title or author",
+		InputEncoding:  "UTF-8",
+		OutputEncoding: "UTF-8",
+	}
+	description.URL.Type = opdsAcquisitionType
+	description.URL.Template = requestBaseURL(r) + opdsPath + "/search?q={searchTerms}"
+
+	writeXML(w, description, openSearchType, "getOpenSearch")
+}
+
+// writeOPDS renders the feed as OPDS 2.0 under /opds/v2 and as OPDS 1.2
+// everywhere else.
+func writeOPDS(w http.ResponseWriter, r *http.Request, feed opdsFeed) {
+	if strings.HasPrefix(r.URL.Path, opds2Path) {
+		writeOPDS2(w, feed)
+		return
+	}
+
+	atom := atomFeed{
+		Xmlns:           "http://www.w3.org/2005/Atom",
+		XmlnsDC:         "http://purl.org/dc/terms/",
+		XmlnsOpenSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		ID:              feed.ID,
+		Title:           feed.Title,
+		Updated:         time.Now().UTC().Format(atomTime),
+		Author:          atomAuthor{Name: opdsAuthor},
+		Links: []atomLink{
+			{Rel: "start", Href: opdsPath, Type: opdsNavigationType},
+			{Rel: "search", Href: opdsPath + "/opensearch.xml", Type: openSearchType},
+		},
+	}
+
+	selfType := opdsNavigationType
+	if feed.Acquisition {
+		selfType = opdsAcquisitionType
+	}
+	atom.Links = append(atom.Links, atomLink{Rel: "self", Href: opdsPath + feed.Self, Type: selfType})
+
+	for _, link := range opdsPageLinks(feed) {
+		atom.Links = append(atom.Links, atomLink{Rel: link.Rel, Href: opdsPath + link.Href, Type: selfType})
+	}
+
+	if feed.PerPage > 0 {
+		atom.TotalResults = feed.Total
+		atom.ItemsPerPage = feed.PerPage
+		atom.StartIndex = (feed.Page-1)*feed.PerPage + 1
+	}
+
+	for _, nav := range feed.Navigation {
+		navType := opdsNavigationType
+		if nav.Acquisition {
+			navType = opdsAcquisitionType
+		}
+
+		atom.Entries = append(atom.Entries, atomEntry{
+			ID:      nav.ID,
+			Title:   nav.Title,
+			Updated: atom.Updated,
+			Content: &atomContent{Type: "text", Value: nav.Content},
+			Links:   []atomLink{{Rel: nav.Rel, Href: opdsPath + nav.Href, Type: navType}},
+		})
+	}
+
+	for _, book := range feed.Books {
+		entry := atomEntry{
+			ID:      fmt.Sprintf("urn:book-api:book:%d", book.ID),
+			Title:   book.Title,
+			Updated: book.UpdatedAt.UTC().Format(atomTime),
+			Authors: []atomAuthor{{Name: book.Author}},
+			Links: []atomLink{
+				{Rel: "alternate", Href: fmt.Sprintf("/books/%d", book.ID), Type: "application/json"},
+			},
+		}
+
+		for _, file := range feed.Files[book.ID] {
+			entry.Links = append(entry.Links, atomLink{Rel: opdsAcquireRel, Href: bookFileHref(file), Type: file.ContentType})
+		}
+
+		if !book.PublishDate.IsZero() {
+			entry.Issued = book.PublishDate.Format(dateLayout)
+		}
+
+		if book.ISBN != "" {
+			entry.Identifier = "urn:isbn:" + book.ISBN
+		}
+
+		if book.CoverURL != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: opdsImageRel, Href: book.CoverURL})
+		}
+
+		if thumbnail := smallestThumbnail(book); thumbnail != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: opdsThumbnailRel, Href: thumbnail, Type: "image/jpeg"})
+		}
+
+		atom.Entries = append(atom.Entries, entry)
+	}
+
+	contentType := selfType + ";charset=utf-8"
+	writeXML(w, atom, contentType, "writeOPDS")
+}
+
+// bookFileHref is the download link of a book file.
+func bookFileHref(file domain.BookFile) string {
+	return fmt.Sprintf("/books/%d/files/%d", file.BookID, file.ID)
+}
+
+// opdsPageLinks returns the first, previous and next page links of a paged
+// feed, with hrefs relative to the version root. The page size is carried
+// along.
+func opdsPageLinks(feed opdsFeed) []atomLink {
+	links := make([]atomLink, 0)
+	if feed.PerPage == 0 {
+		return links
+	}
+
+	pageHref := func(page int) string {
+		separator := "?"
+		if strings.Contains(feed.Self, "?") {
+			separator = "&"
+		}
+
+		return feed.Self + separator + "page=" + strconv.Itoa(page) + "&per_page=" + strconv.Itoa(feed.PerPage)
+	}
+
+	links = append(links, atomLink{Rel: "first", Href: pageHref(1)})
+	if feed.Page > 1 {
+		links = append(links, atomLink{Rel: "previous", Href: pageHref(feed.Page - 1)})
+	}
+	if int64(feed.Page*feed.PerPage) < feed.Total {
+		links = append(links, atomLink{Rel: "next", Href: pageHref(feed.Page + 1)})
+	}
+
+	return links
+}
+
+func smallestThumbnail(book domain.Book) string {
+	sizes := make([]int, 0, len(book.ThumbnailURLs))
+	for size := range book.ThumbnailURLs {
+		if n, err := strconv.Atoi(size); err == nil {
+			sizes = append(sizes, n)
+		}
+	}
+
+	if len(sizes) == 0 {
+		return ""
+	}
+	sort.Ints(sizes)
+
+	return book.ThumbnailURLs[strconv.Itoa(sizes[0])]
+}
+
+// requestBaseURL rebuilds the scheme and host the client used to reach us.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	return scheme + "://" + r.Host
+}
+
+func writeXML(w http.ResponseWriter, v interface{}, contentType, handler string) {
+	result, err := xml.Marshal(v)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "xml marshal error",
+			"request_id": w.Header().Get(requestIdHeader),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", contentType)
+	w.Write([]byte(xml.Header))
+	w.Write(result)
+}
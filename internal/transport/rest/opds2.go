@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const opds2Type = "application/opds+json"
+
+type opds2Feed struct {
+	Metadata     opds2FeedMetadata  `json:"metadata"`
+	Links        []opds2Link        `json:"links"`
+	Navigation   []opds2Link        `json:"navigation,omitempty"`
+	Publications []opds2Publication `json:"publications,omitempty"`
+}
+
+type opds2FeedMetadata struct {
+	Title         string `json:"title"`
+	NumberOfItems int64  `json:"numberOfItems,omitempty"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+}
+
+type opds2Link struct {
+	Rel       string `json:"rel,omitempty"`
+	Href      string `json:"href"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+type opds2Publication struct {
+	Metadata opds2PublicationMetadata `json:"metadata"`
+	Links    []opds2Link              `json:"links"`
+	Images   []opds2Link              `json:"images,omitempty"`
+}
+
+type opds2PublicationMetadata struct {
+	Type       string             `json:"@type"`
+	Identifier string             `json:"identifier"`
+	Title      string             `json:"title"`
+	Author     []opds2Contributor `json:"author"`
+	Published  string             `json:"published,omitempty"`
+	Modified   string             `json:"modified"`
+}
+
+type opds2Contributor struct {
+	Name string `json:"name"`
+}
+
+func writeOPDS2(w http.ResponseWriter, feed opdsFeed) {
+	result := opds2Feed{
+		Metadata: opds2FeedMetadata{Title: feed.Title},
+		Links: []opds2Link{
+			{Rel: "self", Href: opds2Path + feed.Self, Type: opds2Type},
+			{Rel: "start", Href: opds2Path, Type: opds2Type},
+			{Rel: "search", Href: opds2Path + "/search{?query}", Type: opds2Type, Templated: true},
+		},
+	}
+
+	for _, link := range opdsPageLinks(feed) {
+		result.Links = append(result.Links, opds2Link{Rel: link.Rel, Href: opds2Path + link.Href, Type: opds2Type})
+	}
+
+	if feed.PerPage > 0 {
+		result.Metadata.NumberOfItems = feed.Total
+		result.Metadata.ItemsPerPage = feed.PerPage
+		result.Metadata.CurrentPage = feed.Page
+	}
+
+	for _, nav := range feed.Navigation {
+		result.Navigation = append(result.Navigation, opds2Link{
+			Rel:   nav.Rel,
+			Href:  opds2Path + nav.Href,
+			Type:  opds2Type,
+			Title: nav.Title,
+		})
+	}
+
+	for _, book := range feed.Books {
+		publication := opds2Publication{
+			Metadata: opds2PublicationMetadata{
+				Type:       "http://schema.org/Book",
+				Identifier: fmt.Sprintf("urn:book-api:book:%d", book.ID),
+				Title:      book.Title,
+				Author:     []opds2Contributor{{Name: book.Author}},
+				Modified:   book.UpdatedAt.UTC().Format(atomTime),
+			},
+			Links: []opds2Link{
+				{Rel: "self", Href: fmt.Sprintf("/books/%d", book.ID), Type: "application/json"},
+			},
+		}
+
+		for _, file := range feed.Files[book.ID] {
+			publication.Links = append(publication.Links, opds2Link{Rel: opdsAcquireRel, Href: bookFileHref(file), Type: file.ContentType})
+		}
+
+		if book.ISBN != "" {
+			publication.Metadata.Identifier = "urn:isbn:" + book.ISBN
+		}
+
+		if !book.PublishDate.IsZero() {
+			publication.Metadata.Published = book.PublishDate.Format(dateLayout)
+		}
+
+		if book.CoverURL != "" {
+			publication.Images = append(publication.Images, opds2Link{Href: book.CoverURL})
+		}
+
+		if thumbnail := smallestThumbnail(book); thumbnail != "" {
+			publication.Images = append(publication.Images, opds2Link{Href: thumbnail, Type: "image/jpeg"})
+		}
+
+		result.Publications = append(result.Publications, publication)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "writeOPDS2",
+			"problem":    "response json marshal error",
+			"request_id": w.Header().Get(requestIdHeader),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", opds2Type)
+	w.Write(data)
+}
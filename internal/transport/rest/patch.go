@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var acceptPatch = strings.Join([]string{domain.MergePatchType, domain.JSONPatchType}, ", ")
+
+// patchBook applies a JSON merge patch (RFC 7386) or a JSON patch
+// (RFC 6902), told apart by the Content-Type, and returns the patched book.
+// Like PUT and DELETE it needs the book's ETag in If-Match.
+func (h Handler) patchBook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Accept-Patch", acceptPatch)
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "patchBook",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	book, err := h.bookService.GetById(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "patchBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, patchErrorStatus(err), err)
+		return
+	}
+
+	version, err := checkIfMatch(r, book)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "patchBook",
+			"problem":    "precondition error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, preconditionStatus(err), err)
+		return
+	}
+
+	patchType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "patchBook",
+			"problem":    "parse content type error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnsupportedMediaType, domain.ErrorUnsupportedPatchType)
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "patchBook",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	book, err = h.bookService.Patch(r.Context(), id, version, patchType, patch)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "patchBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, patchErrorStatus(err), err)
+		return
+	}
+
+	h.writeBook(w, r, book, "patchBook")
+}
+
+func patchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrorBookNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrorUnsupportedPatchType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrorInvalidPatch),
+		errors.Is(err, domain.ErrorInvalidLanguage):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrorPatchTestFailed),
+		errors.Is(err, domain.ErrorDuplicateISBN):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrorVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrorPatchNotApplicable),
+		errors.Is(err, domain.ErrorValidation):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
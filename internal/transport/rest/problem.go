@@ -0,0 +1,221 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+const (
+	problemContentType = "application/problem+json"
+
+	// problemTypeBase prefixes the type URIs of the problems caused by
+	// domain errors; other problems have the type "about:blank".
+	problemTypeBase = "/problems/"
+
+	requestIdHeader = "X-Request-ID"
+	maxRequestIdLen = 128
+)
+
+// problem is an RFC 7807 problem details object.
+type problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    []domain.FieldError `json:"errors,omitempty"`
+}
+
+type problemType struct {
+	err  error
+	slug string
+}
+
+// problemTypes maps domain errors to the last segment of their type URI.
+// The message of the error, capitalized, is the title of the type. Errors
+// are matched in order with errors.Is.
+var problemTypes = []problemType{
+	{domain.ErrorValidation, "validation-failed"},
+	{domain.ErrorEmptyRequiredField, "required-field-empty"},
+	{domain.ErrorInvalidLanguage, "invalid-language"},
+	{domain.ErrorInvalidISBN, "invalid-isbn"},
+
+	{domain.ErrorUserNotFound, "user-not-found"},
+	{domain.ErrorBookNotFound, "book-not-found"},
+	{domain.ErrorSeriesNotFound, "series-not-found"},
+	{domain.ErrorReviewNotFound, "review-not-found"},
+	{domain.ErrorShelfNotFound, "shelf-not-found"},
+	{domain.ErrorCopyNotFound, "copy-not-found"},
+	{domain.ErrorHoldNotFound, "hold-not-found"},
+	{domain.ErrorFineNotFound, "fine-not-found"},
+	{domain.ErrorBranchNotFound, "branch-not-found"},
+	{domain.ErrorTransferNotFound, "transfer-not-found"},
+	{domain.ErrorBookFileNotFound, "book-file-not-found"},
+	{domain.ErrorTranslationNotFound, "translation-not-found"},
+	{domain.ErrorMetadataNotFound, "metadata-not-found"},
+
+	{domain.ErrorEmptyUpdateBookInput, "empty-update"},
+	{domain.ErrorEmptyUpdateSeriesInput, "empty-update"},
+	{domain.ErrorEmptyUpdateShelfInput, "empty-update"},
+	{domain.ErrorEmptyUpdateCopyInput, "empty-update"},
+	{domain.ErrorEmptyUpdateBranchInput, "empty-update"},
+
+	{domain.ErrorDuplicateISBN, "duplicate-isbn"},
+	{domain.ErrorDuplicateBarcode, "duplicate-barcode"},
+	{domain.ErrorCopyHasLoans, "copy-has-loans"},
+	{domain.ErrorBookHasLoans, "book-has-loans"},
+	{domain.ErrorReviewAlreadyExists, "review-exists"},
+	{domain.ErrorHoldAlreadyExists, "hold-exists"},
+	{domain.ErrorBookAlreadyOnShelf, "book-already-on-shelf"},
+	{domain.ErrorBookNotOnShelf, "book-not-on-shelf"},
+	{domain.ErrorShelfNotEditable, "shelf-not-editable"},
+	{domain.ErrorInvalidShelfOrder, "invalid-shelf-order"},
+	{domain.ErrorInvalidMerge, "invalid-merge"},
+	{domain.ErrorVersionMismatch, "version-mismatch"},
+	{errIfMatchRequired, "if-match-required"},
+
+	{domain.ErrorCopyNotAvailable, "copy-not-available"},
+	{domain.ErrorCopyNotCheckedOut, "copy-not-checked-out"},
+	{domain.ErrorCopyReserved, "copy-reserved"},
+	{domain.ErrorCopyInTransit, "copy-in-transit"},
+	{domain.ErrorCopiesAvailable, "copies-available"},
+	{domain.ErrorLoanLimitReached, "loan-limit-reached"},
+	{domain.ErrorHoldClosed, "hold-closed"},
+	{domain.ErrorFineNotOpen, "fine-not-open"},
+	{domain.ErrorInvalidTransferState, "invalid-transfer-state"},
+	{domain.ErrorSameBranchTransfer, "same-branch-transfer"},
+	{domain.ErrorTransferBranchMismatch, "transfer-branch-mismatch"},
+
+	{domain.ErrorCoverTooLarge, "cover-too-large"},
+	{domain.ErrorUnsupportedCoverType, "unsupported-cover-type"},
+	{domain.ErrorBookFileTooLarge, "book-file-too-large"},
+	{domain.ErrorUnsupportedBookFileType, "unsupported-book-file-type"},
+	{domain.ErrorInvalidBookFile, "invalid-book-file"},
+	{domain.ErrorUnsupportedImportFormat, "unsupported-import-format"},
+	{domain.ErrorMissingImportColumn, "missing-import-column"},
+
+	{domain.ErrorUnsupportedPatchType, "unsupported-patch-type"},
+	{domain.ErrorInvalidPatch, "invalid-patch"},
+	{domain.ErrorPatchNotApplicable, "patch-not-applicable"},
+	{domain.ErrorPatchTestFailed, "patch-test-failed"},
+
+	{domain.ErrorBookWithoutISBN, "book-without-isbn"},
+	{domain.ErrorUnknownEnrichField, "unknown-enrich-field"},
+	{domain.ErrorMetadataUnavailable, "metadata-unavailable"},
+}
+
+// newProblem describes err as a problem with the given status. Domain
+// errors get their own type; the details of other errors are only shown
+// for client errors, so that server internals do not leak.
+func newProblem(status int, err error) problem {
+	p := problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+
+	if err == nil {
+		return p
+	}
+
+	for _, t := range problemTypes {
+		if errors.Is(err, t.err) {
+			message := t.err.Error()
+
+			p.Type = problemTypeBase + t.slug
+			p.Title = strings.ToUpper(message[:1]) + message[1:]
+			p.Detail = message
+			if status < http.StatusInternalServerError {
+				p.Detail = err.Error()
+			}
+			break
+		}
+	}
+
+	if p.Type == "about:blank" && status < http.StatusInternalServerError {
+		p.Detail = err.Error()
+	}
+
+	var invalid domain.ValidationError
+	if errors.As(err, &invalid) {
+		p.Detail = "the request has invalid fields"
+		p.Errors = invalid.Fields
+	}
+
+	return p
+}
+
+// writeError answers with an application/problem+json description of err,
+// which may be nil when there is nothing to tell beyond the status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	p := newProblem(status, err)
+	p.RequestID = w.Header().Get(requestIdHeader)
+
+	result, err := json.Marshal(p)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "writeError",
+			"problem":    "problem json marshal error",
+			"request_id": w.Header().Get(requestIdHeader),
+		}).Error(err)
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	w.Write(result)
+}
+
+// requestId tags every request with an id, taken from the X-Request-ID
+// header when the client sent a usable one. The id is echoed in the
+// response and reported in problems and logs.
+func requestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIdHeader)
+		if !validRequestId(id) {
+			id = newRequestId()
+		}
+
+		w.Header().Set(requestIdHeader, id)
+
+		ctx := context.WithValue(r.Context(), "request_id", id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLen {
+		return false
+	}
+
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, nil)
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, nil)
+}
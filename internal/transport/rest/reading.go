@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (h Handler) logReadingSession(w http.ResponseWriter, r *http.Request) {
+	userId, bookId, ok := getUserResourceIds(w, r, "logReadingSession")
+	if !ok {
+		return
+	}
+
+	var session domain.ReadingSession
+	if err := readJSON(r, &session); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "logReadingSession",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	session.UserID = userId
+	session.BookID = bookId
+
+	if err := session.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "logReadingSession",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	id, err := h.readingService.LogSession(r.Context(), session)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "logReadingSession",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorBookNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, id, http.StatusCreated, "logReadingSession")
+}
+
+func (h Handler) getReadingSessions(w http.ResponseWriter, r *http.Request) {
+	userId, bookId, ok := getUserResourceIds(w, r, "getReadingSessions")
+	if !ok {
+		return
+	}
+
+	sessions, err := h.readingService.GetSessions(r.Context(), userId, bookId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getReadingSessions",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, sessions, http.StatusOK, "getReadingSessions")
+}
+
+func (h Handler) getReadingProgress(w http.ResponseWriter, r *http.Request) {
+	userId, bookId, ok := getUserResourceIds(w, r, "getReadingProgress")
+	if !ok {
+		return
+	}
+
+	progress, err := h.readingService.GetProgress(r.Context(), userId, bookId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getReadingProgress",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorBookNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, progress, http.StatusOK, "getReadingProgress")
+}
+
+func (h Handler) getMyStats(w http.ResponseWriter, r *http.Request) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getMyStats",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	year := time.Now().Year()
+	if v := r.URL.Query().Get("year"); v != "" {
+		year, err = strconv.Atoi(v)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"handler":    "getMyStats",
+				"problem":    "parse year error",
+				"request_id": r.Context().Value("request_id"),
+			}).Error(err)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	stats, err := h.readingService.GetStats(r.Context(), userId, year)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getMyStats",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, stats, http.StatusOK, "getMyStats")
+}
@@ -0,0 +1,223 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+)
+
+func (h Handler) getBookReviews(w http.ResponseWriter, r *http.Request) {
+	bookId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookReviews",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, perPage, err := getPageFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookReviews",
+			"problem":    "get page from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reviews, err := h.reviewService.GetByBook(r.Context(), bookId, page, perPage)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookReviews",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorBookNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := json.Marshal(reviews)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookReviews",
+			"problem":    "reviews json marshal error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(result)
+}
+
+func (h Handler) createReview(w http.ResponseWriter, r *http.Request) {
+	userId, bookId, input, ok := h.readReviewRequest(w, r, "createReview")
+	if !ok {
+		return
+	}
+
+	review, err := h.reviewService.Create(r.Context(), userId, bookId, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createReview",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		switch {
+		case errors.Is(err, domain.ErrorBookNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrorReviewAlreadyExists):
+			writeError(w, http.StatusConflict, err)
+		default:
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	writeJSON(w, review, http.StatusCreated, "createReview")
+}
+
+func (h Handler) updateReview(w http.ResponseWriter, r *http.Request) {
+	userId, bookId, input, ok := h.readReviewRequest(w, r, "updateReview")
+	if !ok {
+		return
+	}
+
+	review, err := h.reviewService.Update(r.Context(), userId, bookId, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateReview",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorReviewNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, review, http.StatusOK, "updateReview")
+}
+
+func (h Handler) deleteReview(w http.ResponseWriter, r *http.Request) {
+	bookId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteReview",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteReview",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = h.reviewService.Delete(r.Context(), userId, bookId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteReview",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorReviewNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// readReviewRequest extracts the book id, the current user and a validated review
+// from the request. It writes the error response itself and reports false on failure.
+func (h Handler) readReviewRequest(w http.ResponseWriter, r *http.Request, handler string) (int64, int64, domain.ReviewInput, bool) {
+	var input domain.ReviewInput
+
+	bookId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return 0, 0, input, false
+	}
+
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return 0, 0, input, false
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return 0, 0, input, false
+	}
+
+	if err := json.Unmarshal(body, &input); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "request body unmarshal error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return 0, 0, input, false
+	}
+
+	if err := input.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    handler,
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return 0, 0, input, false
+	}
+
+	return userId, bookId, input, true
+}
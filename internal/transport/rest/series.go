@@ -0,0 +1,219 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+)
+
+func (h Handler) createSeries(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createSeries",
+			"problem":    "reading request body",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var series domain.Series
+	err = json.Unmarshal(reqBody, &series)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createSeries",
+			"problem":    "unmarshal request body",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	lastInsertedId, err := h.seriesService.Create(r.Context(), series)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createSeries",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorEmptyRequiredField) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := json.Marshal(lastInsertedId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createSeries",
+			"problem":    "lastInsertId json marshal error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(result)
+}
+
+func (h Handler) getAllSeries(w http.ResponseWriter, r *http.Request) {
+	list, err := h.seriesService.GetAll(r.Context())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getAllSeries",
+			"problem":    "get []series error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := json.Marshal(list)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getAllSeries",
+			"problem":    "[]series json marshal error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(result)
+}
+
+func (h Handler) getSeriesById(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getSeriesById",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	series, err := h.seriesService.GetById(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getSeriesById",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorSeriesNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := json.Marshal(series)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getSeriesById",
+			"problem":    "series json marshal error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(result)
+}
+
+func (h Handler) updateSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateSeries",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateSeries",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var input domain.UpdateSeriesInput
+	err = json.Unmarshal(body, &input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateSeries",
+			"problem":    "json unmarshal error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.seriesService.Update(r.Context(), id, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateSeries",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		if errors.Is(err, domain.ErrorEmptyUpdateSeriesInput) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) deleteSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteSeries",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.seriesService.Delete(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteSeries",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,379 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+func (h Handler) getAllShelves(w http.ResponseWriter, r *http.Request) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getAllShelves",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	shelves, err := h.shelfService.GetAll(r.Context(), userId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getAllShelves",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, shelves, http.StatusOK, "getAllShelves")
+}
+
+func (h Handler) getUserShelves(w http.ResponseWriter, r *http.Request) {
+	ownerId, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getUserShelves",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	shelves, err := h.shelfService.GetPublicByUser(r.Context(), ownerId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getUserShelves",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, shelves, http.StatusOK, "getUserShelves")
+}
+
+func (h Handler) createShelf(w http.ResponseWriter, r *http.Request) {
+	userId, err := getUserIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createShelf",
+			"problem":    "get user id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var shelf domain.Shelf
+	if err := readJSON(r, &shelf); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createShelf",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := shelf.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createShelf",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	id, err := h.shelfService.Create(r.Context(), userId, shelf)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "createShelf",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, id, http.StatusCreated, "createShelf")
+}
+
+func (h Handler) getShelfById(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "getShelfById")
+	if !ok {
+		return
+	}
+
+	shelf, err := h.shelfService.GetById(r.Context(), userId, id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getShelfById",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, shelf, http.StatusOK, "getShelfById")
+}
+
+func (h Handler) getSharedShelf(w http.ResponseWriter, r *http.Request) {
+	shelf, err := h.shelfService.GetShared(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getSharedShelf",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, shelf, http.StatusOK, "getSharedShelf")
+}
+
+func (h Handler) updateShelf(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "updateShelf")
+	if !ok {
+		return
+	}
+
+	var input domain.UpdateShelfInput
+	if err := readJSON(r, &input); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateShelf",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateShelf",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	err := h.shelfService.Update(r.Context(), userId, id, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "updateShelf",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) deleteShelf(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "deleteShelf")
+	if !ok {
+		return
+	}
+
+	err := h.shelfService.Delete(r.Context(), userId, id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteShelf",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) addShelfBook(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "addShelfBook")
+	if !ok {
+		return
+	}
+
+	var input domain.AddShelfBookInput
+	if err := readJSON(r, &input); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "addShelfBook",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "addShelfBook",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	err := h.shelfService.AddBook(r.Context(), userId, id, input.BookID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "addShelfBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h Handler) removeShelfBook(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "removeShelfBook")
+	if !ok {
+		return
+	}
+
+	bookId, err := getInt64VarFromRequest(r, "book_id")
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "removeShelfBook",
+			"problem":    "get book id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.shelfService.RemoveBook(r.Context(), userId, id, bookId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "removeShelfBook",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) reorderShelf(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "reorderShelf")
+	if !ok {
+		return
+	}
+
+	var input domain.ReorderShelfInput
+	if err := readJSON(r, &input); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "reorderShelf",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "reorderShelf",
+			"problem":    "request validation error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	err := h.shelfService.Reorder(r.Context(), userId, id, input.BookIDs)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "reorderShelf",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h Handler) shareShelf(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "shareShelf")
+	if !ok {
+		return
+	}
+
+	token, err := h.shelfService.Share(r.Context(), userId, id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "shareShelf",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, map[string]string{
+		"token": token,
+		"url":   fmt.Sprintf("/shared/shelves/%s", token),
+	}, http.StatusOK, "shareShelf")
+}
+
+func (h Handler) unshareShelf(w http.ResponseWriter, r *http.Request) {
+	userId, id, ok := getUserResourceIds(w, r, "unshareShelf")
+	if !ok {
+		return
+	}
+
+	err := h.shelfService.Unshare(r.Context(), userId, id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "unshareShelf",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, shelfErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func shelfErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrorShelfNotFound),
+		errors.Is(err, domain.ErrorBookNotFound),
+		errors.Is(err, domain.ErrorBookNotOnShelf):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrorShelfNotEditable):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrorBookAlreadyOnShelf):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrorInvalidShelfOrder),
+		errors.Is(err, domain.ErrorEmptyUpdateShelfInput):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
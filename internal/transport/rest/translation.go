@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"book_api/internal/domain"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+func (h Handler) getBookTranslations(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookTranslations",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	translations, err := h.bookService.GetTranslations(r.Context(), id)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "getBookTranslations",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, translationErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, translations, http.StatusOK, "getBookTranslations")
+}
+
+// setBookTranslation creates or replaces the translation into the locale
+// of the path; a locale in the body is ignored.
+func (h Handler) setBookTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "setBookTranslation",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var input domain.BookTranslation
+	if err := readJSON(r, &input); err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "setBookTranslation",
+			"problem":    "read request body error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	input.Locale = mux.Vars(r)["locale"]
+
+	translation, err := h.bookService.SetTranslation(r.Context(), id, input)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "setBookTranslation",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+
+		writeError(w, translationErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, translation, http.StatusOK, "setBookTranslation")
+}
+
+func (h Handler) deleteBookTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteBookTranslation",
+			"problem":    "get id from request error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.bookService.DeleteTranslation(r.Context(), id, mux.Vars(r)["locale"])
+	if err != nil {
+		log.WithFields(log.Fields{
+			"handler":    "deleteBookTranslation",
+			"problem":    "service error",
+			"request_id": r.Context().Value("request_id"),
+		}).Error(err)
+		writeError(w, translationErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func translationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrorBookNotFound),
+		errors.Is(err, domain.ErrorTranslationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrorInvalidLanguage):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrorValidation):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
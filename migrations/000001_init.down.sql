@@ -0,0 +1,2 @@
+drop table if exists books;
+drop table if exists users;
@@ -0,0 +1,19 @@
+-- Tables of the original API; "if not exists" leaves databases created
+-- before the migrations were introduced untouched.
+create table if not exists users (
+    id            bigserial primary key,
+    name          text        not null,
+    email         text        not null,
+    password      text        not null,
+    registered_at timestamptz not null
+);
+
+create index if not exists users_email_idx on users (email);
+
+create table if not exists books (
+    id           bigserial primary key,
+    title        text        not null,
+    author       text        not null,
+    publish_date timestamptz not null,
+    rating       integer     not null default 0
+);
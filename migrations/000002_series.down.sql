@@ -0,0 +1,5 @@
+alter table books
+    drop column volume,
+    drop column series_id;
+
+drop table series;
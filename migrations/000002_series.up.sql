@@ -0,0 +1,11 @@
+create table series (
+    id          bigserial primary key,
+    name        text not null,
+    description text not null default ''
+);
+
+alter table books
+    add column series_id bigint references series (id) on delete set null,
+    add column volume    double precision;
+
+create index books_series_id_idx on books (series_id, volume);
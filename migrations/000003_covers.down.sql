@@ -0,0 +1 @@
+alter table books drop column cover;
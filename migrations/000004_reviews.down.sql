@@ -0,0 +1,12 @@
+alter table books rename column legacy_rating to rating;
+
+update books set rating = coalesce(rating, round(rating_sum::numeric / nullif(rating_count, 0)), 0);
+
+alter table books alter column rating set not null;
+
+alter table books
+    drop column rating_histogram,
+    drop column rating_count,
+    drop column rating_sum;
+
+drop table reviews;
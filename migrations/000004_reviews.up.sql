@@ -0,0 +1,30 @@
+create table if not exists reviews (
+    user_id    bigint      not null references users (id) on delete cascade,
+    book_id    bigint      not null references books (id) on delete cascade,
+    rating     integer     not null check (rating between 1 and 5),
+    text       text        not null default '',
+    created_at timestamptz not null,
+    updated_at timestamptz not null,
+    primary key (user_id, book_id)
+);
+
+create index if not exists reviews_book_id_idx on reviews (book_id, created_at desc);
+
+alter table books
+    add column rating_sum       bigint   not null default 0,
+    add column rating_count     integer  not null default 0,
+    add column rating_histogram bigint[] not null default '{0,0,0,0,0}';
+
+update books set
+    rating_count = (select count(*) from reviews where reviews.book_id = books.id),
+    rating_sum = (select coalesce(sum(rating), 0) from reviews where reviews.book_id = books.id),
+    rating_histogram = array(
+        select count(reviews.rating) from generate_series(1, 5) g
+        left join reviews on reviews.book_id = books.id and reviews.rating = g
+        group by g order by g
+    );
+
+-- The single rating no user owns is kept aside instead of being dropped, so
+-- rolling back restores it.
+alter table books rename column rating to legacy_rating;
+alter table books alter column legacy_rating drop not null;
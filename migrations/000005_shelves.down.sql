@@ -0,0 +1,2 @@
+drop table shelf_books;
+drop table shelves;
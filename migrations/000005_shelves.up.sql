@@ -0,0 +1,21 @@
+create table shelves (
+    id          bigserial primary key,
+    user_id     bigint      not null references users (id) on delete cascade,
+    name        text        not null,
+    kind        text        not null check (kind in ('want_to_read', 'reading', 'read', 'custom')),
+    public      boolean     not null default false,
+    share_token text unique,
+    created_at  timestamptz not null
+);
+
+create unique index shelves_user_kind_idx on shelves (user_id, kind) where kind <> 'custom';
+
+create table shelf_books (
+    shelf_id bigint      not null references shelves (id) on delete cascade,
+    book_id  bigint      not null references books (id) on delete cascade,
+    position integer     not null,
+    added_at timestamptz not null,
+    primary key (shelf_id, book_id)
+);
+
+create index shelf_books_book_id_idx on shelf_books (book_id);
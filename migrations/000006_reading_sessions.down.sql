@@ -0,0 +1 @@
+drop table reading_sessions;
@@ -0,0 +1,13 @@
+create table reading_sessions (
+    id          bigserial primary key,
+    user_id     bigint           not null references users (id) on delete cascade,
+    book_id     bigint           not null references books (id) on delete cascade,
+    pages       integer check (pages >= 0),
+    percent     double precision check (percent between 0 and 100),
+    started_at  timestamptz      not null,
+    finished_at timestamptz,
+    completed   boolean          not null default false
+);
+
+create index reading_sessions_user_book_idx on reading_sessions (user_id, book_id, started_at);
+create index reading_sessions_book_id_idx on reading_sessions (book_id);
@@ -0,0 +1,23 @@
+create table copies (
+    id         bigserial primary key,
+    book_id    bigint      not null references books (id) on delete cascade,
+    barcode    text        not null unique,
+    location   text        not null default '',
+    condition  text        not null default '',
+    created_at timestamptz not null
+);
+
+create index copies_book_id_idx on copies (book_id);
+
+create table loans (
+    id             bigserial primary key,
+    copy_id        bigint      not null references copies (id) on delete restrict,
+    user_id        bigint      not null references users (id) on delete cascade,
+    checked_out_at timestamptz not null,
+    due_at         timestamptz not null,
+    returned_at    timestamptz
+);
+
+-- A copy is on at most one active loan; checkout relies on the violation.
+create unique index loans_active_copy_idx on loans (copy_id) where returned_at is null;
+create index loans_user_id_idx on loans (user_id, checked_out_at desc);
@@ -0,0 +1,15 @@
+create table holds (
+    id              bigserial primary key,
+    book_id         bigint      not null references books (id) on delete cascade,
+    user_id         bigint      not null references users (id) on delete cascade,
+    status          text        not null check (status in ('waiting', 'ready', 'fulfilled', 'cancelled', 'expired')),
+    copy_id         bigint references copies (id) on delete set null,
+    created_at      timestamptz not null,
+    ready_at        timestamptz,
+    pickup_deadline timestamptz,
+    closed_at       timestamptz
+);
+
+create index holds_book_queue_idx on holds (book_id, status, created_at, id);
+create index holds_user_id_idx on holds (user_id, created_at desc);
+create index holds_copy_id_idx on holds (copy_id) where status = 'ready';
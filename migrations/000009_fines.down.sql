@@ -0,0 +1,7 @@
+drop table fines;
+
+drop index loans_overdue_idx;
+
+alter table loans drop column reminded_at;
+
+alter table users drop column role;
@@ -0,0 +1,20 @@
+alter table users add column role text not null default 'reader' check (role in ('reader', 'librarian'));
+
+alter table loans add column reminded_at timestamptz;
+
+create index loans_overdue_idx on loans (due_at) where returned_at is null;
+
+create table fines (
+    id          bigserial primary key,
+    loan_id     bigint      not null unique references loans (id) on delete restrict,
+    user_id     bigint      not null references users (id) on delete cascade,
+    amount      bigint      not null check (amount >= 0),
+    status      text        not null check (status in ('open', 'paid', 'waived')),
+    note        text,
+    created_at  timestamptz not null,
+    updated_at  timestamptz not null,
+    resolved_at timestamptz,
+    resolved_by bigint references users (id) on delete set null
+);
+
+create index fines_user_id_idx on fines (user_id, created_at desc);
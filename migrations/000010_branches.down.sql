@@ -0,0 +1,7 @@
+drop table transfers;
+
+alter table holds drop column pickup_branch_id;
+
+alter table copies drop column branch_id;
+
+drop table branches;
@@ -0,0 +1,27 @@
+create table branches (
+    id            bigserial primary key,
+    name          text        not null,
+    address       text        not null default '',
+    opening_hours jsonb       not null default '{}',
+    created_at    timestamptz not null
+);
+
+alter table copies add column branch_id bigint references branches (id) on delete set null;
+
+alter table holds add column pickup_branch_id bigint references branches (id) on delete set null;
+
+create table transfers (
+    id             bigserial primary key,
+    copy_id        bigint      not null references copies (id) on delete cascade,
+    from_branch_id bigint references branches (id) on delete set null,
+    to_branch_id   bigint      not null references branches (id) on delete cascade,
+    hold_id        bigint references holds (id) on delete set null,
+    status         text        not null check (status in ('requested', 'in_transit', 'received', 'cancelled')),
+    requested_by   bigint      not null references users (id),
+    requested_at   timestamptz not null,
+    shipped_at     timestamptz,
+    received_at    timestamptz
+);
+
+create index transfers_copy_id_idx on transfers (copy_id) where status in ('requested', 'in_transit');
+create index transfers_status_idx on transfers (status, requested_at desc);
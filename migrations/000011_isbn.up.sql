@@ -0,0 +1,3 @@
+alter table books add column isbn text;
+
+create index books_isbn_idx on books (isbn);
@@ -0,0 +1,3 @@
+alter table books add column updated_at timestamptz not null default now();
+
+create index books_updated_at_idx on books (updated_at);
@@ -0,0 +1 @@
+drop table book_files;
@@ -0,0 +1,14 @@
+create table book_files (
+    id           bigserial primary key,
+    book_id      bigint      not null references books (id) on delete cascade,
+    format       text        not null,
+    name         text        not null,
+    content_type text        not null,
+    size         bigint      not null,
+    sha256       text        not null,
+    storage_key  text        not null,
+    metadata     jsonb,
+    created_at   timestamptz not null
+);
+
+create index book_files_book_id_idx on book_files (book_id);
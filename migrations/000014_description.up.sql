@@ -0,0 +1 @@
+alter table books add column description text;
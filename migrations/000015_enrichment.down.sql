@@ -0,0 +1,3 @@
+alter table books
+    drop column subjects,
+    drop column page_count;
@@ -0,0 +1,3 @@
+alter table books
+    add column page_count integer,
+    add column subjects   text[];
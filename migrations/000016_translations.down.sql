@@ -0,0 +1,3 @@
+drop table book_translations;
+
+alter table books drop column language;
@@ -0,0 +1,9 @@
+alter table books add column language text;
+
+create table book_translations (
+    book_id     bigint not null references books (id) on delete cascade,
+    locale      text   not null,
+    title       text   not null,
+    description text,
+    primary key (book_id, locale)
+);
@@ -0,0 +1 @@
+alter table books add column version bigint not null default 1;
@@ -0,0 +1,3 @@
+drop index books_isbn_key;
+
+create index books_isbn_idx on books (isbn);
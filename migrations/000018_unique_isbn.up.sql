@@ -0,0 +1,13 @@
+-- Imports upsert books by ISBN, so an ISBN may belong to one book only.
+-- Duplicates have to be merged before the index can be built.
+do $$
+begin
+    if exists (select 1 from books where isbn is not null group by isbn having count(*) > 1) then
+        raise exception 'books share an ISBN; merge the duplicates before migrating';
+    end if;
+end
+$$;
+
+drop index books_isbn_idx;
+
+create unique index books_isbn_key on books (isbn) where isbn is not null;
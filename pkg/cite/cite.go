@@ -0,0 +1,112 @@
+// Package cite formats bibliographic entries as BibTeX, RIS and CSL-JSON.
+package cite
+
+import (
+	"fmt"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Entry struct {
+	ID        int64
+	Title     string
+	Authors   []string
+	Published time.Time
+	ISBN      string
+	URL       string
+}
+
+// ParseAuthors splits an author string such as "Ilf; Petrov" or
+// "Strugatsky, Arkady and Strugatsky, Boris" into individual names.
+func ParseAuthors(s string) []string {
+	s = strings.NewReplacer(" and ", ";", " & ", ";").Replace(s)
+
+	authors := make([]string, 0)
+	for _, name := range strings.Split(s, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+
+	return authors
+}
+
+// splitName returns the family and given names of "Family, Given" or
+// "Given Family".
+func splitName(name string) (family, given string) {
+	if i := strings.Index(name, ","); i >= 0 {
+		return strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+1:])
+	}
+
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		return name[i+1:], name[:i]
+	}
+
+	return name, ""
+}
+
+// Key returns the citation key of an entry built from the first author's
+// family name, the year and the first significant title word, followed by
+// the id, e.g. "tolstoy1869war-42". The key depends on nothing but the entry,
+// so a book is cited under the same key alone and in any batch.
+func Key(e Entry) string {
+	key := baseKey(e)
+	if key == "" {
+		return fmt.Sprintf("book%d", e.ID)
+	}
+
+	return fmt.Sprintf("%s-%d", key, e.ID)
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "on": true, "in": true, "and": true,
+}
+
+func baseKey(e Entry) string {
+	var key strings.Builder
+
+	if len(e.Authors) > 0 {
+		family, _ := splitName(e.Authors[0])
+		key.WriteString(keyWord(family))
+	}
+
+	if !e.Published.IsZero() {
+		key.WriteString(e.Published.Format("2006"))
+	}
+
+	for _, word := range strings.Fields(e.Title) {
+		if w := keyWord(word); w != "" && !stopWords[w] {
+			key.WriteString(w)
+			break
+		}
+	}
+
+	return key.String()
+}
+
+// keyWord reduces a word to lower-case ASCII letters and digits, removing
+// accents and transliterating Cyrillic.
+func keyWord(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteString(cyrillic[r])
+		}
+	}
+
+	return b.String()
+}
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ы': "y", 'э': "e", 'ю': "iu", 'я': "ia",
+	'ў': "o", 'қ': "q", 'ғ': "g", 'ҳ': "h",
+}
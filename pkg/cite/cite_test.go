@@ -0,0 +1,159 @@
+package cite
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var entries = []Entry{
+	{
+		ID:        42,
+		Title:     "Profits & Losses: 100% {Revised}",
+		Authors:   []string{"Smith_Jones, John", "Mary Ann Lee"},
+		Published: time.Date(2001, time.March, 5, 0, 0, 0, 0, time.UTC),
+		ISBN:      "9780306406157",
+		URL:       "https://example.org/books?id=42&lang=en#top",
+	},
+	{
+		ID:      7,
+		Title:   "Двенадцать стульев\nРоман",
+		Authors: []string{"Ilf"},
+	},
+}
+
+func TestBibTeX(t *testing.T) {
+	want := `@book{smithjones2001profits-42,
+  title = {{Profits \& Losses: 100\% \{Revised\}}},
+  author = {Smith\_Jones, John and Mary Ann Lee},
+  year = {2001},
+  isbn = {9780306406157},
+  url = {https://example.org/books?id=42\&lang=en\#top},
+}
+
+@book{ilfdvenadtsat-7,
+  title = {{Двенадцать стульев
+Роман}},
+  author = {Ilf},
+}
+`
+
+	var buf bytes.Buffer
+	if err := BibTeX(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBibTeXEscapesSpecialCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	if err := BibTeX(&buf, []Entry{{ID: 1, Title: `a\b ~c^ $d`}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "@book{ab-1,\n  title = {{a\\textbackslash{}b \\textasciitilde{}c\\textasciicircum{} \\$d}},\n}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRIS(t *testing.T) {
+	want := "TY  - BOOK\r\n" +
+		"ID  - smithjones2001profits-42\r\n" +
+		"TI  - Profits & Losses: 100% {Revised}\r\n" +
+		"AU  - Smith_Jones, John\r\n" +
+		"AU  - Lee, Mary Ann\r\n" +
+		"PY  - 2001\r\n" +
+		"DA  - 2001/03/05/\r\n" +
+		"SN  - 9780306406157\r\n" +
+		"UR  - https://example.org/books?id=42&lang=en#top\r\n" +
+		"ER  - \r\n" +
+		"TY  - BOOK\r\n" +
+		"ID  - ilfdvenadtsat-7\r\n" +
+		"TI  - Двенадцать стульев Роман\r\n" +
+		"AU  - Ilf\r\n" +
+		"ER  - \r\n"
+
+	var buf bytes.Buffer
+	if err := RIS(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCSLJSON(t *testing.T) {
+	want := `[{"id":"smithjones2001profits-42","type":"book","title":"Profits & Losses: 100% {Revised}",` +
+		`"author":[{"family":"Smith_Jones","given":"John"},{"family":"Lee","given":"Mary Ann"}],` +
+		`"issued":{"date-parts":[[2001,3,5]]},"ISBN":"9780306406157","URL":"https://example.org/books?id=42&lang=en#top"},` +
+		`{"id":"ilfdvenadtsat-7","type":"book","title":"Двенадцать стульев\nРоман","author":[{"literal":"Ilf"}]}]` + "\n"
+
+	var buf bytes.Buffer
+	if err := CSLJSON(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseAuthors(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"", []string{}},
+		{"Lev Tolstoy", []string{"Lev Tolstoy"}},
+		{"Ilf; Petrov", []string{"Ilf", "Petrov"}},
+		{"Strugatsky, Arkady and Strugatsky, Boris", []string{"Strugatsky, Arkady", "Strugatsky, Boris"}},
+		{"Ilf & Petrov;  ", []string{"Ilf", "Petrov"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseAuthors(tt.s); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAuthors(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		name, family, given string
+	}{
+		{"Tolstoy, Lev", "Tolstoy", "Lev"},
+		{"Lev Nikolayevich Tolstoy", "Tolstoy", "Lev Nikolayevich"},
+		{"de Balzac, Honoré", "de Balzac", "Honoré"},
+		{"Homer", "Homer", ""},
+	}
+
+	for _, tt := range tests {
+		family, given := splitName(tt.name)
+		if family != tt.family || given != tt.given {
+			t.Errorf("splitName(%q) = %q, %q, want %q, %q", tt.name, family, given, tt.family, tt.given)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		entry Entry
+		want  string
+	}{
+		{Entry{ID: 1, Title: "The War of the Worlds", Authors: []string{"H. G. Wells"}}, "wellswar-1"},
+		{Entry{ID: 2, Title: "Évangile", Authors: []string{"Müller, Ernst"}}, "mullerevangile-2"},
+		{Entry{ID: 3, Title: "The"}, "book3"},
+	}
+
+	for _, tt := range tests {
+		if got := Key(tt.entry); got != tt.want {
+			t.Errorf("Key(%+v) = %s, want %s", tt.entry, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package cite
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// BibTeX writes the entries as @book records. Titles are double braced so
+// their capitalization survives bibliography styles.
+func BibTeX(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+
+	for i, e := range entries {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+
+		fmt.Fprintf(bw, "@book{%s,\n", Key(e))
+		fmt.Fprintf(bw, "  title = {{%s}},\n", bibtexEscaper.Replace(e.Title))
+
+		if len(e.Authors) > 0 {
+			authors := make([]string, 0, len(e.Authors))
+			for _, a := range e.Authors {
+				authors = append(authors, bibtexEscaper.Replace(a))
+			}
+			fmt.Fprintf(bw, "  author = {%s},\n", strings.Join(authors, " and "))
+		}
+
+		if !e.Published.IsZero() {
+			fmt.Fprintf(bw, "  year = {%s},\n", e.Published.Format("2006"))
+		}
+
+		if e.ISBN != "" {
+			fmt.Fprintf(bw, "  isbn = {%s},\n", bibtexEscaper.Replace(e.ISBN))
+		}
+
+		if e.URL != "" {
+			fmt.Fprintf(bw, "  url = {%s},\n", bibtexEscaper.Replace(e.URL))
+		}
+
+		bw.WriteString("}\n")
+	}
+
+	return bw.Flush()
+}
+
+// risValue keeps a value on its tag line; RIS has no escaping.
+var risValue = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// RIS writes the entries as BOOK references with CRLF line endings.
+func RIS(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+
+	line := func(tag, value string) {
+		fmt.Fprintf(bw, "%s  - %s\r\n", tag, risValue.Replace(value))
+	}
+
+	for _, e := range entries {
+		line("TY", "BOOK")
+		line("ID", Key(e))
+		line("TI", e.Title)
+
+		for _, a := range e.Authors {
+			family, given := splitName(a)
+			if given != "" {
+				line("AU", family+", "+given)
+			} else {
+				line("AU", family)
+			}
+		}
+
+		if !e.Published.IsZero() {
+			line("PY", e.Published.Format("2006"))
+			line("DA", e.Published.Format("2006/01/02/"))
+		}
+
+		if e.ISBN != "" {
+			line("SN", e.ISBN)
+		}
+
+		if e.URL != "" {
+			line("UR", e.URL)
+		}
+
+		line("ER", "")
+	}
+
+	return bw.Flush()
+}
+
+type cslItem struct {
+	ID     string    `json:"id"`
+	Type   string    `json:"type"`
+	Title  string    `json:"title"`
+	Author []cslName `json:"author,omitempty"`
+	Issued *cslDate  `json:"issued,omitempty"`
+	ISBN   string    `json:"ISBN,omitempty"`
+	URL    string    `json:"URL,omitempty"`
+}
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// CSLJSON writes the entries as a CSL-JSON array.
+func CSLJSON(w io.Writer, entries []Entry) error {
+	items := make([]cslItem, 0, len(entries))
+
+	for _, e := range entries {
+		item := cslItem{
+			ID:    Key(e),
+			Type:  "book",
+			Title: e.Title,
+			ISBN:  e.ISBN,
+			URL:   e.URL,
+		}
+
+		for _, a := range e.Authors {
+			family, given := splitName(a)
+			if given == "" {
+				item.Author = append(item.Author, cslName{Literal: family})
+			} else {
+				item.Author = append(item.Author, cslName{Family: family, Given: given})
+			}
+		}
+
+		if !e.Published.IsZero() {
+			item.Issued = &cslDate{DateParts: [][]int{{
+				e.Published.Year(), int(e.Published.Month()), e.Published.Day(),
+			}}}
+		}
+
+		items = append(items, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(items)
+}
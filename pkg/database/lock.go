@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// AdvisoryLocker grants cluster-wide exclusive locks backed by Postgres
+// transaction-level advisory locks.
+type AdvisoryLocker struct {
+	db *sql.DB
+}
+
+func NewAdvisoryLocker(db *sql.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+// TryLock attempts to take the lock without waiting. When it succeeds the lock
+// is held by an open transaction until release is called.
+func (l AdvisoryLocker) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "select pg_try_advisory_xact_lock($1)", key).Scan(&locked); err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+
+	if !locked {
+		tx.Rollback()
+		return nil, false, nil
+	}
+
+	return func() { tx.Rollback() }, true, nil
+}
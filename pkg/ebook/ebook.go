@@ -0,0 +1,67 @@
+// Package ebook reads bibliographic metadata embedded in EPUB and PDF files.
+package ebook
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrorInvalidFile = errors.New("invalid ebook file")
+
+// Metadata holds what the file says about itself. Fields the file does not
+// provide are left empty.
+type Metadata struct {
+	Title       string
+	Creators    []string
+	Language    string
+	Identifiers []Identifier
+	Publisher   string
+	Description string
+	Subjects    []string
+	Published   time.Time
+
+	Cover     []byte
+	CoverType string
+}
+
+type Identifier struct {
+	Scheme string
+	Value  string
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`<[^>]*>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// plainText strips markup some publishers put into descriptions and
+// collapses whitespace.
+func plainText(s string) string {
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// parseDate accepts the W3CDTF subset used by dc:date.
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
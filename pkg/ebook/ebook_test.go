@@ -0,0 +1,199 @@
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const container = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+const opf = `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title id="t">Master &amp; Margarita</dc:title>
+    <dc:creator id="c1">Mikhail Bulgakov</dc:creator>
+    <meta refines="#c1" property="role">aut</meta>
+    <dc:creator id="c2">Michael Glenny</dc:creator>
+    <meta refines="#c2" property="role">trl</meta>
+    <dc:identifier id="i">urn:isbn:9780306406157</dc:identifier>
+    <dc:language>ru</dc:language>
+    <dc:date>1967-03</dc:date>
+  </metadata>
+  <manifest>
+    <item id="cover" href="images/cover%20art.jpg" media-type="image/jpeg" properties="cover-image"/>
+  </manifest>
+</package>`
+
+// epub builds an archive of the given files.
+func epub(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestParseEPUB(t *testing.T) {
+	r := epub(t, map[string]string{
+		"mimetype":                   "application/epub+zip",
+		"META-INF/container.xml":     container,
+		"OEBPS/content.opf":          opf,
+		"OEBPS/images/cover art.jpg": "jpeg",
+	})
+
+	meta, err := ParseEPUB(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.Title != "Master & Margarita" {
+		t.Errorf("title = %q", meta.Title)
+	}
+	if !reflect.DeepEqual(meta.Creators, []string{"Mikhail Bulgakov"}) {
+		t.Errorf("creators = %q", meta.Creators)
+	}
+	if !reflect.DeepEqual(meta.Identifiers, []Identifier{{Scheme: "isbn", Value: "9780306406157"}}) {
+		t.Errorf("identifiers = %v", meta.Identifiers)
+	}
+	if meta.Language != "ru" || meta.Published.Year() != 1967 {
+		t.Errorf("language = %q, published = %v", meta.Language, meta.Published)
+	}
+	if string(meta.Cover) != "jpeg" || meta.CoverType != "image/jpeg" {
+		t.Errorf("cover = %q of type %q", meta.Cover, meta.CoverType)
+	}
+}
+
+func TestParseEPUBMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"missing container.xml", map[string]string{
+			"OEBPS/content.opf": opf,
+		}},
+		{"container is not XML", map[string]string{
+			"META-INF/container.xml": "<container><rootfiles>",
+		}},
+		{"no rootfile", map[string]string{
+			"META-INF/container.xml": `<container><rootfiles/></container>`,
+		}},
+		{"bad OPF path", map[string]string{
+			"META-INF/container.xml": container,
+			"content.opf":            opf,
+		}},
+		{"OPF is not XML", map[string]string{
+			"META-INF/container.xml": container,
+			"OEBPS/content.opf":      "<package><metadata>",
+		}},
+		{"unknown OPF encoding", map[string]string{
+			"META-INF/container.xml": container,
+			"OEBPS/content.opf":      `<?xml version="1.0" encoding="x-unknown"?><package/>`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := epub(t, tt.files)
+			if _, err := ParseEPUB(r, r.Size()); !errors.Is(err, ErrorInvalidFile) {
+				t.Errorf("err = %v, want %v", err, ErrorInvalidFile)
+			}
+		})
+	}
+}
+
+func TestParseEPUBNotZip(t *testing.T) {
+	for _, data := range []string{"", "not a zip", "PK\x03\x04truncated"} {
+		r := bytes.NewReader([]byte(data))
+		if _, err := ParseEPUB(r, r.Size()); !errors.Is(err, ErrorInvalidFile) {
+			t.Errorf("ParseEPUB(%q) err = %v, want %v", data, err, ErrorInvalidFile)
+		}
+	}
+}
+
+const pdf = "%PDF-1.4\n" +
+	"1 0 obj\n<< /Title (Anna \\(Karenina\\)) /Author (Lev Tolstoy and Someone Else)" +
+	" /Subject <FEFF004E006F00760065006C> /Keywords (russian, classic) /CreationDate (D:18770101) >>\nendobj\n" +
+	"2 0 obj\n<< /Type /Catalog >>\nendobj\n" +
+	"trailer\n<< /Size 3 /Root 2 0 R /Info 1 0 R >>\nstartxref\n0\n%%EOF\n"
+
+func TestParsePDF(t *testing.T) {
+	r := bytes.NewReader([]byte(pdf))
+	meta, err := ParsePDF(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Metadata{
+		Title:       "Anna (Karenina)",
+		Creators:    []string{"Lev Tolstoy", "Someone Else"},
+		Description: "Novel",
+		Subjects:    []string{"russian", "classic"},
+	}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("got %+v, want %+v", meta, want)
+	}
+}
+
+func TestParsePDFMalformed(t *testing.T) {
+	trailer := bytes.LastIndex([]byte(pdf), []byte("trailer"))
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"missing header", pdf[len("%PDF-1.4\n"):]},
+		{"truncated trailer", pdf[:trailer+len("trailer\n<< /Size 3 /Ro")]},
+		{"truncated before the end-of-file marker", pdf[:len(pdf)-len("%%EOF\n")]},
+		{"truncated information dictionary", "%PDF-1.4\n1 0 obj\n<< /Title (Anna\n" +
+			"trailer\n<< /Info 1 0 R >>\n%%EOF\n"},
+		{"unbalanced dictionary", "%PDF-1.4\n1 0 obj\n<< /Title (Anna) ] >>\nendobj\n" +
+			"trailer\n<< /Info 1 0 R >>\n%%EOF\n"},
+		{"information object is not a dictionary", "%PDF-1.4\n1 0 obj\n(Anna)\nendobj\n" +
+			"trailer\n<< /Info 1 0 R >>\n%%EOF\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader([]byte(tt.data))
+			if _, err := ParsePDF(r, r.Size()); !errors.Is(err, ErrorInvalidFile) {
+				t.Errorf("err = %v, want %v", err, ErrorInvalidFile)
+			}
+		})
+	}
+}
+
+func TestParsePDFWithoutInformation(t *testing.T) {
+	data := "%PDF-1.7\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+	r := bytes.NewReader([]byte(data))
+	meta, err := ParsePDF(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(meta, Metadata{}) {
+		t.Errorf("got %+v, want no metadata", meta)
+	}
+}
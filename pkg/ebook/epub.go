@@ -0,0 +1,341 @@
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"golang.org/x/text/encoding/htmlindex"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	containerPath = "META-INF/container.xml"
+	packageType   = "application/oebps-package+xml"
+
+	maxPackageSize = 4 << 20
+	maxCoverSize   = 10 << 20
+)
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type opfPackage struct {
+	Metadata struct {
+		Titles       []opfElement `xml:"http://purl.org/dc/elements/1.1/ title"`
+		Creators     []opfElement `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		Languages    []opfElement `xml:"http://purl.org/dc/elements/1.1/ language"`
+		Identifiers  []opfElement `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+		Publishers   []opfElement `xml:"http://purl.org/dc/elements/1.1/ publisher"`
+		Descriptions []opfElement `xml:"http://purl.org/dc/elements/1.1/ description"`
+		Subjects     []opfElement `xml:"http://purl.org/dc/elements/1.1/ subject"`
+		Dates        []opfElement `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Metas        []opfMeta    `xml:"meta"`
+	} `xml:"metadata"`
+	Manifest []opfItem `xml:"manifest>item"`
+}
+
+type opfElement struct {
+	ID     string `xml:"id,attr"`
+	Role   string `xml:"http://www.idpf.org/2007/opf role,attr"`
+	Scheme string `xml:"http://www.idpf.org/2007/opf scheme,attr"`
+	Event  string `xml:"http://www.idpf.org/2007/opf event,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// opfMeta covers both the EPUB 2 name/content form and the EPUB 3
+// property/refines form.
+type opfMeta struct {
+	Name     string `xml:"name,attr"`
+	Content  string `xml:"content,attr"`
+	Property string `xml:"property,attr"`
+	Refines  string `xml:"refines,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type opfItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr"`
+}
+
+// ParseEPUB reads the package document of an EPUB 2 or 3 file along with
+// its cover image, if the package declares one.
+func ParseEPUB(r io.ReaderAt, size int64) (Metadata, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("%w: %s", ErrorInvalidFile, err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var container epubContainer
+	if err := decodeXML(files, containerPath, &container); err != nil {
+		return Metadata{}, err
+	}
+
+	opfPath := ""
+	for _, rootfile := range container.Rootfiles {
+		if rootfile.MediaType == packageType || rootfile.MediaType == "" {
+			opfPath = rootfile.FullPath
+			break
+		}
+	}
+	if opfPath == "" {
+		return Metadata{}, fmt.Errorf("%w: no package document", ErrorInvalidFile)
+	}
+
+	var pkg opfPackage
+	if err := decodeXML(files, opfPath, &pkg); err != nil {
+		return Metadata{}, err
+	}
+
+	meta := packageMetadata(pkg)
+
+	if item, ok := coverItem(pkg); ok {
+		href, err := url.PathUnescape(item.Href)
+		if err == nil {
+			data, err := readFile(files, path.Join(path.Dir(opfPath), href), maxCoverSize)
+			if err == nil {
+				meta.Cover = data
+				meta.CoverType = item.MediaType
+			}
+		}
+	}
+
+	return meta, nil
+}
+
+func packageMetadata(pkg opfPackage) Metadata {
+	md := pkg.Metadata
+
+	// EPUB 3 attaches roles, title types and identifier schemes to
+	// elements through refining meta elements.
+	refinements := make(map[string]map[string]string)
+	for _, m := range md.Metas {
+		if m.Refines == "" || m.Property == "" {
+			continue
+		}
+
+		id := strings.TrimPrefix(m.Refines, "#")
+		if refinements[id] == nil {
+			refinements[id] = make(map[string]string)
+		}
+		refinements[id][m.Property] = strings.TrimSpace(m.Value)
+	}
+
+	var meta Metadata
+
+	for _, title := range md.Titles {
+		value := strings.TrimSpace(title.Value)
+		if value == "" {
+			continue
+		}
+
+		if meta.Title == "" || refinements[title.ID]["title-type"] == "main" {
+			meta.Title = value
+		}
+	}
+
+	var others []string
+	for _, creator := range md.Creators {
+		name := strings.TrimSpace(creator.Value)
+		if name == "" {
+			continue
+		}
+
+		role := creator.Role
+		if role == "" {
+			role = refinements[creator.ID]["role"]
+		}
+
+		if role == "" || role == "aut" {
+			meta.Creators = append(meta.Creators, name)
+		} else {
+			others = append(others, name)
+		}
+	}
+	if len(meta.Creators) == 0 {
+		meta.Creators = others
+	}
+
+	meta.Language = firstValue(md.Languages)
+	meta.Publisher = firstValue(md.Publishers)
+	meta.Description = plainText(firstValue(md.Descriptions))
+
+	for _, subject := range md.Subjects {
+		if value := strings.TrimSpace(subject.Value); value != "" {
+			meta.Subjects = append(meta.Subjects, value)
+		}
+	}
+
+	for _, id := range md.Identifiers {
+		scheme := id.Scheme
+		if scheme == "" {
+			scheme = refinements[id.ID]["identifier-type"]
+		}
+
+		if identifier, ok := parseIdentifier(scheme, id.Value); ok {
+			meta.Identifiers = append(meta.Identifiers, identifier)
+		}
+	}
+
+	meta.Published = parseDate(publicationDate(md.Dates))
+
+	return meta
+}
+
+// parseIdentifier lower-cases the scheme and moves URN prefixes such as
+// "urn:isbn:" into it.
+func parseIdentifier(scheme, value string) (Identifier, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Identifier{}, false
+	}
+
+	lower := strings.ToLower(value)
+	for _, prefix := range []string{"urn:isbn:", "urn:uuid:", "urn:doi:", "isbn:", "doi:"} {
+		if strings.HasPrefix(lower, prefix) {
+			scheme = strings.TrimSuffix(strings.TrimPrefix(prefix, "urn:"), ":")
+			value = strings.TrimSpace(value[len(prefix):])
+			break
+		}
+	}
+
+	return Identifier{Scheme: strings.ToLower(scheme), Value: value}, true
+}
+
+// publicationDate prefers the date EPUB 2 marks as the publication event,
+// then one without an event.
+func publicationDate(dates []opfElement) string {
+	result := ""
+	for _, date := range dates {
+		value := strings.TrimSpace(date.Value)
+		switch {
+		case value == "":
+		case strings.EqualFold(date.Event, "publication"):
+			return value
+		case date.Event == "" && result == "":
+			result = value
+		}
+	}
+
+	if result == "" {
+		return firstValue(dates)
+	}
+
+	return result
+}
+
+// coverItem finds the cover image by the EPUB 3 cover-image property, the
+// EPUB 2 cover meta element or, failing both, an image item named cover.
+func coverItem(pkg opfPackage) (opfItem, bool) {
+	for _, item := range pkg.Manifest {
+		for _, property := range strings.Fields(item.Properties) {
+			if property == "cover-image" {
+				return item, true
+			}
+		}
+	}
+
+	for _, m := range pkg.Metadata.Metas {
+		if m.Name != "cover" || m.Content == "" {
+			continue
+		}
+
+		for _, item := range pkg.Manifest {
+			if item.ID == m.Content || item.Href == m.Content {
+				return item, true
+			}
+		}
+	}
+
+	for _, item := range pkg.Manifest {
+		if strings.Contains(strings.ToLower(item.ID), "cover") && strings.HasPrefix(item.MediaType, "image/") {
+			return item, true
+		}
+	}
+
+	return opfItem{}, false
+}
+
+func firstValue(elements []opfElement) string {
+	for _, e := range elements {
+		if value := strings.TrimSpace(e.Value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func decodeXML(files map[string]*zip.File, name string, v interface{}) error {
+	data, err := readFile(files, name, maxPackageSize)
+	if err != nil {
+		return err
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charsetReader
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrorInvalidFile, name, err)
+	}
+
+	return nil
+}
+
+// charsetReader lets package documents declare legacy encodings such as
+// windows-1251.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, err
+	}
+
+	return enc.NewDecoder().Reader(input), nil
+}
+
+// readFile reads an entry of the archive, falling back to a case-insensitive
+// match since some producers are careless about the case of paths.
+func readFile(files map[string]*zip.File, name string, limit int64) ([]byte, error) {
+	f, ok := files[name]
+	if !ok {
+		for n, file := range files {
+			if strings.EqualFold(n, name) {
+				f, ok = file, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrorInvalidFile, name)
+	}
+
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("%w: %s is too large", ErrorInvalidFile, name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrorInvalidFile, name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrorInvalidFile, name, err)
+	}
+
+	return data, nil
+}
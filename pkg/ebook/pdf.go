@@ -0,0 +1,401 @@
+package ebook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	scanChunk   = 1 << 20
+	scanOverlap = 128
+	maxDictSize = 64 << 10
+	tailSize    = 1024
+)
+
+var (
+	infoPattern    = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	encryptPattern = regexp.MustCompile(`/Encrypt\s*(?:\d|<<)`)
+	authorsSplit   = strings.NewReplacer(" and ", ";", "&", ";")
+	keywordsSplit  = strings.NewReplacer(",", ";")
+)
+
+// ParsePDF reads the document information dictionary of a PDF file. It
+// scans the raw bytes for the trailer rather than walking the cross
+// reference table, so it also copes with files whose table is damaged.
+// Information kept in compressed object streams or XMP packets and that of
+// encrypted documents is not read. A file cut short before its end-of-file
+// marker is rejected.
+func ParsePDF(r io.ReaderAt, size int64) (Metadata, error) {
+	head := make([]byte, 1024)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return Metadata{}, err
+	}
+	if !bytes.Contains(head[:n], []byte("%PDF-")) {
+		return Metadata{}, fmt.Errorf("%w: missing PDF header", ErrorInvalidFile)
+	}
+
+	tailOffset := size - tailSize
+	if tailOffset < 0 {
+		tailOffset = 0
+	}
+	tail := make([]byte, size-tailOffset)
+	n, err = r.ReadAt(tail, tailOffset)
+	if err != nil && err != io.EOF {
+		return Metadata{}, err
+	}
+	if !bytes.Contains(tail[:n], []byte("%%EOF")) {
+		return Metadata{}, fmt.Errorf("%w: truncated file", ErrorInvalidFile)
+	}
+
+	info, _, err := scanLast(r, size, infoPattern)
+	if err != nil || info == nil {
+		return Metadata{}, err
+	}
+
+	encrypted, _, err := scanLast(r, size, encryptPattern)
+	if err != nil || encrypted != nil {
+		return Metadata{}, err
+	}
+
+	objPattern := regexp.MustCompile(`(?:^|\D)` + string(info[1]) + `\s+` + string(info[2]) + `\s+obj\b`)
+	obj, end, err := scanLast(r, size, objPattern)
+	if err != nil || obj == nil {
+		return Metadata{}, err
+	}
+
+	buf := make([]byte, maxDictSize)
+	n, err = r.ReadAt(buf, end)
+	if err != nil && err != io.EOF {
+		return Metadata{}, err
+	}
+
+	p := &pdfParser{data: buf[:n]}
+	dict, err := p.dict()
+	if err != nil {
+		return Metadata{}, fmt.Errorf("%w: %s", ErrorInvalidFile, err)
+	}
+
+	var meta Metadata
+	meta.Title = strings.TrimSpace(dict["Title"])
+	meta.Creators = splitList(authorsSplit.Replace(dict["Author"]))
+	meta.Description = plainText(dict["Subject"])
+	meta.Subjects = splitList(keywordsSplit.Replace(dict["Keywords"]))
+
+	return meta, nil
+}
+
+// scanLast returns the submatches of the last match of re in the file and
+// the offset just past it, reading the file in overlapping chunks.
+func scanLast(r io.ReaderAt, size int64, re *regexp.Regexp) ([][]byte, int64, error) {
+	var (
+		match [][]byte
+		end   int64
+	)
+
+	buf := make([]byte, scanChunk+scanOverlap)
+	for offset := int64(0); offset < size; offset += scanChunk {
+		n, err := r.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+
+		chunk := buf[:n]
+		for _, loc := range re.FindAllSubmatchIndex(chunk, -1) {
+			match = make([][]byte, len(loc)/2)
+			for i := range match {
+				if loc[2*i] >= 0 {
+					match[i] = append([]byte(nil), chunk[loc[2*i]:loc[2*i+1]]...)
+				}
+			}
+			end = offset + int64(loc[1])
+		}
+	}
+
+	return match, end, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// pdfParser understands just enough of the PDF object syntax to read a
+// dictionary of strings.
+type pdfParser struct {
+	data []byte
+	pos  int
+}
+
+// dict parses a dictionary, keeping the entries whose values are strings.
+func (p *pdfParser) dict() (map[string]string, error) {
+	p.skipSpace()
+	if !p.consume("<<") {
+		return nil, fmt.Errorf("expected dictionary at %d", p.pos)
+	}
+
+	result := make(map[string]string)
+	for {
+		p.skipSpace()
+		if p.consume(">>") {
+			return result, nil
+		}
+
+		if p.pos >= len(p.data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		// The generation number and "R" of a reference follow its object
+		// number.
+		if p.data[p.pos] != '/' {
+			if _, err := p.value(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		key := p.name()
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		if s, ok := value.(string); ok {
+			result[key] = s
+		}
+	}
+}
+
+// value parses any object, returning strings decoded to UTF-8 and nil for
+// everything that is skipped.
+func (p *pdfParser) value() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	switch c := p.data[p.pos]; {
+	case c == '(':
+		return p.literal()
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		_, err := p.dict()
+		return nil, err
+	case c == '<':
+		return p.hex()
+	case c == '[':
+		p.pos++
+		for {
+			p.skipSpace()
+			if p.consume("]") {
+				return nil, nil
+			}
+
+			if _, err := p.value(); err != nil {
+				return nil, err
+			}
+		}
+	case c == '/':
+		p.name()
+		return nil, nil
+	case c == ')' || c == '>' || c == ']' || c == '{' || c == '}':
+		return nil, fmt.Errorf("unexpected %q at %d", c, p.pos)
+	default:
+		// Numbers, references ("12 0 R") and keywords are read one token
+		// at a time.
+		start := p.pos
+		for p.pos < len(p.data) && !isDelimiter(p.data[p.pos]) && !isSpace(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			return nil, fmt.Errorf("unexpected %q at %d", c, p.pos)
+		}
+
+		return nil, nil
+	}
+}
+
+func (p *pdfParser) name() string {
+	p.pos++
+
+	var name []byte
+	for p.pos < len(p.data) && !isDelimiter(p.data[p.pos]) && !isSpace(p.data[p.pos]) {
+		c := p.data[p.pos]
+		if c == '#' && p.pos+2 < len(p.data) {
+			if v, err := strconv.ParseUint(string(p.data[p.pos+1:p.pos+3]), 16, 8); err == nil {
+				name = append(name, byte(v))
+				p.pos += 3
+				continue
+			}
+		}
+
+		name = append(name, c)
+		p.pos++
+	}
+
+	return string(name)
+}
+
+func (p *pdfParser) literal() (string, error) {
+	p.pos++
+
+	var (
+		s     []byte
+		depth = 1
+	)
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return decodeText(s), nil
+			}
+		case '\\':
+			if p.pos >= len(p.data) {
+				break
+			}
+
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				s = append(s, '\n')
+			case 'r':
+				s = append(s, '\r')
+			case 't':
+				s = append(s, '\t')
+			case 'b':
+				s = append(s, '\b')
+			case 'f':
+				s = append(s, '\f')
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					s = append(s, byte(v))
+				} else {
+					s = append(s, e)
+				}
+			}
+			continue
+		}
+
+		s = append(s, c)
+	}
+
+	return "", io.ErrUnexpectedEOF
+}
+
+func (p *pdfParser) hex() (string, error) {
+	p.pos++
+
+	var digits []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		if c == '>' {
+			if len(digits)%2 == 1 {
+				digits = append(digits, '0')
+			}
+
+			s := make([]byte, len(digits)/2)
+			for i := range s {
+				v, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+				if err != nil {
+					return "", err
+				}
+				s[i] = byte(v)
+			}
+
+			return decodeText(s), nil
+		}
+
+		if !isSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+
+	return "", io.ErrUnexpectedEOF
+}
+
+func (p *pdfParser) consume(token string) bool {
+	if bytes.HasPrefix(p.data[p.pos:], []byte(token)) {
+		p.pos += len(token)
+		return true
+	}
+
+	return false
+}
+
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; {
+		case isSpace(c):
+			p.pos++
+		case c == '%':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// decodeText decodes a PDF text string: UTF-16BE or UTF-8 when marked by a
+// byte order mark, otherwise PDFDocEncoding, approximated by Latin-1 unless
+// the bytes happen to be valid UTF-8.
+func decodeText(s []byte) string {
+	switch {
+	case len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff:
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	case bytes.HasPrefix(s, []byte("\xef\xbb\xbf")):
+		return string(s[3:])
+	case utf8.Valid(s):
+		return string(s)
+	}
+
+	runes := make([]rune, len(s))
+	for i, c := range s {
+		runes[i] = rune(c)
+	}
+
+	return string(runes)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
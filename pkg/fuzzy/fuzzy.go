@@ -0,0 +1,75 @@
+// Package fuzzy compares short strings such as titles and names by the
+// similarity of their trigrams, the way PostgreSQL's pg_trgm does.
+package fuzzy
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Normalize lower-cases s, strips diacritics and replaces everything but
+// letters and digits with single spaces.
+func Normalize(s string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			space = false
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// Trigrams returns the sorted set of trigrams of the normalized words of s.
+// Each word is padded with two spaces in front and one behind, so short
+// words and word starts weigh in as well.
+func Trigrams(s string) []string {
+	set := make(map[string]struct{})
+	for _, word := range strings.Fields(Normalize(s)) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+
+	trigrams := make([]string, 0, len(set))
+	for t := range set {
+		trigrams = append(trigrams, t)
+	}
+	sort.Strings(trigrams)
+
+	return trigrams
+}
+
+// Similarity is the number of shared trigrams divided by the number of
+// distinct trigrams in both sorted sets, from 0 to 1.
+func Similarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
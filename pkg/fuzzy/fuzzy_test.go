@@ -0,0 +1,86 @@
+package fuzzy
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"  War and Peace ":          "war and peace",
+		"Crime & Punishment!":       "crime punishment",
+		"Les Misérables":            "les miserables",
+		"Ёжик в тумане":             "ежик в тумане",
+		"1984 -- (Nineteen-Eighty)": "1984 nineteen eighty",
+		"":                          "",
+	}
+
+	for s, want := range tests {
+		if got := Normalize(s); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestTrigrams(t *testing.T) {
+	want := []string{"  a", "  c", " a ", " ca", "at ", "cat"}
+	if got := Trigrams("A cat, a CAT"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got := Trigrams("!!"); len(got) != 0 {
+		t.Errorf("got %q for no words", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"cat", "cat", 1},
+		{"Cat!", "cat", 1},
+		// 3 shared of "  c", " ca", "cat", "at ", "ats", "ts "
+		{"cat", "cats", 0.5},
+		{"cat", "dog", 0},
+		{"", "cat", 0},
+		{"", "", 0},
+	}
+
+	for _, tt := range tests {
+		got := Similarity(Trigrams(tt.a), Trigrams(tt.b))
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if back := Similarity(Trigrams(tt.b), Trigrams(tt.a)); back != got {
+			t.Errorf("Similarity(%q, %q) = %v is not symmetric", tt.b, tt.a, back)
+		}
+	}
+}
+
+func TestSimilarityMatches(t *testing.T) {
+	const threshold = 0.8
+
+	tests := []struct {
+		a, b  string
+		match bool
+	}{
+		{"The Master and Margarita", "The master & Margarita", true},
+		{"Les Misérables", "Les Miserables", true},
+		{"Dostoevsky, Fyodor", "Fyodor Dostoevsky", true},
+		{"One Hundred Years of Solitude", "One Hundred Years of Solitude.", true},
+		{"War and Peace", "Peace and War", true},
+		{"War and Peace", "War of the Worlds", false},
+		{"Harry Potter and the Philosopher's Stone", "Harry Potter and the Chamber of Secrets", false},
+		{"Tolstoy, Leo", "Tolstaya, Tatyana", false},
+		{"Dune", "Dune Messiah", false},
+	}
+
+	for _, tt := range tests {
+		score := Similarity(Trigrams(tt.a), Trigrams(tt.b))
+		if (score >= threshold) != tt.match {
+			t.Errorf("Similarity(%q, %q) = %.3f, match want %v", tt.a, tt.b, score, tt.match)
+		}
+	}
+}
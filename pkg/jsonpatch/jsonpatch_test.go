@@ -0,0 +1,246 @@
+package jsonpatch
+
+import (
+	"errors"
+	"testing"
+)
+
+// assertJSON fails the test unless got and want hold the same JSON value.
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	g, err := decode(got)
+	if err != nil {
+		t.Fatalf("decode result %s: %v", got, err)
+	}
+	w, err := decode([]byte(want))
+	if err != nil {
+		t.Fatalf("decode want %s: %v", want, err)
+	}
+
+	if !equal(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{
+			name:  "add member",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "add", "path": "/b", "value": [1, 2]}]`,
+			want:  `{"a": 1, "b": [1, 2]}`,
+		},
+		{
+			name:  "add replaces an existing member",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "add", "path": "/a", "value": 2}]`,
+			want:  `{"a": 2}`,
+		},
+		{
+			name:  "add inserts into an array",
+			doc:   `{"a": [1, 3]}`,
+			patch: `[{"op": "add", "path": "/a/1", "value": 2}]`,
+			want:  `{"a": [1, 2, 3]}`,
+		},
+		{
+			name:  "add appends with -",
+			doc:   `{"a": [1, 2]}`,
+			patch: `[{"op": "add", "path": "/a/-", "value": 3}]`,
+			want:  `{"a": [1, 2, 3]}`,
+		},
+		{
+			name:  "add at the array length appends",
+			doc:   `{"a": [1, 2]}`,
+			patch: `[{"op": "add", "path": "/a/2", "value": 3}]`,
+			want:  `{"a": [1, 2, 3]}`,
+		},
+		{
+			name:  "add replaces the whole document",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "add", "path": "", "value": [true]}]`,
+			want:  `[true]`,
+		},
+		{
+			name:  "remove member",
+			doc:   `{"a": 1, "b": 2}`,
+			patch: `[{"op": "remove", "path": "/a"}]`,
+			want:  `{"b": 2}`,
+		},
+		{
+			name:  "remove array element",
+			doc:   `[1, 2, 3]`,
+			patch: `[{"op": "remove", "path": "/1"}]`,
+			want:  `[1, 3]`,
+		},
+		{
+			name:  "replace nested member",
+			doc:   `{"a": {"b": "x"}}`,
+			patch: `[{"op": "replace", "path": "/a/b", "value": null}]`,
+			want:  `{"a": {"b": null}}`,
+		},
+		{
+			name:  "replace array element",
+			doc:   `{"a": [1, 2]}`,
+			patch: `[{"op": "replace", "path": "/a/0", "value": "one"}]`,
+			want:  `{"a": ["one", 2]}`,
+		},
+		{
+			name:  "move member",
+			doc:   `{"a": {"b": 1}, "c": {}}`,
+			patch: `[{"op": "move", "from": "/a/b", "path": "/c/d"}]`,
+			want:  `{"a": {}, "c": {"d": 1}}`,
+		},
+		{
+			name:  "move within an array",
+			doc:   `[1, 2, 3]`,
+			patch: `[{"op": "move", "from": "/0", "path": "/-"}]`,
+			want:  `[2, 3, 1]`,
+		},
+		{
+			name:  "move to itself",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a"}]`,
+			want:  `{"a": 1}`,
+		},
+		{
+			name:  "move to a sibling sharing the prefix",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/ab"}]`,
+			want:  `{"ab": 1}`,
+		},
+		{
+			name:  "copy is deep",
+			doc:   `{"a": {"b": 1}}`,
+			patch: `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "replace", "path": "/c/b", "value": 2}]`,
+			want:  `{"a": {"b": 1}, "c": {"b": 2}}`,
+		},
+		{
+			name:  "test passes",
+			doc:   `{"a": {"b": [1, "x"]}, "n": 10}`,
+			patch: `[{"op": "test", "path": "/a", "value": {"b": [1, "x"]}}, {"op": "test", "path": "/n", "value": 1e1}]`,
+			want:  `{"a": {"b": [1, "x"]}, "n": 10}`,
+		},
+		{
+			name:  "escaped pointer tokens",
+			doc:   `{"a/b": 1, "m~n": 2, "~1": 3}`,
+			patch: `[{"op": "replace", "path": "/a~1b", "value": 4}, {"op": "remove", "path": "/m~0n"}, {"op": "test", "path": "/~01", "value": 3}]`,
+			want:  `{"a/b": 4, "~1": 3}`,
+		},
+		{
+			name:  "empty member name",
+			doc:   `{"": 1}`,
+			patch: `[{"op": "replace", "path": "/", "value": 2}]`,
+			want:  `{"": 2}`,
+		},
+		{
+			name:  "large numbers are kept",
+			doc:   `{"n": 12345678901234567890}`,
+			patch: `[{"op": "add", "path": "/m", "value": 1}]`,
+			want:  `{"n": 12345678901234567890, "m": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  error
+	}{
+		{"not an array", `{}`, `{"op": "add"}`, ErrorInvalidPatch},
+		{"unknown operation", `{}`, `[{"op": "merge", "path": "/a"}]`, ErrorInvalidPatch},
+		{"missing path", `{}`, `[{"op": "add", "value": 1}]`, ErrorInvalidPatch},
+		{"missing value", `{}`, `[{"op": "add", "path": "/a"}]`, ErrorInvalidPatch},
+		{"missing from", `{"a": 1}`, `[{"op": "move", "path": "/b"}]`, ErrorInvalidPatch},
+		{"relative pointer", `{"a": 1}`, `[{"op": "remove", "path": "a"}]`, ErrorInvalidPatch},
+		{"remove the document", `{"a": 1}`, `[{"op": "remove", "path": ""}]`, ErrorInvalidPatch},
+		{"move into own child", `{"a": {"b": {}}}`, `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`, ErrorInvalidPatch},
+		{"add to missing parent", `{}`, `[{"op": "add", "path": "/a/b", "value": 1}]`, ErrorPathNotFound},
+		{"add past the array end", `[1]`, `[{"op": "add", "path": "/2", "value": 1}]`, ErrorPathNotFound},
+		{"add at a negative index", `[1]`, `[{"op": "add", "path": "/-1", "value": 1}]`, ErrorPathNotFound},
+		{"index with a leading zero", `[1, 2]`, `[{"op": "replace", "path": "/01", "value": 1}]`, ErrorPathNotFound},
+		{"replace -", `[1]`, `[{"op": "replace", "path": "/-", "value": 1}]`, ErrorPathNotFound},
+		{"remove -", `[1]`, `[{"op": "remove", "path": "/-"}]`, ErrorPathNotFound},
+		{"remove past the array end", `[1]`, `[{"op": "remove", "path": "/1"}]`, ErrorPathNotFound},
+		{"replace missing member", `{}`, `[{"op": "replace", "path": "/a", "value": 1}]`, ErrorPathNotFound},
+		{"remove missing member", `{}`, `[{"op": "remove", "path": "/a"}]`, ErrorPathNotFound},
+		{"path through a scalar", `{"a": 1}`, `[{"op": "add", "path": "/a/b", "value": 1}]`, ErrorPathNotFound},
+		{"move from missing member", `{}`, `[{"op": "move", "from": "/a", "path": "/b"}]`, ErrorPathNotFound},
+		{"test missing member", `{}`, `[{"op": "test", "path": "/a", "value": 1}]`, ErrorPathNotFound},
+		{"test mismatch", `{"a": "1"}`, `[{"op": "test", "path": "/a", "value": 1}]`, ErrorTestFailed},
+		{"test extra member", `{"a": {"b": 1, "c": 2}}`, `[{"op": "test", "path": "/a", "value": {"b": 1}}]`, ErrorTestFailed},
+		{"later operation fails", `{"a": 1}`, `[{"op": "remove", "path": "/a"}, {"op": "test", "path": "/a", "value": 1}]`, ErrorPathNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if got != nil {
+				t.Errorf("result %s returned with an error", got)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Examples from appendix A of RFC 7386.
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"a": "foo"}`, `null`, `null`},
+		{`{"a": "foo"}`, `"bar"`, `"bar"`},
+		{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("merge: %v", err)
+			}
+
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	if _, err := MergePatch([]byte(`{}`), []byte(`{"a":`)); !errors.Is(err, ErrorInvalidPatch) {
+		t.Errorf("err = %v, want %v", err, ErrorInvalidPatch)
+	}
+}
@@ -0,0 +1,128 @@
+package label
+
+import (
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"image/color"
+)
+
+// Label size in millimetres, matching a 3x7 sheet of A4 labels.
+const (
+	Width  = 70.0
+	Height = 42.3
+
+	padding = 3.0
+)
+
+type Label struct {
+	Title   string
+	Author  string
+	Barcode string
+	ISBN    string
+	URL     string
+}
+
+// canvas is the drawing surface shared by the PNG, SVG and PDF renderers.
+// All coordinates are in millimetres from the top-left corner of the label,
+// text is positioned by its baseline.
+type canvas interface {
+	rect(x, y, w, h float64)
+	text(x, y, size float64, s string)
+}
+
+type codes struct {
+	code128 barcode.Barcode
+	ean13   barcode.Barcode
+	qr      barcode.Barcode
+}
+
+// encode builds the Code 128 barcode for the copy, the EAN-13 barcode for
+// the ISBN when there is one and the QR code for the book URL.
+func encode(l Label) (codes, error) {
+	var c codes
+	var err error
+
+	c.code128, err = code128.Encode(l.Barcode)
+	if err != nil {
+		return c, err
+	}
+
+	if l.ISBN != "" {
+		c.ean13, err = ean.Encode(l.ISBN)
+		if err != nil {
+			return c, err
+		}
+	}
+
+	if l.URL != "" {
+		c.qr, err = qr.Encode(l.URL, qr.M, qr.Auto)
+		if err != nil {
+			return c, err
+		}
+	}
+
+	return c, nil
+}
+
+func layout(cv canvas, l Label, c codes) {
+	textWidth := Width - 2*padding
+	codeWidth := textWidth
+	if c.qr != nil {
+		codeWidth = Width - 3*padding - 20
+		drawCode(cv, c.qr, Width-padding-20, 9, 20, 20)
+	}
+
+	cv.text(padding, 6, 3, truncate(l.Title, textWidth, 3))
+	cv.text(padding, 9, 2.2, truncate(l.Author, codeWidth, 2.2))
+
+	drawCode(cv, c.code128, padding, 11, codeWidth, 11)
+	cv.text(padding, 25, 2.5, l.Barcode)
+
+	if c.ean13 != nil {
+		drawCode(cv, c.ean13, padding, 27, codeWidth, 9)
+		cv.text(padding, 39, 2.5, "ISBN "+l.ISBN)
+	}
+}
+
+// drawCode paints every horizontal run of dark modules of an unscaled
+// barcode as a single rectangle.
+func drawCode(cv canvas, bc barcode.Barcode, x, y, w, h float64) {
+	bounds := bc.Bounds()
+	mw := w / float64(bounds.Dx())
+	mh := h / float64(bounds.Dy())
+
+	for row := bounds.Min.Y; row < bounds.Max.Y; row++ {
+		start := -1
+		for col := bounds.Min.X; col <= bounds.Max.X; col++ {
+			on := col < bounds.Max.X && isDark(bc.At(col, row))
+			switch {
+			case on && start < 0:
+				start = col
+			case !on && start >= 0:
+				cv.rect(x+float64(start-bounds.Min.X)*mw, y+float64(row-bounds.Min.Y)*mh, float64(col-start)*mw, mh)
+				start = -1
+			}
+		}
+	}
+}
+
+func isDark(c color.Color) bool {
+	return color.GrayModel.Convert(c).(color.Gray).Y < 128
+}
+
+// truncate shortens s so that it roughly fits into width millimetres at the
+// given font size.
+func truncate(s string, width, size float64) string {
+	runes := []rune(s)
+	n := int(width / (size * 0.55))
+	if len(runes) <= n {
+		return s
+	}
+	if n < 1 {
+		return ""
+	}
+
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,203 @@
+package label
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boombuler/barcode"
+)
+
+// modules returns the first row of the barcode as a string of 1s for dark
+// and 0s for light modules.
+func modules(bc barcode.Barcode) string {
+	var b strings.Builder
+	bounds := bc.Bounds()
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		if isDark(bc.At(x, bounds.Min.Y)) {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+
+	return b.String()
+}
+
+// Code 128 symbol patterns by value, from ISO/IEC 15417.
+var code128Patterns = map[string]string{
+	"A":       "10100011000", // 33 in code set B
+	"B":       "10001011000", // 34 in code set B
+	"12":      "10110011100",
+	"34":      "10001011000",
+	"56":      "11100010110",
+	"78":      "11000010100",
+	"57":      "11101101000",
+	"Code C":  "10111011110", // 99
+	"Start B": "11010010000", // 104
+	"Start C": "11010011100", // 105
+}
+
+const code128Stop = "1100011101011"
+
+func TestCode128(t *testing.T) {
+	tests := []struct {
+		barcode string
+		symbols []string
+	}{
+		// Digits only are packed in pairs in code set C.
+		{"12345678", []string{"Start C", "12", "34", "56", "78"}},
+		// The run of digits after the letters switches from B to C.
+		{"AB12345678", []string{"Start B", "A", "B", "Code C", "12", "34", "56", "78"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.barcode, func(t *testing.T) {
+			c, err := encode(Label{Barcode: tt.barcode})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			row := modules(c.code128)
+			// Symbols of 11 modules, the check symbol and the stop pattern.
+			if want := 11*(len(tt.symbols)+1) + len(code128Stop); len(row) != want {
+				t.Fatalf("width = %d modules, want %d", len(row), want)
+			}
+
+			for i, symbol := range tt.symbols {
+				if got := row[11*i : 11*i+11]; got != code128Patterns[symbol] {
+					t.Errorf("symbol %d = %s, want %s (%s)", i, got, code128Patterns[symbol], symbol)
+				}
+			}
+
+			if !strings.HasSuffix(row, code128Stop) {
+				t.Errorf("row %s does not end with the stop pattern", row)
+			}
+		})
+	}
+}
+
+func TestCode128CheckSymbol(t *testing.T) {
+	// (104 + 33*1 + 34*2 + 99*3 + 12*4 + 34*5 + 56*6 + 78*7) mod 103 = 57
+	c, err := encode(Label{Barcode: "AB12345678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := modules(c.code128)
+	check := row[len(row)-len(code128Stop)-11 : len(row)-len(code128Stop)]
+	if check != code128Patterns["57"] {
+		t.Errorf("check symbol = %s, want %s", check, code128Patterns["57"])
+	}
+}
+
+func TestEAN13(t *testing.T) {
+	c, err := encode(Label{Barcode: "C1", ISBN: "9780306406157"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := modules(c.ean13)
+	if len(row) != 95 {
+		t.Fatalf("width = %d modules, want 95", len(row))
+	}
+
+	checks := []struct {
+		name  string
+		start int
+		want  string
+	}{
+		{"start guard", 0, "101"},
+		// The leading 9 encodes the first digit of the left half, 7, with odd parity.
+		{"7 in set L", 3, "0111011"},
+		// and the second, 8, with even parity.
+		{"8 in set G", 10, "0001001"},
+		{"middle guard", 45, "01010"},
+		{"4 in set R", 50, "1011100"},
+		{"check digit 7 in set R", 85, "1000100"},
+		{"end guard", 92, "101"},
+	}
+	for _, check := range checks {
+		if got := row[check.start : check.start+len(check.want)]; got != check.want {
+			t.Errorf("%s = %s, want %s", check.name, got, check.want)
+		}
+	}
+}
+
+func TestEAN13RejectsWrongCheckDigit(t *testing.T) {
+	if _, err := encode(Label{Barcode: "C1", ISBN: "9780306406158"}); err == nil {
+		t.Error("encoded an ISBN with a wrong check digit")
+	}
+}
+
+func TestQR(t *testing.T) {
+	// 32 bytes exceed the 26 of version 2 at level M and fit version 3,
+	// which is 29 modules wide.
+	c, err := encode(Label{Barcode: "C1", URL: "https://library.example/books/42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bounds := c.qr.Bounds()
+	if bounds.Dx() != 29 || bounds.Dy() != 29 {
+		t.Fatalf("size = %dx%d, want 29x29", bounds.Dx(), bounds.Dy())
+	}
+
+	// Every corner but the bottom right has a finder pattern: a dark ring
+	// of 7x7 modules, a light ring and a dark 3x3 centre.
+	finder := []string{"1111111", "1000001", "1011101", "1011101", "1011101", "1000001", "1111111"}
+	for _, corner := range [][2]int{{0, 0}, {22, 0}, {0, 22}} {
+		for dy, line := range finder {
+			for dx, m := range line {
+				if isDark(c.qr.At(corner[0]+dx, corner[1]+dy)) != (m == '1') {
+					t.Fatalf("finder pattern at %v differs at (%d, %d)", corner, dx, dy)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeOptionalCodes(t *testing.T) {
+	c, err := encode(Label{Barcode: "C1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.ean13 != nil || c.qr != nil {
+		t.Error("codes encoded for a label without ISBN and URL")
+	}
+}
+
+func TestSVGEscapesText(t *testing.T) {
+	var buf bytes.Buffer
+	err := SVG(&buf, Label{Title: "War & <Peace>", Author: "Tolstoy", Barcode: "C1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svg := buf.String()
+	if !strings.Contains(svg, "War &amp; &lt;Peace&gt;") {
+		t.Errorf("title is not escaped in %s", svg)
+	}
+	if !strings.Contains(svg, "<rect ") {
+		t.Error("no bars drawn")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width float64
+		want  string
+	}{
+		{"Short", 64, "Short"},
+		{"Анна Каренина", 11, "Анна Ка…"},
+		{"Anything", 1, ""},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.width, 2.5); got != tt.want {
+			t.Errorf("truncate(%q, %v) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
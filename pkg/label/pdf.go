@@ -0,0 +1,60 @@
+package label
+
+import (
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font/gofont/goregular"
+	"io"
+)
+
+const (
+	sheetColumns = 3
+	sheetRows    = 7
+)
+
+type pdfCanvas struct {
+	pdf  *gofpdf.Fpdf
+	x, y float64
+}
+
+func (c pdfCanvas) rect(x, y, w, h float64) {
+	c.pdf.Rect(c.x+x, c.y+y, w, h, "F")
+}
+
+func (c pdfCanvas) text(x, y, size float64, s string) {
+	c.pdf.SetFont("goregular", "", size*72/25.4)
+	c.pdf.Text(c.x+x, c.y+y, s)
+}
+
+// PDF writes the labels as A4 sheets of 3x7 labels, adding pages as needed.
+func PDF(w io.Writer, labels []Label) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddUTF8FontFromBytes("goregular", "", goregular.TTF)
+	pdf.SetFillColor(0, 0, 0)
+
+	perPage := sheetColumns * sheetRows
+	if len(labels) == 0 {
+		pdf.AddPage()
+	}
+
+	for i, l := range labels {
+		c, err := encode(l)
+		if err != nil {
+			return err
+		}
+
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+
+		slot := i % perPage
+		layout(pdfCanvas{
+			pdf: pdf,
+			x:   float64(slot%sheetColumns) * Width,
+			y:   float64(slot/sheetColumns) * Height,
+		}, l, c)
+	}
+
+	return pdf.Output(w)
+}
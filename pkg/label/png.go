@@ -0,0 +1,68 @@
+package label
+
+import (
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// DPI is the resolution of rendered PNG labels.
+const DPI = 300
+
+var regular, _ = opentype.Parse(goregular.TTF)
+
+type pngCanvas struct {
+	img   *image.Gray
+	scale float64
+}
+
+func (c pngCanvas) px(v float64) int {
+	return int(math.Round(v * c.scale))
+}
+
+func (c pngCanvas) rect(x, y, w, h float64) {
+	r := image.Rect(c.px(x), c.px(y), c.px(x+w), c.px(y+h))
+	draw.Draw(c.img, r, image.Black, image.Point{}, draw.Src)
+}
+
+func (c pngCanvas) text(x, y, size float64, s string) {
+	face, err := opentype.NewFace(regular, &opentype.FaceOptions{
+		Size: size * c.scale,
+		DPI:  72,
+	})
+	if err != nil {
+		return
+	}
+	defer face.Close()
+
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.Black,
+		Face: face,
+		Dot:  fixed.P(c.px(x), c.px(y)),
+	}
+	d.DrawString(s)
+}
+
+// PNG writes the label as a grayscale image rendered at DPI.
+func PNG(w io.Writer, l Label) error {
+	c, err := encode(l)
+	if err != nil {
+		return err
+	}
+
+	cv := pngCanvas{scale: DPI / 25.4}
+	cv.img = image.NewGray(image.Rect(0, 0, cv.px(Width), cv.px(Height)))
+	draw.Draw(cv.img, cv.img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	layout(cv, l, c)
+
+	return png.Encode(w, cv.img)
+}
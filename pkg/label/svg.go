@@ -0,0 +1,40 @@
+package label
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type svgCanvas struct {
+	w *bufio.Writer
+}
+
+func (c svgCanvas) rect(x, y, w, h float64) {
+	fmt.Fprintf(c.w, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f"/>`, x, y, w, h)
+}
+
+func (c svgCanvas) text(x, y, size float64, s string) {
+	fmt.Fprintf(c.w, `<text x="%.3f" y="%.3f" font-size="%.2f">`, x, y, size)
+	xml.EscapeText(c.w, []byte(s))
+	c.w.WriteString("</text>")
+}
+
+// SVG writes the label as a standalone SVG document sized in millimetres.
+func SVG(w io.Writer, l Label) error {
+	c, err := encode(l)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%.1fmm" height="%.1fmm" viewBox="0 0 %.1f %.1f">`,
+		Width, Height, Width, Height)
+	bw.WriteString(`<rect width="100%" height="100%" fill="#fff"/>`)
+	bw.WriteString(`<g fill="#000" font-family="Helvetica, Arial, sans-serif" shape-rendering="crispEdges">`)
+	layout(svgCanvas{w: bw}, l, c)
+	bw.WriteString("</g></svg>")
+
+	return bw.Flush()
+}
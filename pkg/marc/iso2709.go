@@ -0,0 +1,208 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Reader reads records in ISO 2709 format. Records are expected to be UTF-8
+// encoded; MARC-8 content is returned byte for byte.
+type Reader struct {
+	r *bufio.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next record or io.EOF once the input is exhausted.
+func (r *Reader) Read() (Record, error) {
+	// Line breaks between records are common in files passed around by hand.
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return Record{}, err
+		}
+		if b != '\n' && b != '\r' {
+			r.r.UnreadByte()
+			break
+		}
+	}
+
+	prefix, err := r.r.Peek(5)
+	if err == io.EOF {
+		return Record{}, fmt.Errorf("%w: truncated leader", ErrorInvalidRecord)
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	length, ok := number(prefix)
+	if !ok || length < leaderLength+1 {
+		return Record{}, fmt.Errorf("%w: bad record length", ErrorInvalidRecord)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r.r, data); err == io.ErrUnexpectedEOF {
+		return Record{}, fmt.Errorf("%w: truncated record", ErrorInvalidRecord)
+	} else if err != nil {
+		return Record{}, err
+	}
+
+	return parseRecord(data)
+}
+
+func parseRecord(data []byte) (Record, error) {
+	if data[len(data)-1] != recordTerminator {
+		return Record{}, fmt.Errorf("%w: missing record terminator", ErrorInvalidRecord)
+	}
+
+	record := Record{Leader: string(data[:leaderLength])}
+
+	base, ok := number(data[12:17])
+	if !ok || base <= leaderLength || base > len(data) {
+		return record, fmt.Errorf("%w: bad base address", ErrorInvalidRecord)
+	}
+
+	directory := data[leaderLength : base-1]
+	if len(directory)%directoryEntrySize != 0 {
+		return record, fmt.Errorf("%w: bad directory", ErrorInvalidRecord)
+	}
+
+	for i := 0; i < len(directory); i += directoryEntrySize {
+		entry := directory[i : i+directoryEntrySize]
+		tag := string(entry[:3])
+
+		length, ok1 := number(entry[3:7])
+		start, ok2 := number(entry[7:12])
+		if !ok1 || !ok2 || length < 1 || base+start+length > len(data) {
+			return record, fmt.Errorf("%w: bad directory entry for %s", ErrorInvalidRecord, tag)
+		}
+
+		value := bytes.TrimSuffix(data[base+start:base+start+length], []byte{fieldTerminator})
+
+		if IsControlTag(tag) {
+			record.ControlFields = append(record.ControlFields, ControlField{Tag: tag, Value: string(value)})
+			continue
+		}
+
+		record.DataFields = append(record.DataFields, parseDataField(tag, value))
+	}
+
+	return record, nil
+}
+
+// number reads a fixed-width numeric field of the leader or directory.
+// Unlike strconv.Atoi it accepts nothing but ASCII digits, so the result is
+// never negative.
+func number(b []byte) (int, bool) {
+	n := 0
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+
+	return n, len(b) > 0
+}
+
+func parseDataField(tag string, value []byte) DataField {
+	field := DataField{Tag: tag, Ind1: ' ', Ind2: ' '}
+
+	parts := bytes.Split(value, []byte{subfieldDelimiter})
+	if len(parts[0]) > 0 {
+		field.Ind1 = parts[0][0]
+	}
+	if len(parts[0]) > 1 {
+		field.Ind2 = parts[0][1]
+	}
+
+	for _, part := range parts[1:] {
+		if len(part) == 0 {
+			continue
+		}
+
+		field.Subfields = append(field.Subfields, Subfield{Code: part[0], Value: string(part[1:])})
+	}
+
+	return field
+}
+
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write encodes the record in ISO 2709, computing the record length, base
+// address and directory.
+func (w *Writer) Write(record Record) error {
+	var directory, fields bytes.Buffer
+
+	addField := func(tag string, value []byte) error {
+		if len(tag) != 3 {
+			return fmt.Errorf("%w: bad tag %q", ErrorInvalidRecord, tag)
+		}
+		if len(value) > 9999 {
+			return fmt.Errorf("%w: field %s is too long", ErrorInvalidRecord, tag)
+		}
+
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(value), fields.Len())
+		fields.Write(value)
+
+		return nil
+	}
+
+	for _, f := range record.ControlFields {
+		if err := addField(f.Tag, append([]byte(f.Value), fieldTerminator)); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range record.DataFields {
+		value := []byte{indicator(f.Ind1), indicator(f.Ind2)}
+		for _, s := range f.Subfields {
+			value = append(value, subfieldDelimiter, s.Code)
+			value = append(value, s.Value...)
+		}
+		value = append(value, fieldTerminator)
+
+		if err := addField(f.Tag, value); err != nil {
+			return err
+		}
+	}
+
+	directory.WriteByte(fieldTerminator)
+	fields.WriteByte(recordTerminator)
+
+	base := leaderLength + directory.Len()
+	length := base + fields.Len()
+	if length > 99999 {
+		return fmt.Errorf("%w: record is too long", ErrorInvalidRecord)
+	}
+
+	leader := record.leader()
+	copy(leader[0:5], fmt.Sprintf("%05d", length))
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+
+	for _, part := range [][]byte{leader, directory.Bytes(), fields.Bytes()} {
+		if _, err := w.w.Write(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func indicator(b byte) byte {
+	if b == 0 {
+		return ' '
+	}
+
+	return b
+}
@@ -0,0 +1,98 @@
+// Package marc reads and writes MARC 21 bibliographic records in ISO 2709
+// transmission format and in MARCXML.
+package marc
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	fieldTerminator    = 0x1e
+	recordTerminator   = 0x1d
+	subfieldDelimiter  = 0x1f
+	leaderLength       = 24
+	directoryEntrySize = 12
+)
+
+var ErrorInvalidRecord = errors.New("invalid marc record")
+
+type Record struct {
+	Leader        string
+	ControlFields []ControlField
+	DataFields    []DataField
+}
+
+type ControlField struct {
+	Tag   string
+	Value string
+}
+
+type DataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// IsControlTag reports whether the tag belongs to a control field (001-009).
+func IsControlTag(tag string) bool {
+	return strings.HasPrefix(tag, "00")
+}
+
+// ControlField returns the value of the first control field with the tag.
+func (r Record) ControlField(tag string) string {
+	for _, f := range r.ControlFields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+
+	return ""
+}
+
+// Fields returns every data field with the tag in record order.
+func (r Record) Fields(tag string) []DataField {
+	fields := make([]DataField, 0)
+	for _, f := range r.DataFields {
+		if f.Tag == tag {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// Subfield returns the value of the first subfield with the code.
+func (f DataField) Subfield(code byte) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return s.Value
+		}
+	}
+
+	return ""
+}
+
+// leader returns the record leader padded or cut to 24 characters with the
+// positions this package depends on filled in: UTF-8 encoding, two
+// indicators, one-character subfield codes and the entry map.
+func (r Record) leader() []byte {
+	leader := []byte(r.Leader)
+	for len(leader) < leaderLength {
+		leader = append(leader, ' ')
+	}
+	leader = leader[:leaderLength]
+
+	leader[9] = 'a'
+	leader[10] = '2'
+	leader[11] = '2'
+	copy(leader[20:], "4500")
+
+	return leader
+}
@@ -0,0 +1,154 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sampleRecords() []Record {
+	return []Record{
+		{
+			Leader: "00000nam a2200000 i 4500",
+			ControlFields: []ControlField{
+				{Tag: "001", Value: "42"},
+				{Tag: "008", Value: "230101s1999    xx            000 0 und d"},
+			},
+			DataFields: []DataField{
+				{Tag: "020", Ind1: ' ', Ind2: ' ', Subfields: []Subfield{{Code: 'a', Value: "9780306406157"}}},
+				{Tag: "100", Ind1: '1', Ind2: ' ', Subfields: []Subfield{{Code: 'a', Value: "Толстой, Лев"}}},
+				{Tag: "245", Ind1: '1', Ind2: '0', Subfields: []Subfield{
+					{Code: 'a', Value: "War and peace :"},
+					{Code: 'b', Value: "a novel & more <1>"},
+				}},
+				{Tag: "264", Ind1: ' ', Ind2: '1', Subfields: []Subfield{{Code: 'c', Value: "1999"}}},
+			},
+		},
+		{
+			Leader: "00000nam a2200000 i 4500",
+			ControlFields: []ControlField{
+				{Tag: "001", Value: "43"},
+			},
+			DataFields: []DataField{
+				{Tag: "245", Ind1: '0', Ind2: '0', Subfields: []Subfield{{Code: 'a', Value: "Untitled"}}},
+			},
+		},
+	}
+}
+
+// withoutAddresses blanks the record length and base address, which only
+// ISO 2709 carries.
+func withoutAddresses(record Record) Record {
+	leader := []byte(record.Leader)
+	copy(leader[0:5], "     ")
+	copy(leader[12:17], "     ")
+	record.Leader = string(leader)
+
+	return record
+}
+
+func readAll(t *testing.T, read func() (Record, error)) []Record {
+	t.Helper()
+
+	records := make([]Record, 0)
+	for {
+		record, err := read()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+
+		records = append(records, record)
+	}
+}
+
+func TestISO2709RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, record := range sampleRecords() {
+		if err := w.Write(record); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	// Line breaks between records must be skipped.
+	data := strings.Replace(buf.String(), "\x1d", "\x1d\r\n", 1)
+
+	got := readAll(t, NewReader(strings.NewReader(data)).Read)
+	want := sampleRecords()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		if !reflect.DeepEqual(withoutAddresses(got[i]), withoutAddresses(want[i])) {
+			t.Errorf("record %d:\n got %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMARCXMLRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewXMLWriter(&buf)
+	for _, record := range sampleRecords() {
+		if err := w.Write(record); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	got := readAll(t, NewXMLReader(&buf).Read)
+	want := sampleRecords()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		if !reflect.DeepEqual(withoutAddresses(got[i]), withoutAddresses(want[i])) {
+			t.Errorf("record %d:\n got %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadMalformedDirectory(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(sampleRecords()[1]); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	valid := buf.String()
+
+	// The 245 entry follows the 001 entry in the directory.
+	entry := leaderLength + directoryEntrySize
+
+	tests := []struct {
+		name   string
+		record string
+	}{
+		{"negative start", valid[:entry+7] + "-9999" + valid[entry+12:]},
+		{"signed start", valid[:entry+7] + "+0000" + valid[entry+12:]},
+		{"signed length", valid[:entry+3] + "+012" + valid[entry+7:]},
+		{"non-digit length", valid[:entry+3] + "00x2" + valid[entry+7:]},
+		{"start out of range", valid[:entry+7] + "99999" + valid[entry+12:]},
+		{"zero length", valid[:entry+3] + "0000" + valid[entry+7:]},
+		{"signed base address", valid[:12] + "-0001" + valid[17:]},
+		{"signed record length", "+" + valid[1:]},
+		{"truncated record", valid[:len(valid)-3]},
+		{"missing terminator", valid[:len(valid)-1] + " "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tt.record)).Read()
+			if !errors.Is(err, ErrorInvalidRecord) {
+				t.Errorf("got %v, want %v", err, ErrorInvalidRecord)
+			}
+		})
+	}
+}
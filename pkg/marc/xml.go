@@ -0,0 +1,167 @@
+package marc
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const xmlNamespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XMLReader reads the record elements of a MARCXML document one at a time,
+// whether they are wrapped in a collection or not.
+type XMLReader struct {
+	d *xml.Decoder
+}
+
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{d: xml.NewDecoder(r)}
+}
+
+// Read returns the next record or io.EOF once the document is exhausted.
+func (r *XMLReader) Read() (Record, error) {
+	for {
+		token, err := r.d.Token()
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return Record{}, fmt.Errorf("%w: %s", ErrorInvalidRecord, err)
+		}
+		if err != nil {
+			return Record{}, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var xr xmlRecord
+		if err := r.d.DecodeElement(&xr, &start); err != nil {
+			return Record{}, fmt.Errorf("%w: %s", ErrorInvalidRecord, err)
+		}
+
+		return xr.record(), nil
+	}
+}
+
+func (xr xmlRecord) record() Record {
+	record := Record{Leader: xr.Leader}
+
+	for _, f := range xr.ControlFields {
+		record.ControlFields = append(record.ControlFields, ControlField{Tag: f.Tag, Value: f.Value})
+	}
+
+	for _, f := range xr.DataFields {
+		field := DataField{Tag: f.Tag, Ind1: firstByte(f.Ind1), Ind2: firstByte(f.Ind2)}
+		for _, s := range f.Subfields {
+			field.Subfields = append(field.Subfields, Subfield{Code: firstByte(s.Code), Value: s.Value})
+		}
+
+		record.DataFields = append(record.DataFields, field)
+	}
+
+	return record
+}
+
+func firstByte(s string) byte {
+	if s == "" {
+		return ' '
+	}
+
+	return s[0]
+}
+
+// XMLWriter writes records into a MARCXML collection. Close must be called
+// to end the document.
+type XMLWriter struct {
+	w       io.Writer
+	e       *xml.Encoder
+	started bool
+}
+
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	return &XMLWriter{w: w, e: xml.NewEncoder(w)}
+}
+
+func (w *XMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	_, err := io.WriteString(w.w, xml.Header+`<collection xmlns="`+xmlNamespace+`">`)
+
+	return err
+}
+
+func (w *XMLWriter) Write(record Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	xr := xmlRecord{Leader: string(record.leader())}
+
+	// MARCXML carries no record length or base address.
+	leader := []byte(xr.Leader)
+	copy(leader[0:5], "     ")
+	copy(leader[12:17], "     ")
+	xr.Leader = string(leader)
+
+	for _, f := range record.ControlFields {
+		xr.ControlFields = append(xr.ControlFields, xmlControlField{Tag: f.Tag, Value: f.Value})
+	}
+
+	for _, f := range record.DataFields {
+		field := xmlDataField{
+			Tag:  f.Tag,
+			Ind1: string(indicator(f.Ind1)),
+			Ind2: string(indicator(f.Ind2)),
+		}
+		for _, s := range f.Subfields {
+			field.Subfields = append(field.Subfields, xmlSubfield{Code: string(s.Code), Value: s.Value})
+		}
+
+		xr.DataFields = append(xr.DataFields, field)
+	}
+
+	if err := w.e.Encode(xr); err != nil {
+		return err
+	}
+
+	return w.e.Flush()
+}
+
+func (w *XMLWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w.w, "</collection>")
+
+	return err
+}
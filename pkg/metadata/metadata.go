@@ -0,0 +1,16 @@
+// Package metadata looks up bibliographic details of a book by ISBN in
+// external catalogs.
+package metadata
+
+import "errors"
+
+var ErrorNotFound = errors.New("metadata: book not found")
+
+// Record holds what a catalog knows about an edition. Fields it does not
+// know are left empty.
+type Record struct {
+	Description string
+	PageCount   int
+	Subjects    []string
+	CoverURL    string
+}
@@ -0,0 +1,172 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	maxResponseSize = 1 << 20
+	maxCoverSize    = 10 << 20
+)
+
+// OpenLibrary reads editions and works from the Open Library JSON API, or
+// any service that mirrors its /isbn/{isbn}.json and /works/{id}.json
+// endpoints, and cover images from its covers service.
+type OpenLibrary struct {
+	baseURL   string
+	coversURL string
+	client    *http.Client
+}
+
+func NewOpenLibrary(baseURL, coversURL string) *OpenLibrary {
+	return &OpenLibrary{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		coversURL: strings.TrimSuffix(coversURL, "/"),
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type olEdition struct {
+	NumberOfPages int      `json:"number_of_pages"`
+	Description   olText   `json:"description"`
+	Subjects      []string `json:"subjects"`
+	Covers        []int64  `json:"covers"`
+	Works         []struct {
+		Key string `json:"key"`
+	} `json:"works"`
+}
+
+type olWork struct {
+	Description olText   `json:"description"`
+	Subjects    []string `json:"subjects"`
+	Covers      []int64  `json:"covers"`
+}
+
+// olText is a field Open Library returns either as a plain string or as a
+// {"type": "/type/text", "value": "..."} object.
+type olText string
+
+func (t *olText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = olText(s)
+		return nil
+	}
+
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = olText(v.Value)
+
+	return nil
+}
+
+// Lookup finds the edition by ISBN, falling back to its work for the
+// description, subjects and cover the edition lacks.
+func (p OpenLibrary) Lookup(ctx context.Context, isbn string) (Record, error) {
+	var edition olEdition
+	if err := p.getJSON(ctx, "/isbn/"+url.PathEscape(isbn)+".json", &edition); err != nil {
+		return Record{}, err
+	}
+
+	record := Record{
+		Description: strings.TrimSpace(string(edition.Description)),
+		PageCount:   edition.NumberOfPages,
+		Subjects:    edition.Subjects,
+	}
+	cover := firstCover(edition.Covers)
+
+	if len(edition.Works) > 0 && (record.Description == "" || len(record.Subjects) == 0 || cover == 0) {
+		var work olWork
+		err := p.getJSON(ctx, edition.Works[0].Key+".json", &work)
+		if err != nil && err != ErrorNotFound {
+			return Record{}, err
+		}
+
+		if record.Description == "" {
+			record.Description = strings.TrimSpace(string(work.Description))
+		}
+		if len(record.Subjects) == 0 {
+			record.Subjects = work.Subjects
+		}
+		if cover == 0 {
+			cover = firstCover(work.Covers)
+		}
+	}
+
+	if cover != 0 {
+		record.CoverURL = fmt.Sprintf("%s/b/id/%d-L.jpg", p.coversURL, cover)
+	}
+
+	return record, nil
+}
+
+// firstCover skips the negative ids Open Library uses for removed covers.
+func firstCover(ids []int64) int64 {
+	for _, id := range ids {
+		if id > 0 {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// Cover downloads the cover image a record points to.
+func (p OpenLibrary) Cover(ctx context.Context, record Record) ([]byte, error) {
+	if record.CoverURL == "" {
+		return nil, ErrorNotFound
+	}
+
+	resp, err := p.get(ctx, record.CoverURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxCoverSize))
+}
+
+func (p OpenLibrary) getJSON(ctx context.Context, path string, v interface{}) error {
+	resp, err := p.get(ctx, p.baseURL+path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
+
+func (p OpenLibrary) get(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrorNotFound
+	}
+
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("metadata: %s responded with %s", u, resp.Status)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newCatalog starts a stand-in for the Open Library API serving the given
+// paths; any other path is not found.
+func newCatalog(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestLookup(t *testing.T) {
+	server := newCatalog(t, map[string]string{
+		"/isbn/9780306406157.json": `{"number_of_pages": 320, "covers": [-1], "works": [{"key": "/works/OL1W"}]}`,
+		"/works/OL1W.json": `{"description": {"type": "/type/text", "value": " A novel. "}, ` +
+			`"subjects": ["Fiction", "War"], "covers": [42]}`,
+	})
+
+	record, err := NewOpenLibrary(server.URL, server.URL+"/covers/").Lookup(context.Background(), "9780306406157")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+
+	want := Record{
+		Description: "A novel.",
+		PageCount:   320,
+		Subjects:    []string{"Fiction", "War"},
+		CoverURL:    server.URL + "/covers/b/id/42-L.jpg",
+	}
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("got %+v, want %+v", record, want)
+	}
+}
+
+func TestLookupEditionOnly(t *testing.T) {
+	server := newCatalog(t, map[string]string{
+		"/isbn/9780306406157.json": `{"description": "Plain text.", "subjects": ["Poetry"], "covers": [7], ` +
+			`"works": [{"key": "/works/OL404W"}]}`,
+	})
+
+	record, err := NewOpenLibrary(server.URL, server.URL).Lookup(context.Background(), "9780306406157")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+
+	if record.Description != "Plain text." || record.CoverURL != server.URL+"/b/id/7-L.jpg" {
+		t.Errorf("got %+v", record)
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	server := newCatalog(t, nil)
+
+	_, err := NewOpenLibrary(server.URL, server.URL).Lookup(context.Background(), "9780306406157")
+	if !errors.Is(err, ErrorNotFound) {
+		t.Errorf("got %v, want %v", err, ErrorNotFound)
+	}
+}
+
+func TestLookupUpstreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewOpenLibrary(server.URL, server.URL).Lookup(context.Background(), "9780306406157")
+	if err == nil || errors.Is(err, ErrorNotFound) {
+		t.Errorf("got %v, want an upstream error", err)
+	}
+}
+
+func TestCover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/b/id/42-L.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte("image"))
+	}))
+	defer server.Close()
+
+	provider := NewOpenLibrary(server.URL, server.URL)
+
+	data, err := provider.Cover(context.Background(), Record{CoverURL: server.URL + "/b/id/42-L.jpg"})
+	if err != nil || string(data) != "image" {
+		t.Errorf("got %q, %v", data, err)
+	}
+
+	_, err = provider.Cover(context.Background(), Record{CoverURL: server.URL + "/b/id/1-L.jpg"})
+	if !errors.Is(err, ErrorNotFound) {
+		t.Errorf("got %v, want %v", err, ErrorNotFound)
+	}
+
+	if _, err := provider.Cover(context.Background(), Record{}); !errors.Is(err, ErrorNotFound) {
+		t.Errorf("got %v, want %v", err, ErrorNotFound)
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Email delivers messages as plain text mail through an SMTP server.
+type Email struct {
+	cnf EmailConfig
+}
+
+func NewEmail(cnf EmailConfig) *Email {
+	return &Email{cnf: cnf}
+}
+
+func (n Email) Send(ctx context.Context, msg Message) error {
+	if msg.Email == "" {
+		return fmt.Errorf("notify: user %d has no email", msg.UserID)
+	}
+
+	addr := net.JoinHostPort(n.cnf.Host, strconv.Itoa(n.cnf.Port))
+
+	var auth smtp.Auth
+	if n.cnf.Username != "" {
+		auth = smtp.PlainAuth("", n.cnf.Username, n.cnf.Password, n.cnf.Host)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", n.cnf.From)
+	fmt.Fprintf(&body, "To: %s\r\n", sanitizeHeader(msg.Email))
+	fmt.Fprintf(&body, "Subject: %s\r\n", sanitizeHeader(msg.Subject))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(msg.Body)
+
+	return smtp.SendMail(addr, auth, n.cnf.From, []string{msg.Email}, []byte(body.String()))
+}
+
+func sanitizeHeader(v string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
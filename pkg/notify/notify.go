@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+)
+
+type Message struct {
+	Kind    string                 `json:"kind"`
+	UserID  int64                  `json:"user_id"`
+	Email   string                 `json:"email"`
+	Name    string                 `json:"name"`
+	Subject string                 `json:"subject"`
+	Body    string                 `json:"body"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Log writes messages to the application log instead of delivering them.
+type Log struct{}
+
+func NewLog() *Log {
+	return &Log{}
+}
+
+func (n Log) Send(ctx context.Context, msg Message) error {
+	log.WithFields(log.Fields{
+		"notifier": "log",
+		"kind":     msg.Kind,
+		"user_id":  msg.UserID,
+		"email":    msg.Email,
+		"subject":  msg.Subject,
+	}).Info(msg.Body)
+
+	return nil
+}
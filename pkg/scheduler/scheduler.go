@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Locker makes sure a job runs on a single replica at a time.
+type Locker interface {
+	TryLock(ctx context.Context, key int64) (release func(), ok bool, err error)
+}
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	locker Locker
+	jobs   []Job
+}
+
+func New(locker Locker, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		jobs:   jobs,
+	}
+}
+
+// Start runs every job on its own interval until the context is cancelled.
+// Each run is skipped if another replica already holds the job lock.
+func (s *Scheduler) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			log.WithFields(log.Fields{
+				"job": job.Name,
+			}).Warn("job disabled: interval is not set")
+			continue
+		}
+
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	release, ok, err := s.locker.TryLock(ctx, lockKey(job.Name))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"job":     job.Name,
+			"problem": "acquire lock error",
+		}).Error(err)
+		return
+	}
+
+	if !ok {
+		return
+	}
+	defer release()
+
+	if err := job.Run(ctx); err != nil {
+		log.WithFields(log.Fields{
+			"job":     job.Name,
+			"problem": "job error",
+		}).Error(err)
+	}
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("scheduler:" + name))
+
+	return int64(h.Sum64())
+}
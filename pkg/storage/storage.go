@@ -0,0 +1,5 @@
+package storage
+
+import "errors"
+
+var ErrorObjectNotFound = errors.New("object not found")
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// s3Server is a minimal in-memory stand-in for an S3 bucket.
+type s3Server struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (s *s3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.objects[key] = data
+		s.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := s.objects[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err == nil {
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[start : end+1])
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		if _, ok := s.objects[key]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func testStore(t *testing.T, s store) {
+	t.Helper()
+
+	ctx := context.Background()
+	data := "0123456789"
+
+	if err := s.Put(ctx, "covers/1/a.png", strings.NewReader(data), int64(len(data)), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	read := func(r io.ReadCloser, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+
+		return string(b)
+	}
+
+	if got := read(s.Get(ctx, "covers/1/a.png")); got != data {
+		t.Errorf("Get = %q, want %q", got, data)
+	}
+
+	if got := read(s.GetRange(ctx, "covers/1/a.png", 2, 3)); got != "234" {
+		t.Errorf("GetRange = %q, want %q", got, "234")
+	}
+
+	if err := s.Delete(ctx, "covers/1/a.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := s.Get(ctx, "covers/1/a.png"); !errors.Is(err, ErrorObjectNotFound) {
+		t.Errorf("Get after delete: err = %v, want %v", err, ErrorObjectNotFound)
+	}
+
+	if err := s.Delete(ctx, "covers/1/a.png"); err != nil {
+		t.Errorf("Delete of a missing object: %v", err)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	s, err := NewLocal(t.TempDir(), "http://localhost/files/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testStore(t, s)
+
+	if got := s.URL("covers/1/a.png"); got != "http://localhost/files/covers/1/a.png" {
+		t.Errorf("URL = %q", got)
+	}
+}
+
+func TestLocalRejectsEmptyKey(t *testing.T) {
+	s, err := NewLocal(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Put(context.Background(), "/", strings.NewReader("x"), 1, "text/plain"); err == nil {
+		t.Error("Put with an empty key succeeded")
+	}
+}
+
+func TestS3(t *testing.T) {
+	backend := &s3Server{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	s := NewS3(S3Config{
+		Endpoint:  server.URL + "/",
+		Bucket:    "books",
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+
+	testStore(t, s)
+
+	if err := s.Put(context.Background(), "covers/2/b c.jpg", strings.NewReader("x"), 1, "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.types["/books/covers/2/b c.jpg"]; got != "image/jpeg" {
+		t.Errorf("stored content type = %q, want image/jpeg", got)
+	}
+
+	if got, want := s.URL("covers/2/b c.jpg"), server.URL+"/books/covers/2/b%20c.jpg"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+}
+
+func TestS3Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	s := NewS3(S3Config{Endpoint: server.URL, Bucket: "books"})
+
+	err := s.Put(context.Background(), "a", strings.NewReader("x"), 1, "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put err = %v, want a 403 error", err)
+	}
+}
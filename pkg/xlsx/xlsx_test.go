@@ -0,0 +1,177 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readPart returns the contents of a part of the workbook.
+func readPart(t *testing.T, zr *zip.Reader, name string) string {
+	t.Helper()
+
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+
+	return string(data)
+}
+
+type sheetXML struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R   string `xml:"r,attr"`
+			T   string `xml:"t,attr"`
+			S   string `xml:"s,attr"`
+			V   string `xml:"v"`
+			IsT string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, `Books & "More"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.WriteRow("id", "title", nil, "published"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(
+		int64(42),
+		`War & Peace <"vol. 1">`,
+		nil,
+		time.Date(1869, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2000, 1, 2, 12, 0, 0, 0, time.UTC),
+		true,
+		4.5,
+		"=1+1",
+		"  padded  ",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	wide := make([]interface{}, 28)
+	wide[27] = "last"
+	if err := w.WriteRow(wide...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		readPart(t, zr, name)
+	}
+
+	workbook := readPart(t, zr, "xl/workbook.xml")
+	if !strings.Contains(workbook, `<sheet name="Books &amp; &#34;More&#34;"`) {
+		t.Errorf("sheet name is not escaped in %s", workbook)
+	}
+
+	raw := readPart(t, zr, "xl/worksheets/sheet1.xml")
+	if !strings.Contains(raw, `<t xml:space="preserve">War &amp; Peace &lt;&#34;vol. 1&#34;&gt;</t>`) {
+		t.Errorf("title is not escaped in %s", raw)
+	}
+
+	var sheet sheetXML
+	if err := xml.Unmarshal([]byte(raw), &sheet); err != nil {
+		t.Fatalf("sheet is not well-formed: %v", err)
+	}
+
+	type cell struct{ r, t, s, v string }
+	var got [][]cell
+	for _, row := range sheet.Rows {
+		var cells []cell
+		for _, c := range row.Cells {
+			v := c.V
+			if c.T == "inlineStr" {
+				v = c.IsT
+			}
+			cells = append(cells, cell{c.R, c.T, c.S, v})
+		}
+		got = append(got, cells)
+	}
+
+	want := [][]cell{
+		{
+			{"A1", "inlineStr", "", "id"},
+			{"B1", "inlineStr", "", "title"},
+			{"D1", "inlineStr", "", "published"},
+		},
+		{
+			{"A2", "", "", "42"},
+			{"B2", "inlineStr", "", `War & Peace <"vol. 1">`},
+			// Dates before 1900-03-01 are kept as text.
+			{"D2", "inlineStr", "", "1869-01-01"},
+			{"E2", "", "1", "36527.5"},
+			{"F2", "b", "", "1"},
+			{"G2", "", "", "4.5"},
+			// Inline strings are never evaluated as formulas.
+			{"H2", "inlineStr", "", "=1+1"},
+			{"I2", "inlineStr", "", "  padded  "},
+		},
+		{
+			{"AB3", "inlineStr", "", "last"},
+		},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if sheet.Rows[i].R != strconv.Itoa(i+1) {
+			t.Errorf("row %d has number %s", i, sheet.Rows[i].R)
+		}
+		if len(got[i]) != len(want[i]) {
+			t.Errorf("row %d = %v, want %v", i+1, got[i], want[i])
+			continue
+		}
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Errorf("cell = %v, want %v", got[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{
+		0:     "A",
+		25:    "Z",
+		26:    "AA",
+		27:    "AB",
+		51:    "AZ",
+		52:    "BA",
+		701:   "ZZ",
+		702:   "AAA",
+		16383: "XFD",
+	}
+
+	for i, want := range tests {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}